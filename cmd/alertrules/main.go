@@ -0,0 +1,90 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Command alertrules generates Prometheus alerting rules from the Alert of an Operation.
+//
+// Usage:
+//
+//	alertrules -file operation.yaml -o rules.yaml
+//	alertrules -addr 127.0.0.1:12520 -tenant default -app app -server server
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/alertrules"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+func main() {
+	var (
+		file       = flag.String("file", "", "operation file in protobuf JSON or YAML format")
+		addr       = flag.String("addr", "", "OperationService address, used if -file is not set")
+		tenant     = flag.String("tenant", "default", "tenant of the operation")
+		app        = flag.String("app", "", "app of the operation")
+		server     = flag.String("server", "", "server of the operation")
+		timeout    = flag.Duration("timeout", 10*time.Second, "timeout of fetching the operation")
+		rateWindow = flag.String("rate-window", alertrules.DefaultRateWindow, "range of rate() in the rules")
+		output     = flag.String("o", "", "output file, default stdout")
+		indicators = flag.Bool("indicators", false, "list the indicators which can be used as the item name")
+	)
+	flag.Parse()
+	if *indicators {
+		for _, name := range alertrules.Indicators() {
+			fmt.Println(name)
+		}
+		return
+	}
+	if err := run(*file, *addr, *tenant, *app, *server, *timeout, *rateWindow, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "alertrules: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(file, addr, tenant, app, server string, timeout time.Duration, rateWindow, output string) error {
+	var (
+		op  *operation.Operation
+		err error
+	)
+	switch {
+	case file != "":
+		op, err = alertrules.LoadOperation(file)
+	case addr != "":
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		op, err = alertrules.FetchOperation(ctx, addr, tenant, app, server)
+	default:
+		flag.Usage()
+		return fmt.Errorf("either -file or -addr is required")
+	}
+	if err != nil {
+		return err
+	}
+	rules, err := alertrules.Generate(op, alertrules.WithRateWindow(rateWindow))
+	if err != nil {
+		return err
+	}
+	data, err := rules.Marshal()
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0644)
+}
@@ -0,0 +1,40 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Command otelctl manages the dyeing sampler rules and the remote operations.
+//
+// Usage:
+//
+//	otelctl -addr 127.0.0.1:12520 sampler add -ttl 1h -comment debug uid 123
+//	otelctl -http 127.0.0.1:12521 operation apply -f operation.yaml -dry-run
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/otelctl"
+)
+
+func main() {
+	if err := otelctl.Run(context.Background(), os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "otelctl: %v\n", err)
+		if errors.Is(err, otelctl.ErrUsage) {
+			fmt.Fprint(os.Stderr, otelctl.Usage)
+			os.Exit(2)
+		}
+		os.Exit(1)
+	}
+}
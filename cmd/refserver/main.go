@@ -0,0 +1,69 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Command refserver runs the reference SamplerService and OperationService server.
+//
+// Usage:
+//
+//	refserver -grpc-addr :12520 -http-addr :12521 -file refserver.json
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/refserver"
+)
+
+func main() {
+	var (
+		grpcAddr = flag.String("grpc-addr", ":12520", "grpc listen address")
+		httpAddr = flag.String("http-addr", ":12521", "http gateway listen address, disabled if empty")
+		file     = flag.String("file", "", "file to persist the samplers and operations, in memory only if empty")
+	)
+	flag.Parse()
+	if err := run(*grpcAddr, *httpAddr, *file); err != nil {
+		fmt.Fprintf(os.Stderr, "refserver: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(grpcAddr, httpAddr, file string) error {
+	var opts []refserver.Option
+	if file != "" {
+		opts = append(opts, refserver.WithPersistFile(file))
+	}
+	s, err := refserver.New(opts...)
+	if err != nil {
+		return err
+	}
+	grpcLis, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		return err
+	}
+	var httpLis net.Listener
+	if httpAddr != "" {
+		if httpLis, err = net.Listen("tcp", httpAddr); err != nil {
+			return err
+		}
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	fmt.Fprintf(os.Stderr, "refserver: grpc on %s, http on %s\n", grpcAddr, httpAddr)
+	return s.Serve(ctx, grpcLis, httpLis)
+}
@@ -42,6 +42,30 @@ type Code struct {
 	Service string `yaml:"service"`
 	// Method empty means full match
 	Method string `yaml:"method"`
+	// CodeMin, CodeMax match codes in the closed interval [CodeMin, CodeMax] when Code is empty,
+	// e.g. business codes 10000-19999
+	CodeMin int64 `yaml:"code_min"`
+	CodeMax int64 `yaml:"code_max"`
+	// ServiceRegex matches service by regular expression, only used when Service is empty
+	ServiceRegex string `yaml:"service_regex"`
+	// MethodRegex matches method by regular expression, only used when Method is empty
+	MethodRegex string `yaml:"method_regex"`
+	// ErrorType matches the type of errs.Error: business/framework/callee_framework, empty means full match
+	ErrorType string `yaml:"error_type"`
+}
+
+const (
+	// ErrorTypeBusiness business error, including the code of response body
+	ErrorTypeBusiness = "business"
+	// ErrorTypeFramework framework error of the current server
+	ErrorTypeFramework = "framework"
+	// ErrorTypeCalleeFramework framework error returned by the callee
+	ErrorTypeCalleeFramework = "callee_framework"
+)
+
+// IsRange reports whether the code matches a range of codes.
+func (c *Code) IsRange() bool {
+	return c.Code == "" && (c.CodeMin != 0 || c.CodeMax != 0)
 }
 
 func newCode(code, codeType, desc string) *Code {
@@ -14,7 +14,8 @@
 package codes
 
 import (
-	"sort"
+	"encoding/json"
+	"net/http"
 	"strconv"
 	"sync/atomic"
 
@@ -31,6 +32,16 @@ type CodeMapper interface {
 	Mapping(code, service, method string) *Code
 }
 
+// ErrorTypeCodeMapper mapping with the type of errs.Error, see ErrorTypeBusiness etc.
+type ErrorTypeCodeMapper interface {
+	MappingWithErrorType(code, errorType, service, method string) *Code
+}
+
+// CodeExplainer explains which rule classified the code.
+type CodeExplainer interface {
+	Explain(code, errorType, service, method string) *Explanation
+}
+
 // CodeConverter implements CodeMapper interface
 type CodeConverter struct {
 	configurator        remote.Configurator
@@ -59,7 +70,7 @@ func New(opts ...SetupOption) *CodeConverter {
 
 func (cm *CodeConverter) init() {
 	cm.atomicCodeMapping = &atomic.Value{}
-	cm.atomicCodeMapping.Store(covertCodesToMapping(cm.codes))
+	cm.atomicCodeMapping.Store(newCodeRules(cm.codes, nil))
 	if cm.configurator != nil {
 		cm.configurator.RegisterConfigApplyFunc(genConfigApplyFunc(cm.codes, cm.atomicCodeMapping))
 	}
@@ -67,27 +78,29 @@ func (cm *CodeConverter) init() {
 
 // Mapping redefine the return code
 func (cm *CodeConverter) Mapping(code, service, method string) *Code {
-	if v, ok := cm.atomicCodeMapping.Load().(map[string][]*Code)[code]; ok {
-		if c := cm.matchCode(v, service, method); c != nil {
-			return c
-		}
-	}
+	return cm.MappingWithErrorType(code, "", service, method)
+}
 
+// MappingWithErrorType redefine the return code, rules with error type only match the same errorType.
+func (cm *CodeConverter) MappingWithErrorType(code, errorType, service, method string) *Code {
+	if c := cm.rules().match(code, errorType, service, method); c != nil {
+		return c
+	}
 	return cm.defaultCodeTypeFunc(code, service, method)
 }
 
-func (cm *CodeConverter) matchCode(codeArray []*Code, service string, method string) *Code {
-	for _, vv := range codeArray {
-		if vv.Service == service || vv.Method == method {
-			return vv
-		}
-
-		// If the service/method is not configured, check whether there is a global configuration
-		if vv.Service == "" && vv.Method == "" {
-			return vv
-		}
+// Explain explains which rule classified the code.
+func (cm *CodeConverter) Explain(code, errorType, service, method string) *Explanation {
+	e := cm.rules().explain(code, errorType, service, method)
+	if e.Result == nil {
+		e.Result = cm.defaultCodeTypeFunc(code, service, method)
+		e.Source = RuleSourceDefault
 	}
-	return nil
+	return e
+}
+
+func (cm *CodeConverter) rules() *codeRules {
+	return cm.atomicCodeMapping.Load().(*codeRules)
 }
 
 type setupOption struct {
@@ -141,33 +154,61 @@ func CodeMapping(code, service, method string) *Code {
 	return nil
 }
 
-func covertCodesToMapping(codes []*Code) map[string][]*Code {
-	codeMapping := make(map[string][]*Code, len(codes))
+// CodeMappingWithErrorType redefine code with the type of errs.Error,
+// falls back to CodeMapping if the mapper does not implement ErrorTypeCodeMapper.
+func CodeMappingWithErrorType(code, errorType, service, method string) *Code {
+	if m, ok := mapper.Load().(ErrorTypeCodeMapper); ok {
+		return m.MappingWithErrorType(code, errorType, service, method)
+	}
+	return CodeMapping(code, service, method)
+}
 
-	// Matching specific service and method at the same time has the highest priority,
-	// and service takes precedence over method
-	sort.Slice(codes, func(i, j int) bool {
-		return (codes[i].Service != "" && codes[j].Service == "") ||
-			(codes[i].Method != "" && codes[j].Method == "")
-	})
-	for _, v := range codes {
-		codeMapping[v.Code] = append(codeMapping[v.Code], v)
+// Explain explains which rule classified the code, returns nil if the mapper does not implement CodeExplainer.
+func Explain(code, errorType, service, method string) *Explanation {
+	if m, ok := mapper.Load().(CodeExplainer); ok {
+		return m.Explain(code, errorType, service, method)
 	}
-	return codeMapping
+	return nil
+}
+
+// ExplainHandler http handler explains which rule classified the code,
+// e.g. /debug/codes?code=10001&service=trpc.app.server.service&method=/trpc.app.server.service/Method&error_type=business
+func ExplainHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		e := Explain(q.Get("code"), q.Get("error_type"), q.Get("service"), q.Get("method"))
+		if e == nil {
+			w.WriteHeader(http.StatusNotImplemented)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "code mapper does not support explain"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(e)
+	})
 }
 
 func genConfigApplyFunc(codes []*Code, atomicCodeMapping *atomic.Value) remote.ConfigApplyFunc {
 	return func(config *operation.Operation) error {
-		// codeMapping
-		codeMapping := covertCodesToMapping(codes)
+		var remoteCodes []*Code
 		for _, v := range config.GetMetric().GetCodes() {
-			codeStr := strconv.FormatInt(int64(v.GetCode()), 10)
-			desc := NewCode(codeStr, CodeType(v.GetType()), v.GetDescription())
-			desc.Service = v.GetService()
-			desc.Method = v.GetMethod()
-			codeMapping[codeStr] = append(codeMapping[codeStr], desc)
+			remoteCodes = append(remoteCodes, convertRemoteCode(v))
 		}
-		atomicCodeMapping.Store(codeMapping)
+		atomicCodeMapping.Store(newCodeRules(codes, remoteCodes))
 		return nil
 	}
 }
+
+func convertRemoteCode(v *operation.Code) *Code {
+	c := NewCode(strconv.FormatInt(int64(v.GetCode()), 10), CodeType(v.GetType()), v.GetDescription())
+	c.Service = v.GetService()
+	c.Method = v.GetMethod()
+	c.ServiceRegex = v.GetServiceRegex()
+	c.MethodRegex = v.GetMethodRegex()
+	c.ErrorType = v.GetErrorType()
+	if v.GetCodeMin() != 0 || v.GetCodeMax() != 0 {
+		c.Code = ""
+		c.CodeMin = int64(v.GetCodeMin())
+		c.CodeMax = int64(v.GetCodeMax())
+	}
+	return c
+}
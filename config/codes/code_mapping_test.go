@@ -82,9 +82,9 @@ func TestCodeConverter_Map(t *testing.T) {
 				Method: "mymethod",
 			},
 		})), args{"10003", "myservice", "mymethod"}, &Code{
-			Code:   "10003",
-			Type:   CodeTypeTimeout.String(),
-			Method: "mymethod",
+			Code:    "10003",
+			Type:    CodeTypeTimeout.String(),
+			Service: "myservice",
 		}},
 		{"test-multiple-codes-with-service-method", New(WithCodes([]*Code{
 			{
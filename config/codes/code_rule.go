@@ -0,0 +1,254 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package codes
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const (
+	// RuleSourceLocal rule from local config
+	RuleSourceLocal = "local"
+	// RuleSourceRemote rule from remote Operation.Metric.codes
+	RuleSourceRemote = "remote"
+	// RuleSourceDefault no rule matched, classified by the default CodeTypeFunc
+	RuleSourceDefault = "default"
+)
+
+// codeRule is a compiled Code.
+type codeRule struct {
+	code         *Code
+	source       string
+	index        int
+	serviceRegex *regexp.Regexp
+	methodRegex  *regexp.Regexp
+}
+
+// codeRules all the rules, the precedence of rules is:
+//  1. exact code over code range;
+//  2. exact service over service regex over any service;
+//  3. exact method over method regex over any method;
+//  4. narrower range over wider range;
+//  5. specified error type over any error type;
+//  6. local rules over remote rules, then the configured order.
+//
+// The first rule whose all conditions match is used.
+type codeRules struct {
+	exact  map[string][]*codeRule
+	ranges []*codeRule
+}
+
+func newCodeRules(local []*Code, remote []*Code) *codeRules {
+	rules := &codeRules{exact: make(map[string][]*codeRule)}
+	add := func(codes []*Code, source string) {
+		for i, c := range codes {
+			r, err := compileCodeRule(c, source, i)
+			if err != nil {
+				log.Printf("opentelemetry: ignore invalid %s code rule %s: %v", source, c, err)
+				continue
+			}
+			if c.IsRange() {
+				rules.ranges = append(rules.ranges, r)
+			} else {
+				rules.exact[c.Code] = append(rules.exact[c.Code], r)
+			}
+		}
+	}
+	add(local, RuleSourceLocal)
+	add(remote, RuleSourceRemote)
+	for _, v := range rules.exact {
+		sortCodeRules(v)
+	}
+	sortCodeRules(rules.ranges)
+	return rules
+}
+
+func compileCodeRule(c *Code, source string, index int) (*codeRule, error) {
+	r := &codeRule{code: c, source: source, index: index}
+	if c.IsRange() && c.CodeMin > c.CodeMax {
+		return nil, fmt.Errorf("code_min %d > code_max %d", c.CodeMin, c.CodeMax)
+	}
+	var err error
+	if c.Service == "" && c.ServiceRegex != "" {
+		if r.serviceRegex, err = regexp.Compile(c.ServiceRegex); err != nil {
+			return nil, err
+		}
+	}
+	if c.Method == "" && c.MethodRegex != "" {
+		if r.methodRegex, err = regexp.Compile(c.MethodRegex); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func sortCodeRules(rules []*codeRule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		a, b := rules[i], rules[j]
+		if sa, sb := a.serviceScore(), b.serviceScore(); sa != sb {
+			return sa > sb
+		}
+		if sa, sb := a.methodScore(), b.methodScore(); sa != sb {
+			return sa > sb
+		}
+		if a.code.IsRange() && b.code.IsRange() {
+			if wa, wb := a.code.CodeMax-a.code.CodeMin, b.code.CodeMax-b.code.CodeMin; wa != wb {
+				return wa < wb
+			}
+		}
+		if sa, sb := a.errorTypeScore(), b.errorTypeScore(); sa != sb {
+			return sa > sb
+		}
+		if a.source != b.source {
+			return a.source == RuleSourceLocal
+		}
+		return a.index < b.index
+	})
+}
+
+func (r *codeRule) methodScore() int {
+	return matcherScore(r.code.Method, r.methodRegex)
+}
+
+func (r *codeRule) serviceScore() int {
+	return matcherScore(r.code.Service, r.serviceRegex)
+}
+
+func (r *codeRule) errorTypeScore() int {
+	if r.code.ErrorType != "" {
+		return 1
+	}
+	return 0
+}
+
+func matcherScore(exact string, re *regexp.Regexp) int {
+	switch {
+	case exact != "":
+		return 2
+	case re != nil:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// mismatch returns the reason why the rule does not match, empty means matched.
+func (r *codeRule) mismatch(errorType, service, method string) string {
+	switch {
+	case r.code.Service != "" && r.code.Service != service:
+		return "service not equal"
+	case r.serviceRegex != nil && !r.serviceRegex.MatchString(service):
+		return "service regex not match"
+	case r.code.Method != "" && r.code.Method != method:
+		return "method not equal"
+	case r.methodRegex != nil && !r.methodRegex.MatchString(method):
+		return "method regex not match"
+	case r.code.ErrorType != "" && r.code.ErrorType != errorType:
+		return "error type not equal"
+	default:
+		return ""
+	}
+}
+
+// candidates returns rules of the code in precedence order.
+func (rs *codeRules) candidates(code string) []*codeRule {
+	candidates := rs.exact[code]
+	if len(rs.ranges) == 0 {
+		return candidates
+	}
+	n, err := strconv.ParseInt(code, 10, 64)
+	if err != nil {
+		return candidates
+	}
+	for _, r := range rs.ranges {
+		if n >= r.code.CodeMin && n <= r.code.CodeMax {
+			candidates = append(candidates[:len(candidates):len(candidates)], r)
+		}
+	}
+	return candidates
+}
+
+func (rs *codeRules) match(code, errorType, service, method string) *Code {
+	if v, ok := rs.exact[code]; ok {
+		for _, r := range v {
+			if r.mismatch(errorType, service, method) == "" {
+				return r.code
+			}
+		}
+	}
+	if len(rs.ranges) == 0 {
+		return nil
+	}
+	n, err := strconv.ParseInt(code, 10, 64)
+	if err != nil {
+		return nil
+	}
+	for _, r := range rs.ranges {
+		if n >= r.code.CodeMin && n <= r.code.CodeMax && r.mismatch(errorType, service, method) == "" {
+			return r.code
+		}
+	}
+	return nil
+}
+
+// Explanation explains which rule classified the code.
+type Explanation struct {
+	Code      string `json:"code"`
+	ErrorType string `json:"error_type"`
+	Service   string `json:"service"`
+	Method    string `json:"method"`
+	// Result the classified code type and description
+	Result *Code `json:"result"`
+	// Source local/remote/default
+	Source string `json:"source"`
+	// Candidates rules of the code in precedence order
+	Candidates []CandidateRule `json:"candidates"`
+}
+
+// CandidateRule a rule considered during classification.
+type CandidateRule struct {
+	Rule   *Code  `json:"rule"`
+	Source string `json:"source"`
+	// Index index of the rule in its source
+	Index   int  `json:"index"`
+	Matched bool `json:"matched"`
+	// Reason why the rule does not match
+	Reason string `json:"reason,omitempty"`
+}
+
+func (rs *codeRules) explain(code, errorType, service, method string) *Explanation {
+	e := &Explanation{Code: code, ErrorType: errorType, Service: service, Method: method}
+	for _, r := range rs.candidates(code) {
+		reason := r.mismatch(errorType, service, method)
+		if reason == "" && e.Result != nil {
+			reason = "shadowed by a rule of higher precedence"
+		}
+		e.Candidates = append(e.Candidates, CandidateRule{
+			Rule:    r.code,
+			Source:  r.source,
+			Index:   r.index,
+			Matched: reason == "",
+			Reason:  reason,
+		})
+		if reason == "" {
+			e.Result = r.code
+			e.Source = r.source
+		}
+	}
+	return e
+}
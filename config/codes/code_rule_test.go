@@ -0,0 +1,133 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package codes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+func TestCodeConverter_MappingWithErrorType(t *testing.T) {
+	c := New(WithCodes([]*Code{
+		{CodeMin: 10000, CodeMax: 19999, Type: CodeTypeSuccess.String(), Description: "wide"},
+		{CodeMin: 10000, CodeMax: 10099, Type: CodeTypeTimeout.String(), Description: "narrow"},
+		{Code: "10001", Type: CodeTypeException.String(), Description: "exact"},
+		{CodeMin: 20000, CodeMax: 20099, Type: CodeTypeSuccess.String(), Description: "regex",
+			ServiceRegex: `^trpc\.app\..*`, MethodRegex: `^/.*/Get.*`},
+		{CodeMin: 20000, CodeMax: 20099, Type: CodeTypeTimeout.String(), Description: "exact method",
+			ServiceRegex: `^trpc\.app\..*`, Method: "/trpc.app.server.service/GetUser"},
+		{Code: "21", Type: CodeTypeSuccess.String(), Description: "business 21", ErrorType: ErrorTypeBusiness},
+		{Code: "10003", Type: CodeTypeSuccess.String(), Description: "other method", Method: "/other/Method"},
+	}))
+	tests := []struct {
+		name                             string
+		code, errorType, service, method string
+		wantDescription                  string
+	}{
+		{"exact-over-range", "10001", "", "", "", "exact"},
+		{"narrow-range-over-wide-range", "10002", "", "", "", "narrow"},
+		{"wide-range", "10100", "", "", "", "wide"},
+		{"range-bounds", "19999", "", "", "", "wide"},
+		{"out-of-range", "30000", "", "", "", "code!=0"},
+		{"not-a-number", "abc", "", "", "", "code!=0"},
+		{"regex", "20001", "", "trpc.app.server.service", "/trpc.app.server.service/GetItem", "regex"},
+		{"regex-not-match", "20001", "", "trpc.other.server.service", "/trpc.app.server.service/GetItem",
+			"code!=0"},
+		{"exact-method-over-regex", "20001", "", "trpc.app.server.service", "/trpc.app.server.service/GetUser",
+			"exact method"},
+		{"error-type-match", "21", ErrorTypeBusiness, "", "", "business 21"},
+		{"error-type-not-match", "21", ErrorTypeFramework, "", "", "code!=0"},
+		{"all-conditions-must-match", "10003", "", "", "/my/Method", "narrow"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.MappingWithErrorType(tt.code, tt.errorType, tt.service, tt.method)
+			require.NotNil(t, got)
+			assert.Equal(t, tt.wantDescription, got.Description)
+		})
+	}
+}
+
+func TestCodeConverter_InvalidRules(t *testing.T) {
+	c := New(WithCodes([]*Code{
+		{CodeMin: 200, CodeMax: 100, Type: CodeTypeSuccess.String()},
+		{Code: "1", Type: CodeTypeSuccess.String(), ServiceRegex: "("},
+	}))
+	assert.Equal(t, CodeTypeException.String(), c.Mapping("150", "", "").Type)
+	assert.Equal(t, CodeTypeException.String(), c.Mapping("1", "", "").Type)
+}
+
+func TestCodeRules_LocalOverRemote(t *testing.T) {
+	local := []*Code{{CodeMin: 100, CodeMax: 199, Type: CodeTypeSuccess.String(), Description: "local"}}
+	remote := []*Code{
+		convertRemoteCode(&operation.Code{CodeMin: 100, CodeMax: 199, Type: "timeout", Description: "remote"}),
+		convertRemoteCode(&operation.Code{CodeMin: 100, CodeMax: 109, Type: "timeout", Description: "remote narrow"}),
+		convertRemoteCode(&operation.Code{Code: 200, Type: "timeout", MethodRegex: "^/a/.*", ErrorType: "framework"}),
+	}
+	rules := newCodeRules(local, remote)
+	assert.Equal(t, "local", rules.match("150", "", "", "").Description)
+	assert.Equal(t, "remote narrow", rules.match("101", "", "", "").Description)
+	assert.Equal(t, "200", rules.match("200", ErrorTypeFramework, "", "/a/B").Code)
+	assert.Nil(t, rules.match("200", ErrorTypeBusiness, "", "/a/B"))
+}
+
+func TestCodeRules_ServiceOverMethod(t *testing.T) {
+	rules := newCodeRules([]*Code{
+		{CodeMin: 100, CodeMax: 109, Type: CodeTypeTimeout.String(), Description: "method",
+			Method: "/trpc.app.server.service/Get"},
+		{CodeMin: 100, CodeMax: 199, Type: CodeTypeSuccess.String(), Description: "service",
+			Service: "trpc.app.server.service"},
+		{CodeMin: 100, CodeMax: 199, Type: CodeTypeSuccess.String(), Description: "service regex",
+			ServiceRegex: `^trpc\.app\..*`},
+	}, nil)
+	assert.Equal(t, "service",
+		rules.match("101", "", "trpc.app.server.service", "/trpc.app.server.service/Get").Description)
+	assert.Equal(t, "service regex",
+		rules.match("101", "", "trpc.app.other.service", "/trpc.app.server.service/Get").Description)
+	assert.Equal(t, "method", rules.match("101", "", "", "/trpc.app.server.service/Get").Description)
+}
+
+func TestExplainHandler(t *testing.T) {
+	old := mapper.Load()
+	defer mapper.Store(old)
+	SetMapper(New(WithCodes([]*Code{
+		{CodeMin: 1, CodeMax: 100, Type: CodeTypeTimeout.String(), Description: "range"},
+		{Code: "10", Type: CodeTypeSuccess.String(), Description: "exact"},
+		{Code: "10", Type: CodeTypeSuccess.String(), Description: "service", Service: "other"},
+	})))
+
+	rec := httptest.NewRecorder()
+	ExplainHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/codes?code=10&service=s", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var e Explanation
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.Equal(t, "exact", e.Result.Description)
+	assert.Equal(t, RuleSourceLocal, e.Source)
+	require.Len(t, e.Candidates, 3)
+	assert.Equal(t, "service not equal", e.Candidates[0].Reason)
+	assert.True(t, e.Candidates[1].Matched)
+	assert.False(t, e.Candidates[2].Matched)
+	assert.Equal(t, "shadowed by a rule of higher precedence", e.Candidates[2].Reason)
+
+	e = *Explain("1000", "", "", "")
+	assert.Equal(t, RuleSourceDefault, e.Source)
+	assert.Empty(t, e.Candidates)
+}
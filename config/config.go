@@ -20,6 +20,9 @@ import (
 	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
 	"trpc.group/trpc-go/trpc-opentelemetry/api/log"
 	"trpc.group/trpc-go/trpc-opentelemetry/config/codes"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
+	pkgruntime "trpc.group/trpc-go/trpc-opentelemetry/pkg/runtime"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/tracestore"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
@@ -34,6 +37,27 @@ type Config struct {
 	Codes      []*codes.Code     `yaml:"codes"`
 	Attributes []*Attribute      `yaml:"attributes"`
 	Headers    map[string]string `yaml:"headers"`
+	// AutoTune sets GOMAXPROCS and the Go soft memory limit from the container quotas
+	AutoTune pkgruntime.AutoTuneConfig `yaml:"auto_tune"`
+	// Remote configurator of the Operation of the server
+	Remote RemoteConfig `yaml:"remote"`
+}
+
+// RemoteConfig remote configurator config
+type RemoteConfig struct {
+	// Type grpc (default), http, file or none
+	Type string `yaml:"type"`
+	// Addr grpc address of OperationService for grpc, default sampler.sampler_server_addr,
+	// or the grpc-gateway endpoint for http, e.g. http://127.0.0.1:12521
+	Addr string `yaml:"addr"`
+	// Path YAML or protobuf JSON Operation file for file
+	Path string `yaml:"path"`
+	// SyncInterval default 1m, 10s for file
+	SyncInterval time.Duration `yaml:"sync_interval"`
+	// HistorySize number of the configs kept in the history of /debug/remote, default 10
+	HistorySize int `yaml:"history_size"`
+	// ReportStatus reports the apply status by OperationService.ReportApplyStatus, grpc only
+	ReportStatus bool `yaml:"report_status"`
 }
 
 // TracesConfig traces config
@@ -50,6 +74,13 @@ type TracesConfig struct {
 	DisableParentSampling bool `yaml:"disable_parent_sampling"`
 	// EnableZPage local zpage
 	EnableZPage bool `yaml:"enable_zpage"`
+	// FanOutThreshold server spans whose calls to a callee exceed it get a fan-out event and metric,
+	// disabled if not positive
+	FanOutThreshold int `yaml:"fan_out_threshold"`
+	// PprofLabels sets the trace_id, span_id and rpc_method pprof labels on the goroutines handling the server spans
+	PprofLabels bool `yaml:"pprof_labels"`
+	// RecentTraces keeps the recently completed local traces in memory, served by /debug/traces
+	RecentTraces tracestore.Config `yaml:"recent_traces"`
 
 	// ExportConfig config of trace exporter
 	ExportConfig TraceExporterOption `yaml:"export_config"`
@@ -99,6 +130,12 @@ type MetricsConfig struct {
 	// EnabledRegister if true, register service to registry
 	EnabledRegister   bool     `yaml:"enable_register"`
 	RegistryEndpoints []string `yaml:"registry_endpoints"`
+	// Registry registry of enable_register: etcd (default), file_sd or http_sd
+	Registry string `yaml:"registry"`
+	// FileSD file_sd registry config, used if Registry is file_sd
+	FileSD metric.FileSDConfig `yaml:"file_sd"`
+	// HTTPSD http_sd registry config, used if Registry is http_sd
+	HTTPSD metric.HTTPSDConfig `yaml:"http_sd"`
 	// TLSCert certificate chain, private key, and root CA certificate
 	TLSCert     metric.TLSCert `yaml:"tls_cert"`
 	ServerOwner string         `yaml:"server_owner"`
@@ -107,10 +144,40 @@ type MetricsConfig struct {
 	Codes                  []*codes.Code                                 `yaml:"codes"`
 	ClientHistogramBuckets []float64                                     `yaml:"client_histogram_buckets"`
 	ServerHistogramBuckets []float64                                     `yaml:"server_histogram_buckets"`
+	// ClientHistogramBucketsOverrides client histogram buckets by callee service/method
+	ClientHistogramBucketsOverrides []metric.HistogramBucketsOverride `yaml:"client_histogram_buckets_overrides"`
+	// ServerHistogramBucketsOverrides server histogram buckets by callee service/method
+	ServerHistogramBucketsOverrides []metric.HistogramBucketsOverride `yaml:"server_histogram_buckets_overrides"`
+	// Sinks sinks of the trpc metrics API (metrics.IncrCounter etc.): prometheus (default) and otlp,
+	// otlp enables the OTLP metric pipeline exporting to Addr
+	Sinks []string `yaml:"sinks"`
+	// OTLPSink otlp sink config
+	OTLPSink OTLPSinkConfig `yaml:"otlp_sink"`
+	// SinkHistogramBuckets histogram buckets of the trpc metrics API (metrics.AddSample, metrics.RecordTimer) by metric name
+	SinkHistogramBuckets map[string][]float64 `yaml:"sink_histogram_buckets"`
+	// EnabledPayloadSizeHistogram report request/response body and metadata size histograms
+	EnabledPayloadSizeHistogram bool `yaml:"enabled_payload_size_histogram"`
+	// PeerMetrics opt-in per-peer client metrics and outlier detection
+	PeerMetrics metric.PeerMetricsConfig `yaml:"peer_metrics"`
+	// RuntimeMetrics opt-in Go runtime/metrics collector, exported to both prometheus and OTLP
+	RuntimeMetrics metric.RuntimeMetricsConfig `yaml:"runtime_metrics"`
+	// NativeHistogram prometheus native histogram config, exposed by protobuf scrape format
+	NativeHistogram metric.NativeHistogramConfig `yaml:"native_histogram"`
 	// DisableRPCMethodMapping do not process with RPCName (cannot be true when using restful API)
 	DisableRPCMethodMapping bool `yaml:"disable_rpc_method_mapping"`
 	// PrometheusPush prometheus push config
 	PrometheusPush metric.PrometheusPushConfig `yaml:"prometheus_push"`
+	// RemoteWrite prometheus remote-write config, metrics are sent to the endpoint instead of being scraped
+	RemoteWrite metric.RemoteWriteConfig `yaml:"remote_write"`
+	// AdminAuth TLS, basic/bearer auth, path allowlist and POST-only mutations of the admin endpoints
+	AdminAuth auth.Config `yaml:"admin_auth"`
+}
+
+// OTLPSinkConfig config of the OTLP sink of the trpc metrics API
+type OTLPSinkConfig struct {
+	// CompatibleNames names instruments and attributes as the prometheus sink does,
+	// e.g. trpc_counter_total{_name="name", _type="counter"}
+	CompatibleNames bool `yaml:"compatible_names"`
 }
 
 // LogsConfig defines the configuration for the various elements of Logs
@@ -211,6 +278,7 @@ func DefaultConfig() Config {
 			Enabled:           true,
 			EnabledRegister:   true,
 			RegistryEndpoints: []string{"localhost:2379"},
+			NativeHistogram:   metric.DefaultNativeHistogramConfig(),
 		},
 		Logs: LogsConfig{
 			Enabled:      false,
@@ -0,0 +1,104 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maskedValue replaces the secrets in Masked
+const maskedValue = "******"
+
+// sensitiveKeys the values of the keys containing any of them are masked, key_content and private_key hold
+// the PEM of private keys, e.g. metrics.tls_cert.key_content
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "credential", "key_content", "private_key"}
+
+// Masked returns the config as a map keyed by the yaml names, the secrets and all header values are masked.
+func (c Config) Masked() (map[string]interface{}, error) {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return maskMap(m), nil
+}
+
+func maskMap(m map[string]interface{}) map[string]interface{} {
+	for k, v := range m {
+		switch {
+		case isHeadersKey(k):
+			m[k] = maskAll(v)
+		case isSensitiveKey(k):
+			if v != nil && v != "" {
+				m[k] = maskedValue
+			}
+		default:
+			m[k] = maskValue(v)
+		}
+	}
+	return m
+}
+
+func maskValue(v interface{}) interface{} {
+	switch vv := v.(type) {
+	case map[string]interface{}:
+		return maskMap(vv)
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(vv))
+		for k, v := range vv {
+			m[fmt.Sprint(k)] = v
+		}
+		return maskMap(m)
+	case []interface{}:
+		for i := range vv {
+			vv[i] = maskValue(vv[i])
+		}
+		return vv
+	default:
+		return v
+	}
+}
+
+// maskAll masks all values of the headers
+func maskAll(v interface{}) interface{} {
+	m, ok := maskValue(v).(map[string]interface{})
+	if !ok {
+		return v
+	}
+	for k := range m {
+		m[k] = maskedValue
+	}
+	return m
+}
+
+func isHeadersKey(k string) bool {
+	k = strings.ToLower(k)
+	return k == "headers" || strings.HasSuffix(k, "_headers")
+}
+
+func isSensitiveKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -36,6 +36,7 @@ import (
 
 	"trpc.group/trpc-go/trpc-opentelemetry/exporter/retry"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/log"
 )
 
@@ -113,6 +114,16 @@ func (e *Exporter) lastConnectError() error {
 	return *errPtr
 }
 
+// ConnectionStatus returns the collector address and the state of the last connection.
+func (e *Exporter) ConnectionStatus() telemetrystatus.ExporterStatus {
+	s := telemetrystatus.ExporterStatus{Address: e.c.collectorAddr, Connected: true}
+	if err := e.lastConnectError(); err != nil {
+		s.Connected = false
+		s.LastConnectError = err.Error()
+	}
+	return s
+}
+
 // ExportLogs export log
 func (e *Exporter) ExportLogs(parent context.Context, logs []*logsproto.ResourceLogs) error {
 	defer func() {
@@ -32,6 +32,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	"trpc.group/trpc-go/trpc-opentelemetry/exporter/retry"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/log"
 )
 
@@ -106,6 +107,16 @@ func (e *Exporter) lastConnectError() error {
 	return *errPtr
 }
 
+// ConnectionStatus returns the collector address and the state of the last connection.
+func (e *Exporter) ConnectionStatus() telemetrystatus.ExporterStatus {
+	s := telemetrystatus.ExporterStatus{Address: e.c.collectorAddr, Connected: true}
+	if err := e.lastConnectError(); err != nil {
+		s.Connected = false
+		s.LastConnectError = err.Error()
+	}
+	return s
+}
+
 // ExportLogs export log
 func (e *Exporter) ExportLogs(parent context.Context, logs []*logsproto.ResourceLogs) error {
 	ctx, cancel := context.WithCancel(parent)
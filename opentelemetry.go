@@ -16,6 +16,7 @@ package opentelemetry
 import (
 	"context"
 	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -35,6 +36,9 @@ import (
 	apilog "trpc.group/trpc-go/trpc-opentelemetry/api/log"
 	ecosystemotlp "trpc.group/trpc-go/trpc-opentelemetry/exporter/otlp"
 	"trpc.group/trpc-go/trpc-opentelemetry/exporter/retry"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/profiling"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/tracestore"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/zpage"
 	sdklog "trpc.group/trpc-go/trpc-opentelemetry/sdk/log"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/trace"
@@ -79,6 +83,11 @@ func WithSpan(ctx context.Context, spanName string, fn func(ctx context.Context)
 ) error {
 	ctx, sp := globalTracer.Start(ctx, spanName, opts...)
 	defer sp.End()
+	if profiling.Enabled() {
+		var restore func()
+		ctx, restore = profiling.WithSpanLabels(ctx, sp.SpanContext(), profiling.LabelSpanName, spanName)
+		defer restore()
+	}
 	return fn(ctx)
 }
 
@@ -164,13 +173,21 @@ func setup(addr string, options ...SetupOption) error {
 
 	var opts []sdktrace.TracerProviderOption
 	opts = append(opts, sdktrace.WithSampler(o.sampler))
-	opts = append(opts, sdktrace.WithSpanProcessor(
-		trace.NewDeferredSampleProcessor(
-			trace.NewBatchSpanProcessor(exp, o.batchSpanOption...), o.deferredSampler)))
+	bsp := trace.NewBatchSpanProcessor(exp, o.batchSpanOption...)
+	opts = append(opts, sdktrace.WithSpanProcessor(trace.NewDeferredSampleProcessor(bsp, o.deferredSampler)))
+	registerTraceStatus(o.sampler, bsp)
 
 	if o.zPageEnabled {
 		opts = append(opts, sdktrace.WithSpanProcessor(zpage.GetZPageProcessor()))
 	}
+	if o.pprofLabels {
+		profiling.SetEnabled(true)
+	}
+	if o.recentTraces.Enabled {
+		p := tracestore.NewProcessor(o.recentTraces)
+		tracestore.SetDefault(p)
+		opts = append(opts, sdktrace.WithSpanProcessor(p))
+	}
 
 	kvs := []attribute.KeyValue{
 		api.TpsTenantIDKey.String(o.tenantID),
@@ -194,6 +211,13 @@ func setup(addr string, options ...SetupOption) error {
 	}
 
 	res := resource.NewWithAttributes(semconv.SchemaURL, kvs...)
+	telemetrystatus.Register(telemetrystatus.SectionResource, func() interface{} {
+		attrs := make(map[string]string, res.Len())
+		for _, kv := range res.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		return attrs
+	})
 
 	if o.metricEnabled {
 		if err = setupMetric(addr, res, o); err != nil {
@@ -264,7 +288,8 @@ func setupMetric(addr string, res *resource.Resource, o *setupOptions) (err erro
 		return err
 	}
 	meterProvider = sdkmetric.NewMeterProvider(
-		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(*exporter)), sdkmetric.WithResource(res))
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(*exporter)), sdkmetric.WithResource(res),
+		sdkmetric.WithView(o.metricViews...))
 	otel.SetMeterProvider(meterProvider)
 	return nil
 }
@@ -281,15 +306,33 @@ func setupLog(addr string, o *setupOptions, kvs []attribute.KeyValue) (err error
 	if err != nil {
 		return err
 	}
+	bp := sdklog.NewBatchProcessor(exporter)
 	logger := sdklog.NewLogger(
 		sdklog.WithResource(resource.NewWithAttributes(semconv.SchemaURL, kvs...)),
-		sdklog.WithBatcher(sdklog.NewBatchProcessor(exporter)),
+		sdklog.WithBatcher(bp),
 		sdklog.WithLevelEnable(o.enabledLogLevel),
 	)
 	apilog.SetGlobalLogger(logger)
+	telemetrystatus.Register(telemetrystatus.SectionLogs, func() interface{} { return bp.QueueStatus() })
+	telemetrystatus.RegisterExporter("log_exporter", exporter.ConnectionStatus)
 	return nil
 }
 
+// registerTraceStatus registers the sampler and the span queue to /debug/telemetry
+func registerTraceStatus(sampler sdktrace.Sampler, bsp sdktrace.SpanProcessor) {
+	telemetrystatus.Register(telemetrystatus.SectionSampler, func() interface{} {
+		if s, ok := sampler.(*trace.Sampler); ok {
+			return s.Status()
+		}
+		return map[string]string{"description": sampler.Description()}
+	})
+	if q, ok := bsp.(interface {
+		QueueStatus() telemetrystatus.QueueStatus
+	}); ok {
+		telemetrystatus.Register(telemetrystatus.SectionSpans, func() interface{} { return q.QueueStatus() })
+	}
+}
+
 type setupOptions struct {
 	tenantID         string
 	sampler          sdktrace.Sampler
@@ -302,6 +345,8 @@ type setupOptions struct {
 	metricEnabled    bool
 	httpEnabled      bool
 	zPageEnabled     bool
+	recentTraces     tracestore.Config
+	pprofLabels      bool
 	ServerOwner      string
 	CmdbID           string
 	additionalLabels []attribute.KeyValue
@@ -309,6 +354,7 @@ type setupOptions struct {
 	batchSpanOption  []trace.BatchSpanProcessorOption
 	idGenerator      sdktrace.IDGenerator
 	otlptraceHeader  map[string]string
+	metricViews      []sdkmetric.View
 }
 
 func defaultSetupOptions() *setupOptions {
@@ -426,6 +472,14 @@ func WithMetricEnabled(enabled bool) SetupOption {
 	}
 }
 
+// WithMetricViews sets the views of the OTLP MeterProvider, such as histogram buckets of instruments,
+// it only works with WithMetricEnabled.
+func WithMetricViews(views ...sdkmetric.View) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.metricViews = append(cfg.metricViews, views...)
+	}
+}
+
 // WithHTTPEnabled enabled http protocol, default is grpc
 func WithHTTPEnabled(enabled bool) SetupOption {
 	return func(cfg *setupOptions) {
@@ -447,8 +501,52 @@ func WithZPageSpanProcessor(enable bool) SetupOption {
 	}
 }
 
+// WithRecentTraces keeps the recently completed local traces in memory, served by the /debug/traces admin endpoints
+func WithRecentTraces(recentTraces tracestore.Config) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.recentTraces = recentTraces
+	}
+}
+
+// WithPprofLabels sets the trace_id, span_id and rpc_method pprof labels on the goroutines handling the spans,
+// which correlates the CPU profiles of /debug/pprof/labels with the traces at the cost of a few allocations per span
+func WithPprofLabels(enable bool) SetupOption {
+	return func(cfg *setupOptions) {
+		cfg.pprofLabels = enable
+	}
+}
+
+var (
+	shutdownHooksMu sync.Mutex
+	shutdownHooks   []func(ctx context.Context) error
+)
+
+// RegisterShutdownHook registers f to be called by Shutdown before the providers are shut down,
+// e.g. to deregister the metrics endpoint. Hooks are called in reverse order of registration.
+func RegisterShutdownHook(f func(ctx context.Context) error) {
+	shutdownHooksMu.Lock()
+	defer shutdownHooksMu.Unlock()
+	shutdownHooks = append(shutdownHooks, f)
+}
+
+// runShutdownHooks calls and removes the shutdown hooks, all hooks are called and the first error is returned.
+func runShutdownHooks(ctx context.Context) error {
+	shutdownHooksMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownHooksMu.Unlock()
+	var firstErr error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
 // Shutdown report all data before process exit
 func Shutdown(ctx context.Context) error {
+	hooksErr := runShutdownHooks(ctx)
 	if meterProvider != nil {
 		if err := meterProvider.Shutdown(ctx); err != nil {
 			return err
@@ -464,5 +562,5 @@ func Shutdown(ctx context.Context) error {
 			return err
 		}
 	}
-	return nil
+	return hooksErr
 }
@@ -16,6 +16,7 @@ package codes
 
 import (
 	"context"
+	"errors"
 	"strconv"
 
 	"trpc.group/trpc-go/trpc-go/errs"
@@ -96,3 +97,20 @@ func SetDefaultGetCodeFunc(f GetStringCodeFunc) {
 func GetDefaultGetCodeFunc() GetStringCodeFunc {
 	return defaultGetCodeFunc
 }
+
+// ErrorType returns the error type of err used by code rules,
+// nil err means the code comes from the response body, which is a business code.
+func ErrorType(err error) string {
+	var e *errs.Error
+	if err == nil || !errors.As(err, &e) {
+		return codes.ErrorTypeBusiness
+	}
+	switch e.Type {
+	case errs.ErrorTypeFramework:
+		return codes.ErrorTypeFramework
+	case errs.ErrorTypeCalleeFramework:
+		return codes.ErrorTypeCalleeFramework
+	default:
+		return codes.ErrorTypeBusiness
+	}
+}
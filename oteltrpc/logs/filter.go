@@ -20,11 +20,13 @@ import (
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	"trpc.group/trpc-go/trpc-go"
 	"trpc.group/trpc-go/trpc-go/errs"
 	"trpc.group/trpc-go/trpc-go/filter"
 
 	apilog "trpc.group/trpc-go/trpc-opentelemetry/api/log"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/semconv"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 )
 
 var (
@@ -44,6 +46,9 @@ func durationToMilliseconds(duration time.Duration) float64 {
 // ServerFilter get server filters
 func ServerFilter() filter.ServerFilter {
 	return func(ctx context.Context, req interface{}, handle filter.ServerHandleFunc) (rsp interface{}, err error) {
+		if flowLogDisabled(ctx) {
+			return handle(ctx, req)
+		}
 		startTime := time.Now()
 		var fields []attribute.KeyValue
 		fields = append(fields, attribute.String("trpc.start_time", startTime.Format(time.RFC3339)))
@@ -69,6 +74,9 @@ func ServerFilter() filter.ServerFilter {
 // ClientFilter get client filters
 func ClientFilter() filter.ClientFilter {
 	return func(ctx context.Context, req interface{}, rsp interface{}, handle filter.ClientHandleFunc) (err error) {
+		if flowLogDisabled(ctx) {
+			return handle(ctx, req, rsp)
+		}
 		fields := []attribute.KeyValue{
 			systemField,
 			clientField,
@@ -84,3 +92,9 @@ func ClientFilter() filter.ClientFilter {
 		return err
 	}
 }
+
+// flowLogDisabled reports whether the logs of the rpc are disabled by the switches
+func flowLogDisabled(ctx context.Context) bool {
+	msg := trpc.Message(ctx)
+	return oteladmin.Disabled(oteladmin.SignalFlowLog, msg.CalleeServiceName(), msg.CalleeMethod())
+}
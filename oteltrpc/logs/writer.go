@@ -42,6 +42,8 @@ import (
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/consts"
 	otelprometheus "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics/prometheus"
 	"trpc.group/trpc-go/trpc-opentelemetry/otelzap"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 	sdklog "trpc.group/trpc-go/trpc-opentelemetry/sdk/log"
 )
 
@@ -101,14 +103,14 @@ func (w writer) Setup(name string, configDec plugin.Decoder) error {
 	}
 	opts = append(opts, sdklog.WithEnableSampler(cfg.Logs.EnableSampler))
 	opts = append(opts, sdklog.WithEnableSamplerError(cfg.Logs.EnableSamplerError))
-	decoder.Core, decoder.ZapLevel = otelzap.NewBatchCoreAndLevel(
-		otelzap.NewBatchWriteSyncer(
-			exp,
-			resource.NewWithAttributes(semconv.SchemaURL, kvs...),
-			getBatchSyncerOptions(cfg.Logs)...,
-		),
-		opts...,
+	ws := otelzap.NewBatchWriteSyncer(
+		exp,
+		resource.NewWithAttributes(semconv.SchemaURL, kvs...),
+		getBatchSyncerOptions(cfg.Logs)...,
 	)
+	decoder.Core, decoder.ZapLevel = otelzap.NewBatchCoreAndLevel(ws, opts...)
+	decoder.Core = switchCore{Core: decoder.Core}
+	registerTelemetryStatus(ws, exp)
 
 	if enableLogRateLimit(cfg) {
 		decoder.Core = zapcore.NewSamplerWithOptions(decoder.Core,
@@ -119,6 +121,34 @@ func (w writer) Setup(name string, configDec plugin.Decoder) error {
 	return nil
 }
 
+// switchCore drops the entries if the log export is disabled by the admin switches
+type switchCore struct {
+	zapcore.Core
+}
+
+// With adds fields to the wrapped core
+func (c switchCore) With(fields []zapcore.Field) zapcore.Core {
+	return switchCore{Core: c.Core.With(fields)}
+}
+
+// Check checks the entry by the wrapped core if the log export is enabled
+func (c switchCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if oteladmin.Disabled(oteladmin.SignalLogExport, "", "") {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+// registerTelemetryStatus registers the log queue and the exporter connection to /debug/telemetry
+func registerTelemetryStatus(ws *otelzap.BatchWriteSyncer, exp sdklog.Exporter) {
+	telemetrystatus.Register(telemetrystatus.SectionLogs, func() interface{} { return ws.QueueStatus() })
+	if c, ok := exp.(interface {
+		ConnectionStatus() telemetrystatus.ExporterStatus
+	}); ok {
+		telemetrystatus.RegisterExporter("log_exporter", c.ConnectionStatus)
+	}
+}
+
 func getBatchSyncerOptions(cfg config.LogsConfig) []otelzap.BatchSyncerOption {
 	queueSize := otelzap.DefaultMaxQueueSize
 	batchSize := otelzap.DefaultMaxExportBatchSize
@@ -22,6 +22,8 @@ import (
 
 	"trpc.group/trpc-go/trpc-go"
 	"trpc.group/trpc-go/trpc-go/filter"
+
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 )
 
 var (
@@ -42,6 +44,9 @@ func ServerFilter(opts ...Option) filter.ServerFilter {
 	o := evaluateServerOpt(opts)
 	return func(ctx context.Context, req interface{}, handle filter.ServerHandleFunc) (rsp interface{}, err error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalFlowLog, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req)
+		}
 		startTime := time.Now()
 		var fields []zapcore.Field
 		fields = append(fields, zap.String("trpc.start_time", startTime.Format(time.RFC3339)))
@@ -74,6 +79,9 @@ func ClientFilter(opts ...Option) filter.ClientFilter {
 	o := evaluateClientOpt(opts)
 	return func(ctx context.Context, req interface{}, rsp interface{}, handle filter.ClientHandleFunc) (err error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalFlowLog, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req, rsp)
+		}
 		fields := []zapcore.Field{
 			systemField,
 			clientField,
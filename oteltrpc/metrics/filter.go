@@ -23,6 +23,8 @@ import (
 	"trpc.group/trpc-go/trpc-go"
 	"trpc.group/trpc-go/trpc-go/errs"
 	"trpc.group/trpc-go/trpc-go/filter"
+
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 )
 
 var (
@@ -35,6 +37,9 @@ var (
 func ServerFilter() filter.ServerFilter {
 	return func(ctx context.Context, req interface{}, handle filter.ServerHandleFunc) (rsp interface{}, err error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req)
+		}
 		startTime := time.Now()
 		serverStartedCounter.Add(ctx, 1, metric.WithAttributes(trpcService.String(msg.CalleeServiceName()),
 			trpcMethod.String(msg.CalleeMethod())))
@@ -54,6 +59,9 @@ func ServerFilter() filter.ServerFilter {
 func ClientFilter() filter.ClientFilter {
 	return func(ctx context.Context, req, rsp interface{}, handle filter.ClientHandleFunc) (err error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req, rsp)
+		}
 		startTime := time.Now()
 		clientStartedCounter.Add(ctx, 1, metric.WithAttributes(trpcService.String(msg.CalleeServiceName()),
 			trpcMethod.String(msg.CalleeMethod())))
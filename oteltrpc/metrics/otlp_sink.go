@@ -0,0 +1,342 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/aggregation"
+
+	"trpc.group/trpc-go/trpc-go/metrics"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/consts"
+	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics/prometheus"
+)
+
+const (
+	// OTLPSinkName name of OTLPSink, it is different from the prometheus Sink so both of them can be registered.
+	OTLPSinkName = consts.PluginName + "_otlp"
+	// OTLPSinkMeterName instrumentation scope name of the instruments created by OTLPSink
+	OTLPSinkMeterName = "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics"
+)
+
+// OTLPSink implements metrics.Sink, it reports the trpc metrics API to OTLP by a MeterProvider.
+// Dimensions are mapped to attributes, and policies are mapped to instruments:
+// PolicySUM => Float64Counter
+// PolicySET => Float64ObservableGauge of the last value
+// PolicyAVG, PolicyMAX, PolicyMIN => Float64ObservableGauge of the average, maximum or minimum of the last
+// completed fixed window of one minute, the same as the prometheus Sink
+// PolicyTimer => Float64Histogram in seconds
+// PolicyHistogram, PolicyMID => Float64Histogram, buckets are set by the views of OTLPSinkHistogramViews
+// Instruments are named by the metric name, counters and gauges of multi-dimension records are
+// prefixed by the record name, e.g. record_name.metric_name.
+// If compatible names are enabled, instruments and attributes are named as the prometheus Sink does,
+// e.g. trpc_counter_total{_name="metric_name", _type="counter"}, so the same panels work for both of them.
+type OTLPSink struct {
+	meter           metric.Meter
+	compatibleNames bool
+	now             func() time.Time
+
+	mu         sync.Mutex
+	counters   sync.Map // instrument name => metric.Float64Counter
+	gauges     sync.Map // instrument name => *otlpGauge
+	histograms sync.Map // instrument name => metric.Float64Histogram
+}
+
+// OTLPSinkOption OTLPSink option
+type OTLPSinkOption func(*OTLPSink)
+
+// WithOTLPSinkCompatibleNames names instruments and attributes as the prometheus Sink does.
+func WithOTLPSinkCompatibleNames(enabled bool) OTLPSinkOption {
+	return func(s *OTLPSink) {
+		s.compatibleNames = enabled
+	}
+}
+
+// NewOTLPSink creates a new OTLPSink reporting to the meter provider.
+func NewOTLPSink(provider metric.MeterProvider, opts ...OTLPSinkOption) *OTLPSink {
+	s := &OTLPSink{meter: provider.Meter(OTLPSinkMeterName), now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name implements metrics.Sink.
+func (*OTLPSink) Name() string {
+	return OTLPSinkName
+}
+
+// Report implements metrics.Sink, records with prometheus.WithNoSink are skipped as well.
+func (s *OTLPSink) Report(rec metrics.Record, opts ...metrics.Option) error {
+	if prometheus.HasNoSink(opts...) {
+		return nil
+	}
+	ctx := context.Background()
+	var firstErr error
+	for _, m := range rec.GetMetrics() {
+		if err := s.report(ctx, rec.Name, rec.GetDimensions(), m); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *OTLPSink) report(ctx context.Context, recName string, dimensions []*metrics.Dimension,
+	m *metrics.Metrics) error {
+	value := m.Value()
+	switch m.Policy() {
+	case metrics.PolicySUM:
+		name, attrs := s.counterName(recName, m.Name(), dimensions)
+		c, err := s.counter(name, m.Name())
+		if err != nil {
+			return err
+		}
+		c.Add(ctx, value, metric.WithAttributes(attrs...))
+	case metrics.PolicyTimer, metrics.PolicyHistogram, metrics.PolicyMID:
+		unit := ""
+		if m.Policy() == metrics.PolicyTimer {
+			value, unit = time.Duration(int64(value)).Seconds(), "s"
+		}
+		name, attrs := s.histogramName(m.Name(), m.Policy(), dimensions)
+		h, err := s.histogram(name, m.Name(), unit)
+		if err != nil {
+			return err
+		}
+		h.Record(ctx, value, metric.WithAttributes(attrs...))
+	default:
+		policy := m.Policy()
+		if policy != metrics.PolicyAVG && policy != metrics.PolicyMAX && policy != metrics.PolicyMIN {
+			policy = metrics.PolicySET
+		}
+		name, attrs := s.gaugeName(recName, m.Name(), policy, dimensions)
+		g, err := s.gauge(name, m.Name())
+		if err != nil {
+			return err
+		}
+		g.observe(policy, attribute.NewSet(attrs...), value)
+	}
+	return nil
+}
+
+func (s *OTLPSink) counter(name, desc string) (metric.Float64Counter, error) {
+	if c, ok := s.counters.Load(name); ok {
+		return c.(metric.Float64Counter), nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.counters.Load(name); ok {
+		return c.(metric.Float64Counter), nil
+	}
+	c, err := s.meter.Float64Counter(name, metric.WithDescription(desc))
+	if err != nil {
+		return nil, fmt.Errorf("opentelemetry: create counter %s err: %w", name, err)
+	}
+	s.counters.Store(name, c)
+	return c, nil
+}
+
+func (s *OTLPSink) histogram(name, desc, unit string) (metric.Float64Histogram, error) {
+	if h, ok := s.histograms.Load(name); ok {
+		return h.(metric.Float64Histogram), nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if h, ok := s.histograms.Load(name); ok {
+		return h.(metric.Float64Histogram), nil
+	}
+	h, err := s.meter.Float64Histogram(name, metric.WithDescription(desc), metric.WithUnit(unit))
+	if err != nil {
+		return nil, fmt.Errorf("opentelemetry: create histogram %s err: %w", name, err)
+	}
+	s.histograms.Store(name, h)
+	return h, nil
+}
+
+// gauge returns the gauge of name, the callback is registered only once for each name.
+func (s *OTLPSink) gauge(name, desc string) (*otlpGauge, error) {
+	if g, ok := s.gauges.Load(name); ok {
+		return g.(*otlpGauge), nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if g, ok := s.gauges.Load(name); ok {
+		return g.(*otlpGauge), nil
+	}
+	g := newOTLPGauge(s.now)
+	if _, err := s.meter.Float64ObservableGauge(name, metric.WithDescription(desc),
+		metric.WithFloat64Callback(g.callback)); err != nil {
+		return nil, fmt.Errorf("opentelemetry: create gauge %s err: %w", name, err)
+	}
+	s.gauges.Store(name, g)
+	return g, nil
+}
+
+// counterName returns the instrument name and attributes of a counter.
+func (s *OTLPSink) counterName(recName, name string,
+	dimensions []*metrics.Dimension) (string, []attribute.KeyValue) {
+	if !s.compatibleNames {
+		return recordMetricName(recName, name, dimensions), dimensionAttributes(dimensions)
+	}
+	if len(dimensions) == 0 {
+		return "trpc_counter_total", compatibleAttributes(strings.ToValidUTF8(name, ""),
+			prometheus.PolicyType(metrics.PolicySUM), nil)
+	}
+	counterName := prometheus.ConvertMetricName(name)
+	if !strings.HasPrefix(counterName, "trpc_counter_") {
+		counterName = "trpc_counter_" + counterName
+	}
+	if !strings.HasSuffix(counterName, "_total") {
+		counterName += "_total"
+	}
+	return counterName, compatibleAttributes(fmt.Sprintf("%s_%s", recName, name),
+		prometheus.PolicyType(metrics.PolicySUM), dimensions)
+}
+
+// gaugeName returns the instrument name and attributes of a gauge.
+func (s *OTLPSink) gaugeName(recName, name string, policy metrics.Policy,
+	dimensions []*metrics.Dimension) (string, []attribute.KeyValue) {
+	if !s.compatibleNames {
+		return recordMetricName(recName, name, dimensions), dimensionAttributes(dimensions)
+	}
+	if len(dimensions) == 0 {
+		return "trpc_gauge", compatibleAttributes(strings.ToValidUTF8(name, ""),
+			prometheus.PolicyType(policy), nil)
+	}
+	gaugeName := prometheus.ConvertMetricName(name)
+	if !strings.HasPrefix(gaugeName, "trpc_gauge_") {
+		gaugeName = "trpc_gauge_" + gaugeName
+	}
+	return gaugeName, compatibleAttributes(fmt.Sprintf("%s_%s", recName, name),
+		prometheus.PolicyType(policy), dimensions)
+}
+
+// histogramName returns the instrument name and attributes of a histogram,
+// histograms are named by the metric name only, as buckets of the trpc metrics API are.
+func (s *OTLPSink) histogramName(name string, policy metrics.Policy,
+	dimensions []*metrics.Dimension) (string, []attribute.KeyValue) {
+	if !s.compatibleNames {
+		return name, dimensionAttributes(dimensions)
+	}
+	if len(dimensions) == 0 {
+		return compatibleHistogramName(name), compatibleAttributes(strings.ToValidUTF8(name, ""),
+			prometheus.PolicyType(policy), nil)
+	}
+	return compatibleHistogramName(name), dimensionAttributes(dimensions)
+}
+
+func compatibleHistogramName(name string) string {
+	return "trpc_histogram_" + prometheus.ConvertMetricName(name)
+}
+
+func recordMetricName(recName, name string, dimensions []*metrics.Dimension) string {
+	if recName == "" || len(dimensions) == 0 {
+		return name
+	}
+	return recName + "." + name
+}
+
+func dimensionAttributes(dimensions []*metrics.Dimension) []attribute.KeyValue {
+	attrs := make([]attribute.KeyValue, 0, len(dimensions))
+	for _, d := range dimensions {
+		attrs = append(attrs, attribute.String(d.Name, d.Value))
+	}
+	return attrs
+}
+
+func compatibleAttributes(name, typ string, dimensions []*metrics.Dimension) []attribute.KeyValue {
+	return append(dimensionAttributes(dimensions), attribute.String("_name", name), attribute.String("_type", typ))
+}
+
+// OTLPSinkHistogramViews returns views setting the buckets of OTLPSink histograms by the trpc metric name,
+// they should be passed to the MeterProvider, e.g. by opentelemetry.WithMetricViews.
+func OTLPSinkHistogramViews(buckets map[string][]float64, compatibleNames bool) []sdkmetric.View {
+	views := make([]sdkmetric.View, 0, len(buckets))
+	for name, b := range buckets {
+		if compatibleNames {
+			name = compatibleHistogramName(name)
+		}
+		views = append(views, sdkmetric.NewView(sdkmetric.Instrument{
+			Name:  name,
+			Kind:  sdkmetric.InstrumentKindHistogram,
+			Scope: instrumentation.Scope{Name: OTLPSinkMeterName},
+		}, sdkmetric.Stream{
+			Aggregation: aggregation.ExplicitBucketHistogram{Boundaries: b},
+		}))
+	}
+	return views
+}
+
+// otlpGauge keeps the values of gauges between two exports.
+// Values of PolicySET are kept until replaced, and values of PolicyAVG, PolicyMAX and PolicyMIN are
+// aggregated in the same fixed windows as the prometheus Sink, the last completed window is exported.
+type otlpGauge struct {
+	mu       sync.Mutex
+	series   map[attribute.Distinct]*otlpGaugeSeries // PolicySET
+	windowed *prometheus.WindowedValues
+}
+
+type otlpGaugeKey struct {
+	policy metrics.Policy
+	attrs  attribute.Distinct
+}
+
+type otlpGaugeSeries struct {
+	attrs attribute.Set
+	last  float64
+}
+
+func newOTLPGauge(now func() time.Time) *otlpGauge {
+	return &otlpGauge{
+		series:   make(map[attribute.Distinct]*otlpGaugeSeries),
+		windowed: prometheus.NewWindowedValues(prometheus.DefaultGaugeWindow, now),
+	}
+}
+
+func (g *otlpGauge) observe(policy metrics.Policy, attrs attribute.Set, value float64) {
+	if policy != metrics.PolicySET {
+		g.windowed.Observe(otlpGaugeKey{policy: policy, attrs: attrs.Equivalent()}, policy, value,
+			func() interface{} { return attrs })
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	s, ok := g.series[attrs.Equivalent()]
+	if !ok {
+		s = &otlpGaugeSeries{attrs: attrs}
+		g.series[attrs.Equivalent()] = s
+	}
+	s.last = value
+}
+
+func (g *otlpGauge) callback(_ context.Context, o metric.Float64Observer) error {
+	g.mu.Lock()
+	for _, s := range g.series {
+		o.Observe(s.last, metric.WithAttributeSet(s.attrs))
+	}
+	g.mu.Unlock()
+	g.windowed.Range(func(data interface{}, value float64) {
+		attrs := data.(attribute.Set)
+		o.Observe(value, metric.WithAttributeSet(attrs))
+	})
+	return nil
+}
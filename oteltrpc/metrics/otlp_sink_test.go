@@ -0,0 +1,161 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"trpc.group/trpc-go/trpc-go/metrics"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics/prometheus"
+)
+
+func newTestOTLPSink(views []sdkmetric.View, opts ...OTLPSinkOption) (*OTLPSink, sdkmetric.Reader) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader), sdkmetric.WithView(views...))
+	return NewOTLPSink(provider, opts...), reader
+}
+
+// fakeOTLPClock makes the gauge windows of s driven by the returned advance func, it must be called before reporting.
+func fakeOTLPClock(s *OTLPSink) (advance func(time.Duration)) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func collectOTLP(t *testing.T, reader sdkmetric.Reader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	result := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		assert.Equal(t, OTLPSinkMeterName, sm.Scope.Name)
+		for _, m := range sm.Metrics {
+			result[m.Name] = m.Data
+		}
+	}
+	return result
+}
+
+func gaugeValues(t *testing.T, data metricdata.Aggregation, key attribute.Key) map[string]float64 {
+	gauge, ok := data.(metricdata.Gauge[float64])
+	require.True(t, ok, "%T", data)
+	values := make(map[string]float64)
+	for _, dp := range gauge.DataPoints {
+		v, _ := dp.Attributes.Value(key)
+		values[v.AsString()] = dp.Value
+	}
+	return values
+}
+
+func TestOTLPSink_Report(t *testing.T) {
+	s, reader := newTestOTLPSink(OTLPSinkHistogramViews(map[string][]float64{"trpc.hist": {1, 2, 5}}, false))
+	assert.Equal(t, OTLPSinkName, s.Name())
+	advance := fakeOTLPClock(s)
+	dims := []*metrics.Dimension{{Name: "dim", Value: "a"}}
+	for _, rec := range []metrics.Record{
+		metrics.NewSingleDimensionMetrics("trpc.counter", 1, metrics.PolicySUM),
+		metrics.NewSingleDimensionMetrics("trpc.counter", 2, metrics.PolicySUM),
+		metrics.NewSingleDimensionMetrics("trpc.gauge", 5, metrics.PolicySET),
+		metrics.NewSingleDimensionMetrics("trpc.timer", float64(500*time.Millisecond), metrics.PolicyTimer),
+		metrics.NewSingleDimensionMetrics("trpc.hist", 3, metrics.PolicyHistogram),
+		metrics.NewMultiDimensionMetricsX("rec", dims, []*metrics.Metrics{
+			metrics.NewMetrics("max", 3, metrics.PolicyMAX),
+			metrics.NewMetrics("max", 9, metrics.PolicyMAX),
+			metrics.NewMetrics("max", 4, metrics.PolicyMAX),
+		}),
+	} {
+		require.NoError(t, s.Report(rec))
+	}
+	require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.counter", 100, metrics.PolicySUM),
+		prometheus.WithNoSink))
+
+	data := collectOTLP(t, reader)
+	assert.NotContains(t, data, "rec.max", "the current window is not exported")
+	advance(prometheus.DefaultGaugeWindow)
+	data = collectOTLP(t, reader)
+	sum, ok := data["trpc.counter"].(metricdata.Sum[float64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.True(t, sum.IsMonotonic)
+	assert.Equal(t, float64(3), sum.DataPoints[0].Value)
+
+	assert.Equal(t, map[string]float64{"": 5}, gaugeValues(t, data["trpc.gauge"], "dim"))
+	assert.Equal(t, map[string]float64{"a": 9}, gaugeValues(t, data["rec.max"], "dim"))
+
+	timer, ok := data["trpc.timer"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Equal(t, 0.5, timer.DataPoints[0].Sum)
+	hist, ok := data["trpc.hist"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Equal(t, []float64{1, 2, 5}, hist.DataPoints[0].Bounds)
+	assert.Equal(t, []uint64{0, 0, 1, 0}, hist.DataPoints[0].BucketCounts)
+
+	// collections don't reset the window, the last value of PolicySET is kept
+	require.NoError(t, s.Report(metrics.NewMultiDimensionMetricsX("rec", dims, []*metrics.Metrics{
+		metrics.NewMetrics("max", 2, metrics.PolicyMAX),
+	})))
+	data = collectOTLP(t, reader)
+	assert.Equal(t, map[string]float64{"a": 9}, gaugeValues(t, data["rec.max"], "dim"))
+	advance(prometheus.DefaultGaugeWindow)
+	data = collectOTLP(t, reader)
+	assert.Equal(t, map[string]float64{"a": 2}, gaugeValues(t, data["rec.max"], "dim"))
+	assert.Equal(t, map[string]float64{"": 5}, gaugeValues(t, data["trpc.gauge"], "dim"))
+}
+
+func TestOTLPSink_CompatibleNames(t *testing.T) {
+	s, reader := newTestOTLPSink(OTLPSinkHistogramViews(map[string][]float64{"trpc.hist": {1, 2}}, true),
+		WithOTLPSinkCompatibleNames(true))
+	advance := fakeOTLPClock(s)
+	for _, rec := range []metrics.Record{
+		metrics.NewSingleDimensionMetrics("trpc.counter", 1, metrics.PolicySUM),
+		metrics.NewSingleDimensionMetrics("trpc.gauge", 2, metrics.PolicyAVG),
+		metrics.NewSingleDimensionMetrics("trpc.gauge", 4, metrics.PolicyAVG),
+		metrics.NewSingleDimensionMetrics("trpc.hist", 3, metrics.PolicyHistogram),
+		metrics.NewMultiDimensionMetricsX("rec", []*metrics.Dimension{{Name: "dim", Value: "a"}},
+			[]*metrics.Metrics{metrics.NewMetrics("requests", 1, metrics.PolicySUM)}),
+	} {
+		require.NoError(t, s.Report(rec))
+	}
+	advance(prometheus.DefaultGaugeWindow)
+	data := collectOTLP(t, reader)
+
+	sum, ok := data["trpc_counter_total"].(metricdata.Sum[float64])
+	require.True(t, ok)
+	attrs := sum.DataPoints[0].Attributes
+	name, _ := attrs.Value("_name")
+	typ, _ := attrs.Value("_type")
+	assert.Equal(t, "trpc.counter", name.AsString())
+	assert.Equal(t, "counter", typ.AsString())
+
+	assert.Equal(t, map[string]float64{"avg": 3}, gaugeValues(t, data["trpc_gauge"], "_type"))
+
+	hist, ok := data["trpc_histogram_trpc_hist"].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	assert.Equal(t, []float64{1, 2}, hist.DataPoints[0].Bounds)
+
+	sum, ok = data["trpc_counter_requests_total"].(metricdata.Sum[float64])
+	require.True(t, ok)
+	attrs = sum.DataPoints[0].Attributes
+	name, _ = attrs.Value("_name")
+	dim, _ := attrs.Value("dim")
+	assert.Equal(t, "rec_requests", name.AsString())
+	assert.Equal(t, "a", dim.AsString())
+}
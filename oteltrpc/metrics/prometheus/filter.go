@@ -14,6 +14,7 @@
 package prometheus
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"trpc.group/trpc-go/trpc-go/http"
 
 	trpccodes "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/codes"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
@@ -37,6 +39,9 @@ func ServerFilter(options ...ServerFilterOption) filter.ServerFilter {
 	}
 	return func(ctx context.Context, req interface{}, handle filter.ServerHandleFunc) (rsp interface{}, err error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req)
+		}
 		calleeMethod := msg.CalleeMethod()
 		if head := http.Head(ctx); head != nil && head.Request != nil {
 			calleeMethod = fmt.Sprintf("[%s]%s", head.Request.Method, metric.CleanRPCMethod(head.Request.URL.Path))
@@ -45,8 +50,13 @@ func ServerFilter(options ...ServerFilterOption) filter.ServerFilter {
 		r := metric.NewServerReporter("trpc", msg.CallerServiceName(), msg.CallerMethod(),
 			msg.CalleeServiceName(), calleeMethod, metric.WithServerTraceConfig(filterConfig.enableDeferredSample,
 				filterConfig.deferredSampleError, filterConfig.deferredSampleSlowDuration))
+		reqMD := msg.ServerMetaData().Clone()
+		r.SetRequestSize(calcBodySize(req), calcMetaDataSize(reqMD))
 		rsp, err = handle(ctx, req)
+		// response metadata is set by trpc.SetMetaData into the same ServerMetaData
+		r.SetResponseSize(calcBodySize(rsp), calcResponseMetaDataSize(reqMD, msg.ServerMetaData()))
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(ctx, rsp, err)
+		r.SetErrorType(trpccodes.ErrorType(err))
 		r.Handled(ctx, code)
 		return rsp, err
 	}
@@ -60,16 +70,26 @@ func ClientFilter(options ...ClientFilterOption) filter.ClientFilter {
 	}
 	return func(ctx context.Context, req, rsp interface{}, handle filter.ClientHandleFunc) (err error) {
 		msg := trpc.Message(ctx)
-		md := msg.ClientMetaData()
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handle(ctx, req, rsp)
+		}
+		md := msg.ClientMetaData().Clone()
 		monitorRequestSize(req, md)
 
 		r := metric.NewClientReporter("trpc", msg.CallerServiceName(), msg.CallerMethod(),
 			msg.CalleeServiceName(), msg.CalleeMethod(), metric.WithClientTraceConfig(filterConfig.enableDeferredSample,
 				filterConfig.deferredSampleError, filterConfig.deferredSampleSlowDuration))
+		r.SetRequestSize(calcBodySize(req), calcMetaDataSize(md))
 
 		err = handle(ctx, req, rsp)
+		// response metadata is merged into the same ClientMetaData by the codec
+		r.SetResponseSize(calcBodySize(rsp), calcResponseMetaDataSize(md, msg.ClientMetaData()))
+		if addr := msg.RemoteAddr(); addr != nil {
+			r.SetPeer(addr.String())
+		}
 
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(ctx, rsp, err)
+		r.SetErrorType(trpccodes.ErrorType(err))
 		r.Handled(ctx, code)
 		return err
 	}
@@ -125,7 +145,19 @@ func calcMetaDataSize(md codec.MetaData) int {
 	return size
 }
 
-// monitorRequestSize monitor request size
+// calcResponseMetaDataSize calc size of the response metadata, which is stored in the same map as
+// the request metadata reqMD, so only the values added or changed after the request are counted.
+func calcResponseMetaDataSize(reqMD, md codec.MetaData) int {
+	size := 0
+	for k, v := range md {
+		if old, ok := reqMD[k]; !ok || !bytes.Equal(old, v) {
+			size += len(v)
+		}
+	}
+	return size
+}
+
+// monitorRequestSize monitor request size to the deprecated unlabelled histograms
 func monitorRequestSize(req interface{}, md codec.MetaData) {
 	ObserveRequestBodyBytes(calcBodySize(req))
 	ObserveRequestMetaDataBytes(calcMetaDataSize(md))
@@ -16,13 +16,20 @@ package prometheus
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"trpc.group/trpc-go/trpc-go"
+	"trpc.group/trpc-go/trpc-go/codec"
 	pb "trpc.group/trpc-go/trpc-go/testdata/trpc/helloworld"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
 // BenchmarkServerFilter
@@ -96,3 +103,48 @@ func BenchmarkClientFilterWithSpanSampled(b *testing.B) {
 		_ = f(ctx, req, rsp, handle)
 	}
 }
+
+func TestFilter_ResponseMetaDataSize(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer, prometheus.DefaultGatherer = reg, reg
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+	}()
+	require.NoError(t, metric.SetupByConfig(metric.Config{Enabled: true, EnabledPayloadSizeHistogram: true}))
+
+	ctx := trpc.BackgroundContext()
+	msg := trpc.Message(ctx)
+	msg.WithServerMetaData(codec.MetaData{"req": []byte("1234567890"), "both": []byte("12")})
+	_, err := ServerFilter()(ctx, &pb.HelloRequest{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		trpc.SetMetaData(ctx, "rsp", []byte("123"))
+		trpc.SetMetaData(ctx, "both", []byte("1234"))
+		return &pb.HelloReply{}, nil
+	})
+	require.NoError(t, err)
+
+	ctx = trpc.BackgroundContext()
+	msg = trpc.Message(ctx)
+	msg.WithClientMetaData(codec.MetaData{"req": []byte("12345")})
+	require.NoError(t, ClientFilter()(ctx, &pb.HelloRequest{}, &pb.HelloReply{},
+		func(ctx context.Context, req, rsp interface{}) error {
+			// the codec merges the response metadata into ClientMetaData
+			trpc.Message(ctx).ClientMetaData()["rsp"] = []byte("1234567")
+			return nil
+		}))
+
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	got := make(map[string]float64)
+	for _, mf := range mfs {
+		if strings.HasSuffix(mf.GetName(), "_metadata_bytes") && len(mf.GetMetric()) > 0 {
+			got[mf.GetName()] = mf.GetMetric()[0].GetHistogram().GetSampleSum()
+		}
+	}
+	assert.Equal(t, map[string]float64{
+		"rpc_server_request_metadata_bytes":  12,
+		"rpc_server_response_metadata_bytes": 7,
+		"rpc_client_request_metadata_bytes":  5,
+		"rpc_client_response_metadata_bytes": 7,
+	}, got)
+}
@@ -55,6 +55,14 @@ var (
 		Buckets:   []float64{1024, 10240, 102400, 1024_000, 10240_000},
 	})
 
+	downstreamFanOutTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Subsystem: "rpc",
+		Name:      "downstream_fan_out_total",
+		Help:      "Server requests whose calls to a callee exceed the fan-out threshold",
+	}, []string{
+		"service", "method", "callee_service", "callee_method",
+	})
+
 	trpcSDKMetadata = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Name: "opentelemetry_trpc_metadata",
 		Help: "opentelemetry trpc metadata version",
@@ -79,15 +87,26 @@ func ObserveExportLogsBytes(s int) {
 }
 
 // ObserveRequestBodyBytes observe request body bytes
+//
+// Deprecated: it is unlabelled and only kept for compatibility, use the labelled
+// rpc_client_request_body_bytes enabled by metrics.enabled_payload_size_histogram instead.
 func ObserveRequestBodyBytes(s int) {
 	requestBodyBytes.Observe(float64(s))
 }
 
 // ObserveRequestMetaDataBytes observe request metadata bytes
+//
+// Deprecated: it is unlabelled and only kept for compatibility, use the labelled
+// rpc_client_request_metadata_bytes enabled by metrics.enabled_payload_size_histogram instead.
 func ObserveRequestMetaDataBytes(s int) {
 	requestMetaDataBodyBytes.Observe(float64(s))
 }
 
+// IncrDownstreamFanOut report a server request whose calls to a callee exceed the fan-out threshold
+func IncrDownstreamFanOut(service, method, calleeService, calleeMethod string) {
+	downstreamFanOutTotal.WithLabelValues(service, method, calleeService, calleeMethod).Inc()
+}
+
 // MonitorTRPCSDKMeta monitor trpc sdk meta
 func MonitorTRPCSDKMeta() {
 	trpcSDKMetadata.WithLabelValues(trpc.Version()).Set(1)
@@ -22,6 +22,7 @@ import (
 	"trpc.group/trpc-go/trpc-go/server"
 
 	trpccodes "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/codes"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
@@ -32,6 +33,9 @@ func StreamServerFilter() server.StreamFilter {
 	return func(ss server.Stream, info *server.StreamServerInfo, handler server.StreamHandler) error {
 		ctx := ss.Context()
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return handler(ss)
+		}
 		sr := metric.NewServerReporter(
 			systemName,
 			msg.CallerServiceName(),
@@ -42,6 +46,7 @@ func StreamServerFilter() server.StreamFilter {
 			metric.WithServerRPCType(serverStreamType(info)))
 		err := handler(&monitoredServerStream{Stream: ss, monitor: sr})
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(ctx, nil, err)
+		sr.SetErrorType(trpccodes.ErrorType(err))
 		sr.Handled(ctx, code)
 		return err
 	}
@@ -55,6 +60,9 @@ func StreamClientFilter() client.StreamFilter {
 		streamer client.Streamer,
 	) (client.ClientStream, error) {
 		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalMetrics, msg.CalleeServiceName(), msg.CalleeMethod()) {
+			return streamer(ctx, desc)
+		}
 		cr := metric.NewClientReporter(
 			systemName,
 			msg.CallerServiceName(),
@@ -66,10 +74,14 @@ func StreamClientFilter() client.StreamFilter {
 		)
 
 		cs, err := streamer(ctx, desc)
+		if addr := msg.RemoteAddr(); addr != nil {
+			cr.SetPeer(addr.String())
+		}
 		if err != nil { // err == io.EOF if close stream normally
 			var code = "0"
 			if err != io.EOF {
 				code, _ = trpccodes.GetDefaultGetCodeFunc()(ctx, nil, err)
+				cr.SetErrorType(trpccodes.ErrorType(err))
 			}
 			cr.Handled(ctx, code)
 			return nil, err
@@ -120,6 +132,7 @@ func (s *monitoredClientStream) SendMsg(m interface{}) error {
 	timer.ObserveDuration()
 	if err == nil {
 		s.monitor.SentMessage()
+		s.monitor.SentMessageSize(calcBodySize(m))
 	}
 	return err
 }
@@ -133,10 +146,12 @@ func (s *monitoredClientStream) RecvMsg(m interface{}) error {
 	switch err {
 	case nil:
 		s.monitor.ReceivedMessage()
+		s.monitor.ReceivedMessageSize(calcBodySize(m))
 	case io.EOF:
 		s.monitor.Handled(s.Context(), "0")
 	default:
 		code, _ := trpccodes.GetDefaultGetCodeFunc()(s.Context(), m, err)
+		s.monitor.SetErrorType(trpccodes.ErrorType(err))
 		s.monitor.Handled(s.Context(), code)
 	}
 	return err
@@ -153,6 +168,7 @@ func (s *monitoredServerStream) SendMsg(m interface{}) error {
 		return err
 	}
 	s.monitor.SentMessage()
+	s.monitor.SentMessageSize(calcBodySize(m))
 	return nil
 }
 
@@ -162,5 +178,6 @@ func (s *monitoredServerStream) RecvMsg(m interface{}) error {
 		return err
 	}
 	s.monitor.ReceivedMessage()
+	s.monitor.ReceivedMessageSize(calcBodySize(m))
 	return nil
 }
@@ -26,14 +26,33 @@ import (
 	"trpc.group/trpc-go/trpc-go/log"
 
 	"trpc.group/trpc-go/trpc-opentelemetry/api"
+	"trpc.group/trpc-go/trpc-opentelemetry/config/codes"
 	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
 // Setup .
 func Setup(tenantID string, etcdEndpoints []string, opts ...metric.SetupOption) {
-	initSink()
-	admin.HandleFunc("/metrics", metric.LimitMetricsHandler().ServeHTTP)
+	setupCfg := metric.DefaultConfig()
+	for _, opt := range opts {
+		opt(setupCfg)
+	}
+	if metric.SinkEnabled(setupCfg.Sinks, metric.SinkPrometheus) {
+		initSink(WithSinkHistogramOptions(metric.WithHistogramNative(setupCfg.NativeHistogram)),
+			WithSinkHistogramBuckets(setupCfg.SinkHistogramBuckets))
+	}
+	if err := setupCfg.AdminAuth.Validate(); err != nil {
+		log.Errorf("opentelemetry: invalid admin auth config: %v", err)
+		return
+	}
+	for path, h := range map[string]http.Handler{
+		"/metrics":        metric.LimitMetricsHandler(),
+		"/debug/peers":    metric.PeerStatsHandler(),
+		"/debug/codes":    codes.ExplainHandler(),
+		"/debug/registry": metric.RegistrationHandler(),
+	} {
+		admin.HandleFunc(path, setupCfg.AdminAuth.Handler(h, oteladmin.IsMutating).ServeHTTP)
+	}
 	if tenantID == "" {
 		tenantID = "default"
 	}
@@ -41,19 +60,17 @@ func Setup(tenantID string, etcdEndpoints []string, opts ...metric.SetupOption)
 	log.Infof("opentelemetry: setup prometheus metrics server at http://%s/metrics", addr)
 	if needListen {
 		// admin port is not served, start http server
-		cfg := metric.DefaultConfig()
-		for _, opt := range opts {
-			opt(cfg)
-		}
 		srv, err := oteladmin.NewServer(
 			oteladmin.WithAddr(addr),
 			oteladmin.WithEnablePprof(true),
 			oteladmin.WithEnablePrometheus(true),
 			oteladmin.WithEnableHotSwitch(true),
-			oteladmin.WithEnableZPage(cfg.EnabledZPage),
+			oteladmin.WithEnableZPage(setupCfg.EnabledZPage),
+			oteladmin.WithAuth(setupCfg.AdminAuth),
 		)
 		if err != nil {
 			log.Errorf("failed to new admin server: %v", err)
+			return
 		}
 		go func() {
 			log.Infof("opentelemetry: start admin server because trpc admin is not served, addr: %s", addr)
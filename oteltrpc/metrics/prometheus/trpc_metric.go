@@ -31,10 +31,11 @@ import (
 	"trpc.group/trpc-go/trpc-go/metrics"
 
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/consts"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
-func initSink() {
-	metrics.RegisterMetricsSink(NewSink(prometheus.DefaultRegisterer))
+func initSink(opts ...SinkOption) {
+	metrics.RegisterMetricsSink(NewSink(prometheus.DefaultRegisterer, opts...))
 }
 
 // Sink implements metrics.Sink interface, convert trpc metrics API to Prometheus format.
@@ -78,13 +79,46 @@ func initSink() {
 // report case 2 mapping to metric_name1{_name="record_name2_metric_name1", dim_1="val_11", dim_2="val_11"}
 // 2. Implement a special composeMetricVec type, wrap the collectorVec with these two labels inconsistent.
 // When Prometheus stores metrics, label value is empty and there is no label are equivalent.
+// PolicyAVG, PolicyMAX and PolicyMIN are aggregated in fixed windows of one minute and exported as gauges
+// like PolicySET with `_type` avg/max/min, the value is the aggregation of the last completed window.
+// PolicyMID is exported as a summary trpc_summary{_name="name", _type="mid", quantile="0.5"}.
 type Sink struct {
-	counter    *prometheus.CounterVec
-	gauge      *prometheus.GaugeVec
-	counters   *composeMetricVec
-	gauges     *composeMetricVec
-	histograms sync.Map
-	registerer prometheus.Registerer
+	counter      *prometheus.CounterVec
+	gauge        *prometheus.GaugeVec
+	summary      prometheus.ObserverVec
+	counters     *composeMetricVec
+	gauges       *composeMetricVec
+	summaries    *composeMetricVec
+	windowed     *windowedGauges
+	windowedDesc *prometheus.Desc
+	histograms   sync.Map
+	registerer   prometheus.Registerer
+
+	histogramOptions []metric.HistogramOption
+	histogramBuckets map[string][]float64
+}
+
+// SinkOption Sink option
+type SinkOption func(*Sink)
+
+// WithSinkHistogramOptions set options for histograms created by Sink, such as metric.WithHistogramNative
+func WithSinkHistogramOptions(opts ...metric.HistogramOption) SinkOption {
+	return func(s *Sink) {
+		s.histogramOptions = append(s.histogramOptions, opts...)
+	}
+}
+
+// WithSinkHistogramBuckets set buckets of histograms created by Sink by the trpc metric name,
+// they take precedence over the buckets of metrics.NewHistogram.
+func WithSinkHistogramBuckets(buckets map[string][]float64) SinkOption {
+	return func(s *Sink) {
+		if s.histogramBuckets == nil {
+			s.histogramBuckets = make(map[string][]float64, len(buckets))
+		}
+		for name, b := range buckets {
+			s.histogramBuckets[name] = b
+		}
+	}
 }
 
 var (
@@ -95,12 +129,27 @@ var (
 // WithNoSink option to skip reporting to this sink
 var WithNoSink metrics.Option = func(opts *metrics.Options) {}
 
+// HasNoSink returns if WithNoSink is in opts.
+func HasNoSink(opts ...metrics.Option) bool {
+	for _, o := range opts {
+		if *((*uintptr)(unsafe.Pointer(&o))) == *((*uintptr)(unsafe.Pointer(&WithNoSink))) {
+			return true
+		}
+	}
+	return false
+}
+
 // NewSink create a new Sink
-func NewSink(registerer prometheus.Registerer) *Sink {
+func NewSink(registerer prometheus.Registerer, opts ...SinkOption) *Sink {
 	s := &Sink{
 		registerer: registerer,
 		counters:   &composeMetricVec{},
 		gauges:     &composeMetricVec{},
+		summaries:  &composeMetricVec{},
+		windowed:   newWindowedGauges(DefaultGaugeWindow),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
 	s.counter = prometheus.NewCounterVec(prometheus.CounterOpts{
 		Name: defaultCounterName,
@@ -114,10 +163,22 @@ func NewSink(registerer prometheus.Registerer) *Sink {
 	},
 		[]string{"_name", "_type"},
 	).MustCurryWith(prometheus.Labels{"_type": "gauge"})
+	s.summary = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+		Name:       defaultSummaryName,
+		Help:       "trpc metrics summary",
+		Objectives: defaultSummaryObjectives,
+		MaxAge:     defaultSummaryMaxAge,
+	},
+		[]string{"_name", "_type"},
+	).MustCurryWith(prometheus.Labels{"_type": PolicyType(metrics.PolicyMID)})
+	s.windowedDesc = prometheus.NewDesc(defaultGaugeName, "trpc metrics gauge", []string{"_name", "_type"}, nil)
 	_ = s.registerer.Register(s.counter)
 	_ = s.registerer.Register(s.gauge)
+	_ = s.registerer.Register(s.summary)
 	_ = s.registerer.Register(s.counters)
 	_ = s.registerer.Register(s.gauges)
+	_ = s.registerer.Register(s.summaries)
+	_ = s.registerer.Register(s.windowed)
 	return s
 }
 
@@ -128,10 +189,8 @@ func (*Sink) Name() string {
 
 // Report implements metrics.Sink, which will be called synchronously on each metrics operation.
 func (s *Sink) Report(rec metrics.Record, opts ...metrics.Option) error {
-	for _, o := range opts {
-		if *((*uintptr)(unsafe.Pointer(&o))) == *((*uintptr)(unsafe.Pointer(&WithNoSink))) {
-			return nil
-		}
+	if HasNoSink(opts...) {
+		return nil
 	}
 
 	var labels []string
@@ -151,8 +210,10 @@ func (s *Sink) Report(rec metrics.Record, opts ...metrics.Option) error {
 			s.observeHistogram(m.Name(), time.Duration(int64(m.Value())).Seconds(), labels, values)
 		case metrics.PolicyHistogram:
 			s.observeHistogram(m.Name(), m.Value(), labels, values)
-		case metrics.PolicyAVG, metrics.PolicyMAX, metrics.PolicyMIN, metrics.PolicyMID:
-			s.setGauge(rec.Name, m.Name(), m.Value(), labels, values)
+		case metrics.PolicyAVG, metrics.PolicyMAX, metrics.PolicyMIN:
+			s.aggregateGauge(rec.Name, m.Name(), m.Policy(), m.Value(), labels, values)
+		case metrics.PolicyMID:
+			s.observeSummary(rec.Name, m.Name(), m.Value(), labels, values)
 		default:
 			s.setGauge(rec.Name, m.Name(), m.Value(), labels, values)
 		}
@@ -245,7 +306,7 @@ func getGaugeName(name string) string {
 func (s *Sink) observeHistogram(name string, value float64, labels []string, values []string) {
 	histogramLabels := []string{"_name", "_type"}
 	labelsValues := []string{strings.ToValidUTF8(name, ""),
-		"histogram"} // fixed dimension for non-multi-dimension report
+		PolicyType(metrics.PolicyHistogram)} // fixed dimension for non-multi-dimension report
 	if len(labels) != 0 && len(labels) == len(values) { // use user-defined dimension
 		histogramLabels = labels
 		labelsValues = values
@@ -254,20 +315,24 @@ func (s *Sink) observeHistogram(name string, value float64, labels []string, val
 	var ok bool
 	if m, ok = s.histograms.Load(name); !ok {
 		var buckets []float64
-		if h, ok2 := metrics.GetHistogram(name); ok2 {
+		if b, ok2 := s.histogramBuckets[name]; ok2 {
+			buckets = b
+		} else if h, ok2 := metrics.GetHistogram(name); ok2 {
 			for _, b := range h.GetBuckets() {
 				buckets = append(buckets, b.ValueUpperBound)
 			}
 		} else {
 			buckets = prometheus.DefBuckets
 		}
-		m = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		histogramOpts := prometheus.HistogramOpts{
 			Name:    "trpc_histogram_" + s.convertMetricName(name),
 			Help:    strings.ToValidUTF8(name, ""),
 			Buckets: buckets,
-		},
-			histogramLabels,
-		)
+		}
+		for _, opt := range s.histogramOptions {
+			opt(&histogramOpts)
+		}
+		m = prometheus.NewHistogramVec(histogramOpts, histogramLabels)
 		if m, ok = s.histograms.LoadOrStore(name, m); !ok {
 			s.register(m, name)
 		}
@@ -277,6 +342,32 @@ func (s *Sink) observeHistogram(name string, value float64, labels []string, val
 
 // convertMetricName convert metric name to pinyin if not valid, use _ or hex to replace special char
 func (s *Sink) convertMetricName(origin string) string {
+	return ConvertMetricName(origin)
+}
+
+// PolicyType returns the `_type` label value of the trpc metrics policy p as Sink does.
+func PolicyType(p metrics.Policy) string {
+	switch p {
+	case metrics.PolicySUM:
+		return "counter"
+	case metrics.PolicyAVG:
+		return "avg"
+	case metrics.PolicyMAX:
+		return "max"
+	case metrics.PolicyMIN:
+		return "min"
+	case metrics.PolicyMID:
+		return "mid"
+	case metrics.PolicyTimer, metrics.PolicyHistogram:
+		return "histogram"
+	default:
+		return "gauge"
+	}
+}
+
+// ConvertMetricName converts a trpc metric name to a valid Prometheus metric name as Sink does,
+// invalid chars are converted to pinyin, _ or hex.
+func ConvertMetricName(origin string) string {
 	if model.LabelName(origin).IsValid() {
 		return origin
 	}
@@ -21,7 +21,9 @@ import (
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"trpc.group/trpc-go/trpc-go/metrics"
 
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
@@ -114,6 +116,21 @@ func TestSink_ReportHistogram(t *testing.T) {
 		assert.Equal(t, uint64(1),
 			data[0].GetMetric()[0].GetHistogram().GetBucket()[2].GetCumulativeCount()) // label: value
 	})
+	t.Run("native histogram", func(t *testing.T) {
+		nativeCfg := metric.DefaultNativeHistogramConfig()
+		nativeCfg.Enabled = true
+		nativeCfg.Schema = 2
+		s := NewSink(prometheus.NewRegistry(), WithSinkHistogramOptions(metric.WithHistogramNative(nativeCfg)))
+		metrics.RegisterMetricsSink(s)
+		metrics.AddSample("trpc.NativeHistogram", metrics.NewValueBounds(0, 1, 2), 1)
+		data, err := s.registerer.(prometheus.Gatherer).Gather()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(data))
+		h := data[0].GetMetric()[0].GetHistogram()
+		assert.Equal(t, int32(2), h.GetSchema())
+		assert.NotEmpty(t, h.GetBucket()) // classic buckets are kept
+		assert.Equal(t, uint64(1), h.GetSampleCount())
+	})
 }
 
 func TestSink_MultiDimensionReport(t *testing.T) {
@@ -403,6 +420,141 @@ func TestSink_SkipReport(t *testing.T) {
 // BenchmarkMetricCounter_New-12    	  141633	      8068 ns/op	    1933 B/op	      39 allocs/op
 // 2021-09-10
 // BenchmarkMetricCounter_New-12    	  652230	      2284 ns/op	     719 B/op	      16 allocs/op
+func gatherSink(t *testing.T, s *Sink) map[string]*dto.MetricFamily {
+	data, err := s.registerer.(prometheus.Gatherer).Gather()
+	require.NoError(t, err)
+	families := make(map[string]*dto.MetricFamily)
+	for _, mf := range data {
+		families[mf.GetName()] = mf
+	}
+	return families
+}
+
+func metricLabels(m *dto.Metric) map[string]string {
+	labels := make(map[string]string)
+	for _, l := range m.GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	return labels
+}
+
+// fakeWindowClock makes the windows of s driven by the returned advance func.
+func fakeWindowClock(s *Sink) (advance func(time.Duration)) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.windowed.values = NewWindowedValues(DefaultGaugeWindow, func() time.Time { return now })
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func gatherGauges(t *testing.T, s *Sink, name string) map[string]float64 {
+	got := make(map[string]float64)
+	for _, m := range gatherSink(t, s)[name].GetMetric() {
+		labels := metricLabels(m)
+		got[labels["_type"]+"/"+labels["dim"]] = m.GetGauge().GetValue()
+	}
+	return got
+}
+
+func TestSink_ReportWindowedGauge(t *testing.T) {
+	s := NewSink(prometheus.NewRegistry())
+	advance := fakeWindowClock(s)
+	for _, v := range []float64{3, 1, 8} {
+		for _, p := range []metrics.Policy{metrics.PolicyAVG, metrics.PolicyMAX, metrics.PolicyMIN, metrics.PolicySET} {
+			require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.window", v, p)))
+		}
+	}
+	assert.Equal(t, map[string]float64{"gauge/": 8}, gatherGauges(t, s, defaultGaugeName),
+		"the current window is not exported")
+
+	advance(DefaultGaugeWindow)
+	require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.window", 2, metrics.PolicyMAX)))
+	families := gatherSink(t, s)
+	require.Contains(t, families, defaultGaugeName)
+	for _, m := range families[defaultGaugeName].GetMetric() {
+		assert.Equal(t, "trpc.window", metricLabels(m)["_name"])
+	}
+	want := map[string]float64{"avg/": 4, "max/": 8, "min/": 1, "gauge/": 8}
+	assert.Equal(t, want, gatherGauges(t, s, defaultGaugeName))
+	assert.Equal(t, want, gatherGauges(t, s, defaultGaugeName), "collections don't reset the window")
+
+	advance(DefaultGaugeWindow)
+	assert.Equal(t, map[string]float64{"max/": 2, "gauge/": 8}, gatherGauges(t, s, defaultGaugeName),
+		"empty windows are not exported")
+
+	advance(2 * DefaultGaugeWindow)
+	assert.Equal(t, map[string]float64{"gauge/": 8}, gatherGauges(t, s, defaultGaugeName))
+}
+
+func TestSink_MultiDimensionReportWindowedGauge(t *testing.T) {
+	s := NewSink(prometheus.NewRegistry())
+	advance := fakeWindowClock(s)
+	report := func(value string, v float64, p metrics.Policy) {
+		require.NoError(t, s.Report(metrics.NewMultiDimensionMetricsX("rec",
+			[]*metrics.Dimension{{Name: "dim", Value: value}},
+			[]*metrics.Metrics{metrics.NewMetrics("latency", v, p)})))
+	}
+	report("a", 10, metrics.PolicyMAX)
+	report("a", 30, metrics.PolicyMAX)
+	report("b", 20, metrics.PolicyMAX)
+	report("a", 10, metrics.PolicyAVG)
+	report("a", 20, metrics.PolicyAVG)
+
+	advance(DefaultGaugeWindow + time.Second)
+	families := gatherSink(t, s)
+	require.Contains(t, families, "trpc_gauge_latency")
+	for _, m := range families["trpc_gauge_latency"].GetMetric() {
+		assert.Equal(t, "rec_latency", metricLabels(m)["_name"])
+	}
+	assert.Equal(t, map[string]float64{"max/a": 30, "max/b": 20, "avg/a": 15},
+		gatherGauges(t, s, "trpc_gauge_latency"))
+	advance(DefaultGaugeWindow)
+	assert.NotContains(t, gatherSink(t, s), "trpc_gauge_latency")
+}
+
+func TestSink_ReportMID(t *testing.T) {
+	s := NewSink(prometheus.NewRegistry())
+	for i := 1; i <= 101; i++ {
+		require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.mid", float64(i), metrics.PolicyMID)))
+		require.NoError(t, s.Report(metrics.NewMultiDimensionMetricsX("rec",
+			[]*metrics.Dimension{{Name: "dim", Value: "a"}},
+			[]*metrics.Metrics{metrics.NewMetrics("mid", float64(i), metrics.PolicyMID)})))
+	}
+	families := gatherSink(t, s)
+	for _, name := range []string{defaultSummaryName, "trpc_summary_mid"} {
+		require.Contains(t, families, name)
+		mf := families[name]
+		assert.Equal(t, dto.MetricType_SUMMARY, mf.GetType())
+		require.Len(t, mf.GetMetric(), 1)
+		assert.Equal(t, "mid", metricLabels(mf.GetMetric()[0])["_type"])
+		summary := mf.GetMetric()[0].GetSummary()
+		assert.Equal(t, uint64(101), summary.GetSampleCount())
+		assert.Equal(t, float64(101*102/2), summary.GetSampleSum())
+		quantiles := make(map[float64]float64)
+		for _, q := range summary.GetQuantile() {
+			quantiles[q.GetQuantile()] = q.GetValue()
+		}
+		assert.InDelta(t, 51, quantiles[0.5], 5, name) // the median within the 0.05 rank error
+		assert.InDelta(t, 91, quantiles[0.9], 1, name)
+	}
+	assert.Equal(t, "rec_mid", metricLabels(families["trpc_summary_mid"].GetMetric()[0])["_name"])
+}
+
+func TestSink_HistogramBuckets(t *testing.T) {
+	s := NewSink(prometheus.NewRegistry(), WithSinkHistogramBuckets(map[string][]float64{
+		"trpc.custom_buckets": {1, 2, 5},
+	}))
+	require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.custom_buckets", 3, metrics.PolicyHistogram)))
+	require.NoError(t, s.Report(metrics.NewSingleDimensionMetrics("trpc.default_buckets", 3, metrics.PolicyHistogram)))
+	families := gatherSink(t, s)
+
+	var bounds []float64
+	for _, b := range families["trpc_histogram_trpc_custom_buckets"].GetMetric()[0].GetHistogram().GetBucket() {
+		bounds = append(bounds, b.GetUpperBound())
+	}
+	assert.Equal(t, []float64{1, 2, 5}, bounds)
+	assert.Len(t, families["trpc_histogram_trpc_default_buckets"].GetMetric()[0].GetHistogram().GetBucket(),
+		len(prometheus.DefBuckets))
+}
+
 func BenchmarkMetricCounter_New(b *testing.B) {
 	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
@@ -496,3 +648,18 @@ func BenchmarkMetricReport_WithoutSink(b *testing.B) {
 		_ = s.Report(record, WithNoSink)
 	}
 }
+
+func TestPolicyType(t *testing.T) {
+	for p, want := range map[metrics.Policy]string{
+		metrics.PolicySUM:       "counter",
+		metrics.PolicySET:       "gauge",
+		metrics.PolicyAVG:       "avg",
+		metrics.PolicyMAX:       "max",
+		metrics.PolicyMIN:       "min",
+		metrics.PolicyMID:       "mid",
+		metrics.PolicyTimer:     "histogram",
+		metrics.PolicyHistogram: "histogram",
+	} {
+		assert.Equal(t, want, PolicyType(p))
+	}
+}
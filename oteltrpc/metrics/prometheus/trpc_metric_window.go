@@ -0,0 +1,235 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package prometheus
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+
+	"trpc.group/trpc-go/trpc-go/metrics"
+)
+
+const (
+	defaultSummaryName = "trpc_summary"
+	// defaultSummaryMaxAge the window of quantiles of PolicyMID
+	defaultSummaryMaxAge = time.Minute
+)
+
+// DefaultGaugeWindow the window of PolicyAVG, PolicyMAX and PolicyMIN
+const DefaultGaugeWindow = time.Minute
+
+// defaultSummaryObjectives quantiles of PolicyMID, the median and the tail latencies
+var defaultSummaryObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+// WindowedValues aggregates values of PolicyAVG, PolicyMAX and PolicyMIN in fixed windows.
+// Range reads the average, maximum or minimum of the last completed window, so all the readers,
+// e.g. scraping, remote-write and OTLP export, see the same values within a window.
+type WindowedValues struct {
+	mu      sync.Mutex
+	window  time.Duration
+	now     func() time.Time
+	start   time.Time // start of the current window
+	current map[interface{}]*windowedSeries
+	last    map[interface{}]*windowedSeries // the last completed window
+}
+
+type windowedSeries struct {
+	data   interface{}
+	policy metrics.Policy
+	sum    float64
+	count  uint64
+	min    float64
+	max    float64
+}
+
+// NewWindowedValues creates WindowedValues of fixed windows of window, now is the clock of the windows.
+func NewWindowedValues(window time.Duration, now func() time.Time) *WindowedValues {
+	return &WindowedValues{
+		window:  window,
+		now:     now,
+		start:   now().Truncate(window),
+		current: make(map[interface{}]*windowedSeries),
+		last:    make(map[interface{}]*windowedSeries),
+	}
+}
+
+// rotate completes the current window if it is over, must be called with mu held.
+func (w *WindowedValues) rotate() {
+	elapsed := w.now().Sub(w.start)
+	if elapsed < w.window {
+		return
+	}
+	if elapsed < 2*w.window {
+		w.last = w.current
+	} else {
+		// no report in the last completed window
+		w.last = make(map[interface{}]*windowedSeries)
+	}
+	w.current = make(map[interface{}]*windowedSeries, len(w.last))
+	w.start = w.start.Add(elapsed.Truncate(w.window))
+}
+
+// Observe adds value to the current window of the series key, which must be comparable.
+// newData is only called for new series, the data is passed to Range with the aggregated value.
+func (w *WindowedValues) Observe(key interface{}, policy metrics.Policy, value float64, newData func() interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.rotate()
+	s, ok := w.current[key]
+	if !ok {
+		s = &windowedSeries{data: newData(), policy: policy, min: value, max: value}
+		w.current[key] = s
+	}
+	s.sum += value
+	s.count++
+	if value < s.min {
+		s.min = value
+	}
+	if value > s.max {
+		s.max = value
+	}
+}
+
+// Range calls f with the data and the aggregated value of each series of the last completed window,
+// series without any report in it are skipped.
+func (w *WindowedValues) Range(f func(data interface{}, value float64)) {
+	w.mu.Lock()
+	w.rotate()
+	series := w.last // a completed window is not modified any more
+	w.mu.Unlock()
+	for _, s := range series {
+		f(s.data, s.value())
+	}
+}
+
+func (s *windowedSeries) value() float64 {
+	switch s.policy {
+	case metrics.PolicyMAX:
+		return s.max
+	case metrics.PolicyMIN:
+		return s.min
+	default:
+		return s.sum / float64(s.count)
+	}
+}
+
+// windowedGauges exports WindowedValues as gauges.
+type windowedGauges struct {
+	values *WindowedValues
+}
+
+type windowedGauge struct {
+	desc   *prometheus.Desc
+	values []string
+}
+
+func newWindowedGauges(window time.Duration) *windowedGauges {
+	return &windowedGauges{values: NewWindowedValues(window, time.Now)}
+}
+
+// observe adds value to the current window of the series, newDesc is only called for new series.
+func (w *windowedGauges) observe(key string, policy metrics.Policy, value float64,
+	newDesc func() *prometheus.Desc, values []string) {
+	w.values.Observe(key, policy, value, func() interface{} {
+		return windowedGauge{desc: newDesc(), values: values}
+	})
+}
+
+// Describe implements prometheus.Collector, descriptors are dynamic so nothing is described,
+// which makes it an unchecked collector.
+func (w *windowedGauges) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (w *windowedGauges) Collect(ch chan<- prometheus.Metric) {
+	w.values.Range(func(data interface{}, value float64) {
+		g := data.(windowedGauge)
+		m, err := prometheus.NewConstMetric(g.desc, prometheus.GaugeValue, value, g.values...)
+		if err != nil {
+			ch <- prometheus.NewInvalidMetric(g.desc, err)
+			return
+		}
+		ch <- m
+	})
+}
+
+// aggregateGauge reports the value of PolicyAVG, PolicyMAX and PolicyMIN to the current window of the series.
+// The series is named like the gauges of PolicySET, and `_type` is the policy: avg, max or min.
+func (s *Sink) aggregateGauge(recName string, name string, policy metrics.Policy, value float64,
+	labels []string, values []string) {
+	typ := PolicyType(policy)
+	// fast path single-dimension report
+	if len(labels) == 0 {
+		name = strings.ToValidUTF8(name, "")
+		s.windowed.observe(seriesKey(typ, name), policy, value, func() *prometheus.Desc {
+			return s.windowedDesc
+		}, []string{name, typ})
+		return
+	}
+	// multi-dimension report
+	parts := make([]string, 0, 3+len(labels)+len(values))
+	parts = append(append(append(parts, typ, recName, name), labels...), values...)
+	key := seriesKey(parts...)
+	s.windowed.observe(key, policy, value, func() *prometheus.Desc {
+		return prometheus.NewDesc(getGaugeName(s.convertMetricName(name)), name, labels, prometheus.Labels{
+			"_name": fmt.Sprintf("%s_%s", recName, name),
+			"_type": typ,
+		})
+	}, values)
+}
+
+// observeSummary reports the value of PolicyMID to a summary of quantiles over the last minute,
+// the median is the quantile 0.5.
+func (s *Sink) observeSummary(recName string, name string, value float64, labels []string, values []string) {
+	// fast path single-dimension report
+	if len(labels) == 0 {
+		s.summary.WithLabelValues(strings.ToValidUTF8(name, "")).Observe(value)
+		return
+	}
+	// multi-dimension report
+	var c interface{}
+	var ok bool
+	h := labelsHash(name, labels)
+	if c, ok = s.summaries.Load(h); !ok {
+		c = prometheus.NewSummaryVec(prometheus.SummaryOpts{
+			Name: getSummaryName(s.convertMetricName(name)),
+			Help: name,
+			ConstLabels: map[string]string{
+				"_name": fmt.Sprintf("%s_%s", recName, name),
+				"_type": PolicyType(metrics.PolicyMID),
+			},
+			Objectives: defaultSummaryObjectives,
+			MaxAge:     defaultSummaryMaxAge,
+		},
+			labels,
+		)
+		c, _ = s.summaries.LoadOrStore(h, c)
+	}
+	c.(*prometheus.SummaryVec).WithLabelValues(values...).Observe(value)
+}
+
+func getSummaryName(name string) string {
+	if !strings.HasPrefix(name, "trpc_summary_") {
+		name = "trpc_summary_" + name
+	}
+	return name
+}
+
+func seriesKey(parts ...string) string {
+	return strings.Join(parts, string([]byte{model.SeparatorByte}))
+}
@@ -17,11 +17,14 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
 	"runtime"
 	"strings"
+	"sync"
 
 	v1proto "github.com/golang/protobuf/proto"
 	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
@@ -32,6 +35,7 @@ import (
 	"trpc.group/trpc-go/trpc-go/admin"
 	"trpc.group/trpc-go/trpc-go/codec"
 	"trpc.group/trpc-go/trpc-go/filter"
+	trpcmetrics "trpc.group/trpc-go/trpc-go/metrics"
 	"trpc.group/trpc-go/trpc-go/plugin"
 
 	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
@@ -41,8 +45,14 @@ import (
 	trpccodes "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/codes"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/consts"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/logs"
+	oteltrpcmetrics "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics/prometheus"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/traces"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/profiling"
+	pkgruntime "trpc.group/trpc-go/trpc-opentelemetry/pkg/runtime"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/tracestore"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/zpage"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/remote"
@@ -120,6 +130,12 @@ func (f factory) Setup(name string, configDec plugin.Decoder) error {
 	if err != nil {
 		return err
 	}
+	if err := cfg.Metrics.AdminAuth.Validate(); err != nil {
+		return fmt.Errorf("invalid metrics.admin_auth: %w", err)
+	}
+	if cfg.AutoTune.Enabled() {
+		startAutoTune(cfg.AutoTune)
+	}
 	ecosystemtrace.DefaultGetCalleeMethodInfo = getCalleeMethodInfoFunc()
 	if DefaultSampler == nil {
 		DefaultSampler = ecosystemtrace.NewSampler(
@@ -147,8 +163,16 @@ func (f factory) Setup(name string, configDec plugin.Decoder) error {
 		isHTTPEnabled = true
 	}
 	serviceName := trpc.GlobalConfig().Server.App + "." + trpc.GlobalConfig().Server.Server
+	otlpSinkEnabled := cfg.Metrics.Enabled && metric.SinkEnabled(cfg.Metrics.Sinks, metric.SinkOTLP)
 	if cfg.Traces.EnableZPage {
-		admin.HandleFunc("/debug/tracez", zpage.GetZPageHandlerFunc())
+		adminHandle(cfg, "/debug/tracez", http.HandlerFunc(zpage.GetZPageHandlerFunc()))
+	}
+	adminHandle(cfg, "/debug/pprof/labels", profiling.CPUProfileHandler())
+	traces.InitWithAuth(cfg.Metrics.AdminAuth)
+	if cfg.Traces.RecentTraces.Enabled {
+		adminHandle(cfg, "/debug/traces", tracestore.ListHandler())
+		adminHandle(cfg, "/debug/traces/trace", tracestore.TraceHandler())
+		adminHandle(cfg, "/debug/traces/otlp", tracestore.OTLPHandler())
 	}
 	err = opentelemetry.Setup(cfg.Addr,
 		opentelemetry.WithHeader(cfg.Headers),
@@ -166,26 +190,48 @@ func (f factory) Setup(name string, configDec plugin.Decoder) error {
 		opentelemetry.WithBatchSpanProcessorOption(buildBatchSpanProcessorOptions(cfg.Traces.ExportConfig)...),
 		opentelemetry.WithIDGenerator(opentelemetry.GlobalIDGenerator()),
 		opentelemetry.WithZPageSpanProcessor(cfg.Traces.EnableZPage),
+		opentelemetry.WithRecentTraces(cfg.Traces.RecentTraces),
+		opentelemetry.WithPprofLabels(cfg.Traces.PprofLabels),
+		opentelemetry.WithMetricEnabled(otlpSinkEnabled),
+		opentelemetry.WithMetricViews(oteltrpcmetrics.OTLPSinkHistogramViews(cfg.Metrics.SinkHistogramBuckets,
+			cfg.Metrics.OTLPSink.CompatibleNames)...),
 	)
 	if err != nil {
 		return err
 	}
-	configurator := remote.NewRemoteConfigurator(cfg.Sampler.SamplerServerAddr, 0,
-		cfg.TenantID, trpc.GlobalConfig().Server.App, trpc.GlobalConfig().Server.Server,
-	)
+	if otlpSinkEnabled {
+		trpcmetrics.RegisterMetricsSink(oteltrpcmetrics.NewOTLPSink(otel.GetMeterProvider(),
+			oteltrpcmetrics.WithOTLPSinkCompatibleNames(cfg.Metrics.OTLPSink.CompatibleNames)))
+	}
+	configurator := newConfigurator(cfg)
+	adminHandle(cfg, "/debug/remote", remote.StatusHandler(configurator))
+	registerTelemetryStatus(cfg, configurator)
 	if cfg.Metrics.Enabled {
 		prometheus.Setup(cfg.TenantID, cfg.Metrics.RegistryEndpoints,
 			metric.WithEnabledZPage(cfg.Traces.EnableZPage),
 			metric.WithConfigurator(configurator),
 			metric.WithClientHistogramBuckets(cfg.Metrics.ClientHistogramBuckets),
 			metric.WithServerHistogramBuckets(cfg.Metrics.ServerHistogramBuckets),
+			metric.WithClientHistogramBucketsOverrides(cfg.Metrics.ClientHistogramBucketsOverrides),
+			metric.WithServerHistogramBucketsOverrides(cfg.Metrics.ServerHistogramBucketsOverrides),
+			metric.WithNativeHistogram(cfg.Metrics.NativeHistogram),
+			metric.WithSinks(cfg.Metrics.Sinks),
+			metric.WithSinkHistogramBuckets(cfg.Metrics.SinkHistogramBuckets),
+			metric.WithEnabledPayloadSizeHistogram(cfg.Metrics.EnabledPayloadSizeHistogram),
+			metric.WithPeerMetrics(cfg.Metrics.PeerMetrics),
+			metric.WithRuntimeMetrics(cfg.Metrics.RuntimeMetrics),
 			metric.WithTLSCert(cfg.Metrics.TLSCert),
 			metric.WithEnabled(true),
 			metric.WithEnabledRegister(cfg.Metrics.EnabledRegister),
+			metric.WithRegistry(cfg.Metrics.Registry),
+			metric.WithFileSD(cfg.Metrics.FileSD),
+			metric.WithHTTPSD(cfg.Metrics.HTTPSD),
 			metric.WithMetricsPrometheusPush(cfg.Metrics.PrometheusPush),
+			metric.WithRemoteWrite(cfg.Metrics.RemoteWrite),
 		)
 	}
 	setupCodes(cfg, configurator)
+	configurator.RegisterConfigApplyFunc(oteladmin.ApplyRemoteSwitches)
 	setupFilters(cfg)
 	return nil
 }
@@ -243,6 +289,57 @@ func buildBatchSpanProcessorOptions(c config.TraceExporterOption) (options []eco
 	return
 }
 
+// registerTelemetryStatus registers the plugin sections and the /debug/telemetry page on the admin.
+func registerTelemetryStatus(cfg *config.Config, configurator remote.Configurator) {
+	telemetrystatus.Register(telemetrystatus.SectionConfig, func() interface{} {
+		m, err := cfg.Masked()
+		if err != nil {
+			return map[string]string{"error": err.Error()}
+		}
+		return m
+	})
+	if r, ok := configurator.(remote.StatusReporter); ok {
+		telemetrystatus.Register(telemetrystatus.SectionRemote, func() interface{} {
+			s := r.Status()
+			s.History = nil
+			return s
+		})
+	}
+	telemetrystatus.Register(telemetrystatus.SectionCarriers, func() interface{} { return traces.RegisteredCarriers() })
+	adminHandle(cfg, "/debug/telemetry", telemetrystatus.Handler())
+}
+
+// adminHandle registers h to the trpc admin behind the admin auth of cfg
+func adminHandle(cfg *config.Config, pattern string, h http.Handler) {
+	admin.HandleFunc(pattern, cfg.Metrics.AdminAuth.Handler(h, oteladmin.IsMutating).ServeHTTP)
+}
+
+// newConfigurator creates the remote configurator selected by cfg.Remote.Type.
+func newConfigurator(cfg *config.Config) remote.Configurator {
+	app, server := trpc.GlobalConfig().Server.App, trpc.GlobalConfig().Server.Server
+	opts := []remote.Option{
+		remote.WithHistorySize(cfg.Remote.HistorySize),
+		remote.WithReportStatus(cfg.Remote.ReportStatus),
+		remote.WithInstance(trpc.GlobalConfig().Global.LocalIP),
+	}
+	switch cfg.Remote.Type {
+	case remote.TypeFile:
+		return remote.NewFileConfigurator(cfg.Remote.Path, cfg.Remote.SyncInterval, opts...)
+	case remote.TypeHTTP:
+		return remote.NewHTTPConfigurator(cfg.Remote.Addr, cfg.Remote.SyncInterval, cfg.TenantID, app, server, opts...)
+	case remote.TypeNone:
+		return remote.NewRemoteConfigurator("", 0, cfg.TenantID, app, server, opts...)
+	case "", remote.TypeGRPC:
+	default:
+		log.Printf("opentelemetry: unknown remote type %q, use %s", cfg.Remote.Type, remote.TypeGRPC)
+	}
+	addr := cfg.Remote.Addr
+	if addr == "" {
+		addr = cfg.Sampler.SamplerServerAddr
+	}
+	return remote.NewRemoteConfigurator(addr, cfg.Remote.SyncInterval, cfg.TenantID, app, server, opts...)
+}
+
 func setupCodes(cfg *config.Config, configurator remote.Configurator) {
 	var c []*codes.Code
 	c = append(c, cfg.Codes...)
@@ -271,6 +368,7 @@ func setupFilters(cfg *config.Config) {
 		o.TraceLogOption = cfg.Logs.TraceLogOption
 		o.DisableTraceBody = cfg.Traces.DisableTraceBody
 		o.DisableParentSampling = cfg.Traces.DisableParentSampling
+		o.FanOutThreshold = cfg.Traces.FanOutThreshold
 	}
 	logFilterOpts := func(o *logs.FilterOptions) {
 		o.DisableRecovery = cfg.Logs.DisableRecovery
@@ -303,6 +401,37 @@ func setupFilters(cfg *config.Config) {
 	*cf = clientFilter
 }
 
+var (
+	autoTuneMu   sync.Mutex
+	stopAutoTune func()
+	// registerShutdownHook is replaced in tests
+	registerShutdownHook = opentelemetry.RegisterShutdownHook
+)
+
+// startAutoTune stops the previous tuner, starts a new one and stops it on opentelemetry.Shutdown.
+// The shutdown hook is registered only by the first call after a Shutdown.
+func startAutoTune(cfg pkgruntime.AutoTuneConfig) {
+	autoTuneMu.Lock()
+	defer autoTuneMu.Unlock()
+	hooked := stopAutoTune != nil
+	if hooked {
+		stopAutoTune()
+	}
+	stopAutoTune = pkgruntime.AutoTune(cfg)
+	if hooked {
+		return
+	}
+	registerShutdownHook(func(context.Context) error {
+		autoTuneMu.Lock()
+		defer autoTuneMu.Unlock()
+		if stopAutoTune != nil {
+			stopAutoTune()
+			stopAutoTune = nil
+		}
+		return nil
+	})
+}
+
 // ParseConfig can be set by the user to override the config
 var ParseConfig = func(configDec plugin.Decoder) (*config.Config, error) {
 	cfg := &config.Config{}
@@ -14,6 +14,7 @@
 package oteltrpc
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -23,8 +24,10 @@ import (
 	"trpc.group/trpc-go/trpc-go/plugin"
 	pb "trpc.group/trpc-go/trpc-go/testdata/trpc/helloworld"
 
+	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
 	"trpc.group/trpc-go/trpc-opentelemetry/config"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/consts"
+	pkgruntime "trpc.group/trpc-go/trpc-opentelemetry/pkg/runtime"
 	ecosystemtrace "trpc.group/trpc-go/trpc-opentelemetry/sdk/trace"
 )
 
@@ -179,3 +182,35 @@ func Test_getSpecialFractions(t *testing.T) {
 		})
 	}
 }
+
+func Test_startAutoTune(t *testing.T) {
+	hooks := 0
+	registerShutdownHook = func(f func(context.Context) error) {
+		hooks++
+		opentelemetry.RegisterShutdownHook(f)
+	}
+	defer func() { registerShutdownHook = opentelemetry.RegisterShutdownHook }()
+
+	cfg := pkgruntime.AutoTuneConfig{MemoryLimit: true, Interval: time.Hour}
+	startAutoTune(cfg)
+	startAutoTune(cfg)
+	assert.Equal(t, 1, hooks)
+	autoTuneMu.Lock()
+	assert.NotNil(t, stopAutoTune)
+	autoTuneMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = opentelemetry.Shutdown(ctx) // the error of the providers is irrelevant here
+	autoTuneMu.Lock()
+	assert.Nil(t, stopAutoTune)
+	autoTuneMu.Unlock()
+
+	// the hook is registered again after Shutdown
+	startAutoTune(cfg)
+	assert.Equal(t, 2, hooks)
+	_ = opentelemetry.Shutdown(ctx)
+	autoTuneMu.Lock()
+	assert.Nil(t, stopAutoTune)
+	autoTuneMu.Unlock()
+}
@@ -39,6 +39,12 @@ const (
 	CallerMethodKey  = attribute.Key("trpc.caller_method")
 	CalleeServiceKey = attribute.Key("trpc.callee_service")
 	CalleeMethodKey  = attribute.Key("trpc.callee_method")
+
+	DownstreamCallsKey       = attribute.Key("trpc.downstream.calls")
+	DownstreamCalleeCallsKey = attribute.Key("trpc.downstream.callee_calls")
+	DownstreamTotalMsKey     = attribute.Key("trpc.downstream.total_ms")
+	DownstreamMaxMsKey       = attribute.Key("trpc.downstream.max_ms")
+	SelfMsKey                = attribute.Key("trpc.self_ms")
 )
 
 var once sync.Once
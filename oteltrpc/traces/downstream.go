@@ -0,0 +1,165 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/metrics/prometheus"
+	trpcsemconv "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/semconv"
+)
+
+const (
+	// maxCalleeCalls number of the callees in the trpc.downstream.callee_calls attribute, the most called first,
+	// each is "<callee service>/<callee method>=<calls>" like the name of the client span
+	maxCalleeCalls = 20
+	// maxDownstreamIntervals number of the downstream calls counted by the self time
+	maxDownstreamIntervals = 1000
+	// fanOutEventName event of the server span whose calls to a callee exceed the fan-out threshold
+	fanOutEventName = "trpc.downstream.fan_out"
+)
+
+type downstreamStatsKey struct{}
+
+type callee struct {
+	service, method string
+}
+
+type interval struct {
+	start, end time.Time
+}
+
+// downstreamStats the client calls made while handling a server request
+type downstreamStats struct {
+	mu        sync.Mutex
+	calls     map[callee]int
+	total     time.Duration
+	max       time.Duration
+	intervals []interval
+}
+
+// withDownstreamStats returns a context collecting the client calls made with it
+func withDownstreamStats(ctx context.Context) (context.Context, *downstreamStats) {
+	s := &downstreamStats{}
+	return context.WithValue(ctx, downstreamStatsKey{}, s), s
+}
+
+// recordDownstream records a client call to the downstream stats of ctx if any
+func recordDownstream(ctx context.Context, service, method string, start, end time.Time) {
+	s, ok := ctx.Value(downstreamStatsKey{}).(*downstreamStats)
+	if !ok {
+		return
+	}
+	d := end.Sub(start)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.calls == nil {
+		s.calls = make(map[callee]int)
+	}
+	s.calls[callee{service: service, method: method}]++
+	s.total += d
+	if d > s.max {
+		s.max = d
+	}
+	if len(s.intervals) < maxDownstreamIntervals {
+		s.intervals = append(s.intervals, interval{start: start, end: end})
+	}
+}
+
+// end attaches the summary of the downstream calls to the server span of service/method
+// which started at start, the callees called more than fanOutThreshold times are flagged if it is positive.
+func (s *downstreamStats) end(span trace.Span, service, method string, start, end time.Time, fanOutThreshold int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.calls) == 0 {
+		return
+	}
+	type calleeCalls struct {
+		callee
+		n int
+	}
+	var calls int
+	sorted := make([]calleeCalls, 0, len(s.calls))
+	for c, n := range s.calls {
+		calls += n
+		sorted = append(sorted, calleeCalls{callee: c, n: n})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].n != sorted[j].n {
+			return sorted[i].n > sorted[j].n
+		}
+		if sorted[i].service != sorted[j].service {
+			return sorted[i].service < sorted[j].service
+		}
+		return sorted[i].method < sorted[j].method
+	})
+	calleeCallsAttr := make([]string, 0, maxCalleeCalls)
+	for i, c := range sorted {
+		if i < maxCalleeCalls {
+			calleeCallsAttr = append(calleeCallsAttr, fmt.Sprintf("%s/%s=%d", c.service, strings.TrimLeft(c.method, "/"), c.n))
+		}
+		if fanOutThreshold > 0 && c.n > fanOutThreshold {
+			span.AddEvent(fanOutEventName, trace.WithAttributes(
+				trpcsemconv.CalleeServiceKey.String(c.service),
+				trpcsemconv.CalleeMethodKey.String(c.method),
+				attribute.Int("calls", c.n),
+				attribute.Int("threshold", fanOutThreshold),
+			))
+			prometheus.IncrDownstreamFanOut(service, method, c.service, c.method)
+		}
+	}
+	span.SetAttributes(
+		trpcsemconv.DownstreamCallsKey.Int(calls),
+		trpcsemconv.DownstreamCalleeCallsKey.StringSlice(calleeCallsAttr),
+		trpcsemconv.DownstreamTotalMsKey.Float64(milliseconds(s.total)),
+		trpcsemconv.DownstreamMaxMsKey.Float64(milliseconds(s.max)),
+		trpcsemconv.SelfMsKey.Float64(milliseconds(selfTime(start, end, s.intervals))),
+	)
+}
+
+// selfTime the time of [start, end] not covered by the downstream calls, the parallel calls are counted once.
+func selfTime(start, end time.Time, intervals []interval) time.Duration {
+	sorted := append([]interval(nil), intervals...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].start.Before(sorted[j].start) })
+	var covered time.Duration
+	cursor := start
+	for _, iv := range sorted {
+		if iv.start.After(cursor) {
+			cursor = iv.start
+		}
+		if iv.end.After(end) {
+			iv.end = end
+		}
+		if iv.end.After(cursor) {
+			covered += iv.end.Sub(cursor)
+			cursor = iv.end
+		}
+	}
+	if self := end.Sub(start) - covered; self > 0 {
+		return self
+	}
+	return 0
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
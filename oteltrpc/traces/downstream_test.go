@@ -0,0 +1,82 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package traces
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	trpcsemconv "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/semconv"
+)
+
+func TestSelfTime(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	// [10, 30] and [20, 40] overlap, [90, 120] is clipped to the end
+	intervals := []interval{{at(20), at(40)}, {at(10), at(30)}, {at(90), at(120)}}
+	assert.Equal(t, 60*time.Millisecond, selfTime(start, at(100), intervals))
+	assert.Equal(t, 100*time.Millisecond, selfTime(start, at(100), nil))
+	assert.Equal(t, time.Duration(0), selfTime(start, at(10), []interval{{start, at(10)}}))
+}
+
+func TestDownstreamStats(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := tp.Tracer("test").Start(context.Background(), "server")
+
+	ctx, stats := withDownstreamStats(context.Background())
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		recordDownstream(ctx, "trpc.app.user.User", "/GetUser", start, start.Add(10*time.Millisecond))
+	}
+	recordDownstream(ctx, "trpc.app.order.Order", "/List", start, start.Add(40*time.Millisecond))
+	recordDownstream(context.Background(), "trpc.app.order.Order", "/List", start, start.Add(time.Second))
+	stats.end(span, "trpc.app.server.Greeter", "/SayHello", start, start.Add(50*time.Millisecond), 2)
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	attrs := attribute.NewSet(spans[0].Attributes()...)
+	calls, _ := attrs.Value(trpcsemconv.DownstreamCallsKey)
+	assert.Equal(t, int64(4), calls.AsInt64())
+	calleeCalls, _ := attrs.Value(trpcsemconv.DownstreamCalleeCallsKey)
+	assert.Equal(t, []string{"trpc.app.user.User/GetUser=3", "trpc.app.order.Order/List=1"}, calleeCalls.AsStringSlice())
+	total, _ := attrs.Value(trpcsemconv.DownstreamTotalMsKey)
+	assert.Equal(t, float64(70), total.AsFloat64())
+	maxMs, _ := attrs.Value(trpcsemconv.DownstreamMaxMsKey)
+	assert.Equal(t, float64(40), maxMs.AsFloat64())
+	self, _ := attrs.Value(trpcsemconv.SelfMsKey)
+	assert.Equal(t, float64(10), self.AsFloat64())
+
+	events := spans[0].Events()
+	assert.Len(t, events, 1)
+	assert.Equal(t, fanOutEventName, events[0].Name)
+	assert.Contains(t, events[0].Attributes, attribute.Int("calls", 3))
+}
+
+func TestDownstreamStatsNoCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	_, span := tp.Tracer("test").Start(context.Background(), "server")
+	_, stats := withDownstreamStats(context.Background())
+	stats.end(span, "trpc.app.server.Greeter", "/SayHello", time.Now(), time.Now(), 1)
+	span.End()
+	assert.Empty(t, recorder.Ended()[0].Attributes())
+}
@@ -16,6 +16,7 @@ package traces
 
 import (
 	"context"
+	"net/http"
 	"strconv"
 	"strings"
 	"sync"
@@ -42,6 +43,8 @@ import (
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/logs"
 	trpcsemconv "trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/semconv"
 	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/profiling"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
 
@@ -52,9 +55,23 @@ var (
 
 // Init trace filter
 func Init() {
-	admin.HandleFunc("/cmds/disabletrace", oteladmin.DisableTrace)
-	admin.HandleFunc("/cmds/enabletrace", oteladmin.EnableTrace)
-	admin.HandleFunc("/cmds/tracestatus", oteladmin.TraceStatus)
+	InitWithAuth(auth.Config{})
+}
+
+// InitWithAuth registers the trace admin commands to the trpc admin behind the admin auth c
+func InitWithAuth(c auth.Config) {
+	for pattern, h := range map[string]http.HandlerFunc{
+		"/cmds/disabletrace":     oteladmin.DisableTrace,
+		"/cmds/enabletrace":      oteladmin.EnableTrace,
+		"/cmds/tracestatus":      oteladmin.TraceStatus,
+		"/cmds/switches":         oteladmin.ListSwitches,
+		"/cmds/switches/disable": oteladmin.DisableSwitch,
+		"/cmds/switches/enable":  oteladmin.EnableSwitch,
+		"/cmds/sampling":         oteladmin.Sampling,
+		"/cmds/sampling/reset":   oteladmin.ResetSampling,
+	} {
+		admin.HandleFunc(pattern, c.Handler(h, oteladmin.IsMutating).ServeHTTP)
+	}
 }
 
 func getDefaultTracer() trace.Tracer {
@@ -74,6 +91,9 @@ type FilterOptions struct {
 	DisableTraceBody bool
 	// DisableParentSampling ignore parent sampling
 	DisableParentSampling bool
+	// FanOutThreshold the server span gets a fan-out event and metric if the calls to a callee exceed it,
+	// disabled if not positive
+	FanOutThreshold int
 }
 
 // FilterOption filter option
@@ -92,12 +112,12 @@ func ServerFilter(opts ...FilterOption) filter.ServerFilter {
 		v(&opt)
 	}
 	return func(ctx context.Context, req interface{}, f filter.ServerHandleFunc) (rsp interface{}, err error) {
-		if oteladmin.TraceDisabled() {
+		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalTrace, msg.CalleeServiceName(), msg.CalleeMethod()) {
 			return f(ctx, req)
 		}
 
 		start := time.Now()
-		msg := trpc.Message(ctx)
 		md := msg.ServerMetaData()
 		if md == nil {
 			md = codec.MetaData{}
@@ -105,6 +125,12 @@ func ServerFilter(opts ...FilterOption) filter.ServerFilter {
 
 		ctx, span := startServerSpan(ctx, req, msg, md, opt)
 		defer span.End()
+		ctx, downstream := withDownstreamStats(ctx)
+		if profiling.Enabled() {
+			var restore func()
+			ctx, restore = profiling.WithSpanLabels(ctx, span.SpanContext(), profiling.LabelRPCMethod, msg.ServerRPCName())
+			defer restore()
+		}
 
 		log.WithContextFields(ctx, "traceID", span.SpanContext().TraceID().String(),
 			"spanID", span.SpanContext().SpanID().String(),
@@ -125,12 +151,14 @@ func ServerFilter(opts ...FilterOption) filter.ServerFilter {
 		}
 		flow := buildFlowLog(msg, trace.SpanKindServer)
 		handleError(code, err1, span, flow)
-		if needToTraceBody(span, opt, err1) {
+		if needToTraceBody(span, opt, err1) && !traceBodyDisabled(msg) {
 			flow.Request.Body = addEvent(ctx, req, otelsemconv.MessageTypeReceived, receivedDeadline, receivedTime)
 			flow.Response.Body = addEvent(ctx, rsp, otelsemconv.MessageTypeSent, sentDeadline, sentTime)
 		}
 
 		span.SetAttributes(DefaultAttributesAfterServerHandle(ctx, rsp)...)
+		downstream.end(span, msg.CalleeServiceName(), metric.CleanRPCMethod(msg.CalleeMethod()),
+			start, time.Now(), opt.FanOutThreshold)
 		flow.Cost = time.Since(start).String()
 		doFlowLog(ctx, flow, opt)
 		return rsp, err
@@ -217,10 +245,16 @@ func needToTraceBody(span trace.Span, opt FilterOptions, err error) bool {
 	return opt.TraceLogMode != config.LogModeDisable || err != nil
 }
 
+// traceBodyDisabled reports whether the body events are disabled by the switches
+func traceBodyDisabled(msg codec.Msg) bool {
+	return oteladmin.Disabled(oteladmin.SignalTraceBody, msg.CalleeServiceName(), msg.CalleeMethod())
+}
+
 func handleError(errCode int, err error, span trace.Span, flow *logs.FlowLog) {
 	code, msg, errType := getErrCode(errCode, err)
 	calleeService, calleeMethod := flow.Target.Name, flow.Target.Method
-	codeType := ecocodes.CodeMapping(strconv.Itoa(code), calleeService, calleeMethod)
+	codeType := ecocodes.CodeMappingWithErrorType(
+		strconv.Itoa(code), trpccodes.ErrorType(err), calleeService, calleeMethod)
 	if codeType.Type != ecocodes.CodeTypeSuccess.String() {
 		span.SetStatus(codes.Error, msg)
 	} else {
@@ -264,12 +298,12 @@ func ClientFilter(opts ...FilterOption) filter.ClientFilter {
 		v(&opt)
 	}
 	return func(ctx context.Context, req interface{}, rsp interface{}, f filter.ClientHandleFunc) error {
-		if oteladmin.TraceDisabled() {
+		msg := trpc.Message(ctx)
+		if oteladmin.Disabled(oteladmin.SignalTrace, msg.CalleeServiceName(), msg.CalleeMethod()) {
 			return f(ctx, req, rsp)
 		}
 
 		start := time.Now()
-		msg := trpc.Message(ctx)
 		md := msg.ClientMetaData()
 		if md == nil {
 			md = codec.MetaData{}
@@ -286,6 +320,7 @@ func ClientFilter(opts ...FilterOption) filter.ClientFilter {
 		err := f(ctx, req, rsp)
 		receivedDeadline := getDeadline(ctx)
 		receivedTime := time.Now()
+		recordDownstream(ctx, msg.CalleeServiceName(), metric.CleanRPCMethod(msg.CalleeMethod()), sentTime, receivedTime)
 		var code int
 		codeStr, err1 := trpccodes.GetDefaultGetCodeFunc()(ctx, rsp, err)
 		if c, e := strconv.Atoi(codeStr); e == nil {
@@ -293,7 +328,7 @@ func ClientFilter(opts ...FilterOption) filter.ClientFilter {
 		}
 		flow := buildFlowLog(msg, trace.SpanKindClient)
 		handleError(code, err1, span, flow)
-		if needToTraceBody(span, opt, err1) {
+		if needToTraceBody(span, opt, err1) && !traceBodyDisabled(msg) {
 			flow.Request.Body = addEvent(ctx, req, otelsemconv.MessageTypeSent, sentDeadline, sentTime)
 			flow.Response.Body = addEvent(ctx, rsp, otelsemconv.MessageTypeReceived, receivedDeadline, receivedTime)
 		}
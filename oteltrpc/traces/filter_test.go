@@ -16,14 +16,20 @@ package traces
 import (
 	"context"
 	"errors"
+	"net"
+	"net/http"
+	"runtime/pprof"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
 
 	"trpc.group/trpc-go/trpc-go"
+	"trpc.group/trpc-go/trpc-go/admin"
 	"trpc.group/trpc-go/trpc-go/codec"
 	"trpc.group/trpc-go/trpc-go/errs"
 	"trpc.group/trpc-go/trpc-go/log"
@@ -32,6 +38,9 @@ import (
 	"trpc.group/trpc-go/trpc-opentelemetry/api"
 	"trpc.group/trpc-go/trpc-opentelemetry/config"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/codes"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/profiling"
 )
 
 // BenchmarkServerFilter
@@ -203,3 +212,73 @@ func TestServerFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestServerFilterSwitch(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	f := ServerFilter()
+	ctx, msg := codec.WithNewMessage(context.Background())
+	msg.WithCalleeServiceName("trpc.app.server.Greeter")
+	msg.WithCalleeMethod("/SayHello")
+	var traced bool
+	handle := func(ctx context.Context, req interface{}) (interface{}, error) {
+		traced = trace.SpanContextFromContext(ctx).IsValid()
+		return &pb.HelloReply{}, nil
+	}
+
+	assert.Nil(t, oteladmin.DisableSignal(oteladmin.SwitchRule{
+		Signal: oteladmin.SignalTrace, Service: "trpc.app.server.Greeter", Method: "/SayHello"}))
+	_, err := f(ctx, &pb.HelloRequest{}, handle)
+	assert.Nil(t, err)
+	assert.False(t, traced)
+
+	oteladmin.EnableSignal(oteladmin.SignalTrace, "trpc.app.server.Greeter", "/SayHello")
+	_, err = f(ctx, &pb.HelloRequest{}, handle)
+	assert.Nil(t, err)
+	assert.True(t, traced)
+}
+
+func TestServerFilterPprofLabels(t *testing.T) {
+	otel.SetTracerProvider(sdktrace.NewTracerProvider())
+	profiling.SetEnabled(true)
+	defer profiling.SetEnabled(false)
+	ctx, msg := codec.WithNewMessage(context.Background())
+	msg.WithServerRPCName("/trpc.app.server.Greeter/SayHello")
+	var method, traceID string
+	_, err := ServerFilter()(ctx, &pb.HelloRequest{}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		method, _ = pprof.Label(ctx, profiling.LabelRPCMethod)
+		traceID, _ = pprof.Label(ctx, profiling.LabelTraceID)
+		return &pb.HelloReply{}, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "/trpc.app.server.Greeter/SayHello", method)
+	assert.NotEmpty(t, traceID)
+}
+
+func TestInitWithAuth(t *testing.T) {
+	InitWithAuth(auth.Config{Users: []auth.User{{Username: "oncall", Password: "pass", Role: auth.RoleMutate}}})
+	defer Init()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	addr := ln.Addr().String()
+	assert.Nil(t, ln.Close())
+	srv := admin.NewServer(admin.WithAddr(addr))
+	go func() { _ = srv.Serve() }()
+	defer func() { _ = srv.Close(nil) }()
+
+	var rsp *http.Response
+	assert.Eventually(t, func() bool {
+		rsp, err = http.Get("http://" + addr + "/cmds/disabletrace")
+		return err == nil
+	}, 3*time.Second, 50*time.Millisecond)
+	defer rsp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, rsp.StatusCode)
+	assert.False(t, oteladmin.TraceDisabled())
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+addr+"/cmds/tracestatus", nil)
+	assert.Nil(t, err)
+	req.SetBasicAuth("oncall", "pass")
+	rsp2, err := http.DefaultClient.Do(req)
+	assert.Nil(t, err)
+	defer rsp2.Body.Close()
+	assert.Equal(t, http.StatusOK, rsp2.StatusCode)
+}
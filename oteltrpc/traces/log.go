@@ -21,6 +21,7 @@ import (
 
 	"trpc.group/trpc-go/trpc-opentelemetry/config"
 	"trpc.group/trpc-go/trpc-opentelemetry/oteltrpc/logs"
+	oteladmin "trpc.group/trpc-go/trpc-opentelemetry/pkg/admin"
 )
 
 // doFlowLog
@@ -29,6 +30,9 @@ func doFlowLog(ctx context.Context, flow *logs.FlowLog, options FilterOptions) {
 		// fast path
 		return
 	}
+	if oteladmin.Disabled(oteladmin.SignalFlowLog, flow.Target.Name, flow.Target.Method) {
+		return
+	}
 	// process rule
 	for _, v := range options.TraceLogOption.Exclude {
 		matchService := v.Service == "" ||
@@ -15,6 +15,9 @@
 package traces
 
 import (
+	"reflect"
+	"runtime"
+
 	"go.opentelemetry.io/otel/propagation"
 
 	"trpc.group/trpc-go/trpc-go/codec"
@@ -33,6 +36,15 @@ func RegisterCarrier(carrier func(md codec.MetaData, msg codec.Msg) propagation.
 	registeredCarriers = append(registeredCarriers, carrier)
 }
 
+// RegisteredCarriers returns the function names of the registered carriers
+func RegisteredCarriers() []string {
+	names := make([]string, 0, len(registeredCarriers))
+	for _, c := range registeredCarriers {
+		names = append(names, runtime.FuncForPC(reflect.ValueOf(c).Pointer()).Name())
+	}
+	return names
+}
+
 // GetTextMapCarriers ...
 func GetTextMapCarriers(md codec.MetaData, msg codec.Msg) propagation.TextMapCarrier {
 	var textMapCarriers compositeTextMapCarrier
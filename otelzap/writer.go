@@ -31,6 +31,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 	sdklog "trpc.group/trpc-go/trpc-opentelemetry/sdk/log"
 )
 
@@ -458,6 +459,11 @@ func (bp *BatchWriteSyncer) drainQueue() {
 }
 
 // shouldProcessInBatch determines whether to export in batches
+// QueueStatus returns the depth of the queue and the totals of the logs.
+func (bp *BatchWriteSyncer) QueueStatus() telemetrystatus.QueueStatus {
+	return telemetrystatus.NewQueueStatus(len(bp.queue), cap(bp.queue), "logs")
+}
+
 func (bp *BatchWriteSyncer) shouldProcessInBatch() bool {
 	if len(bp.batch) == bp.opt.MaxExportBatchSize {
 		batchByCountCounter.Inc()
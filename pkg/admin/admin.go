@@ -18,6 +18,10 @@ import (
 	"net/http"
 	"net/http/pprof"
 
+	"trpc.group/trpc-go/trpc-opentelemetry/config/codes"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/profiling"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/tracestore"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/zpage"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric"
 )
@@ -40,17 +44,48 @@ func NewServer(opts ...Option) (*Server, error) {
 		return nil, err
 	}
 
+	srv := &http.Server{Addr: o.addr, Handler: o.auth.Handler(newRouter(o), IsMutating)}
+	if o.auth.TLS.Enabled() {
+		tlsConfig, err := o.auth.TLS.ServerConfig()
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = tlsConfig
+	}
 	return &Server{
-		srv:  &http.Server{Addr: o.addr, Handler: newRouter(o)},
+		srv:  srv,
 		opts: o,
 	}, nil
 }
 
-// Serve starts a http server and listen to serve
+// Serve starts a http server and listen to serve, over TLS if configured by WithAuth
 func (s *Server) Serve() error {
+	if s.srv.TLSConfig != nil {
+		return s.srv.ListenAndServeTLS("", "")
+	}
 	return s.srv.ListenAndServe()
 }
 
+// mutatingPaths the admin endpoints which change the runtime state
+var mutatingPaths = map[string]bool{
+	"/cmds/disabletrace":     true,
+	"/cmds/enabletrace":      true,
+	"/cmds/switches/disable": true,
+	"/cmds/switches/enable":  true,
+	"/cmds/sampling/reset":   true,
+}
+
+// IsMutating reports whether r changes the runtime state, /cmds/sampling only mutates with a fraction or by POST.
+func IsMutating(r *http.Request) bool {
+	if mutatingPaths[r.URL.Path] {
+		return true
+	}
+	if r.URL.Path == "/cmds/sampling" {
+		return r.Method == http.MethodPost || r.URL.Query().Get("fraction") != ""
+	}
+	return false
+}
+
 // HTTPServer returns http.Server in Server
 func (s *Server) HTTPServer() *http.Server {
 	return s.srv
@@ -60,6 +95,9 @@ func newRouter(o *Options) *http.ServeMux {
 	mux := http.NewServeMux()
 	if o.enablePrometheus {
 		mux.Handle("/metrics", metric.LimitMetricsHandler())
+		mux.Handle("/debug/peers", metric.PeerStatsHandler())
+		mux.Handle("/debug/codes", codes.ExplainHandler())
+		mux.Handle("/debug/registry", metric.RegistrationHandler())
 	}
 	if o.enablePprof {
 		mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -67,17 +105,27 @@ func newRouter(o *Options) *http.ServeMux {
 		mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 		mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
 		mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+		mux.Handle("/debug/pprof/labels", profiling.CPUProfileHandler())
 	}
 	// support hot switch
 	if o.enableHotSwitch {
 		mux.HandleFunc("/cmds/disabletrace", DisableTrace)
 		mux.HandleFunc("/cmds/enabletrace", EnableTrace)
 		mux.HandleFunc("/cmds/tracestatus", TraceStatus)
+		mux.HandleFunc("/cmds/switches", ListSwitches)
+		mux.HandleFunc("/cmds/switches/disable", DisableSwitch)
+		mux.HandleFunc("/cmds/switches/enable", EnableSwitch)
+		mux.HandleFunc("/cmds/sampling", Sampling)
+		mux.HandleFunc("/cmds/sampling/reset", ResetSampling)
 	}
 	// add zPage handler
 	if o.enableZPage {
 		mux.HandleFunc("/debug/tracez", zpage.GetZPageHandlerFunc())
 	}
+	mux.Handle("/debug/telemetry", telemetrystatus.Handler())
+	mux.Handle("/debug/traces", tracestore.ListHandler())
+	mux.Handle("/debug/traces/trace", tracestore.TraceHandler())
+	mux.Handle("/debug/traces/otlp", tracestore.OTLPHandler())
 
 	return mux
 }
@@ -18,11 +18,14 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/prometheus/common/expfmt"
 	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
 )
 
 func TestServer_Serve(t *testing.T) {
@@ -62,3 +65,39 @@ func TestServer_Serve(t *testing.T) {
 	require.NoError(t, err)
 	require.Greater(t, len(mf), 0)
 }
+
+func TestIsMutating(t *testing.T) {
+	require.True(t, IsMutating(httptest.NewRequest(http.MethodGet, "/cmds/disabletrace", nil)))
+	require.True(t, IsMutating(httptest.NewRequest(http.MethodGet, "/cmds/sampling?fraction=0.5", nil)))
+	require.True(t, IsMutating(httptest.NewRequest(http.MethodPost, "/cmds/sampling", nil)))
+	require.False(t, IsMutating(httptest.NewRequest(http.MethodGet, "/cmds/sampling", nil)))
+	require.False(t, IsMutating(httptest.NewRequest(http.MethodGet, "/cmds/switches", nil)))
+}
+
+func TestServer_Auth(t *testing.T) {
+	srv, err := NewServer(WithAddr("localhost:0"), WithEnableHotSwitch(true), WithAuth(auth.Config{
+		Users:             []auth.User{{Username: "u", Password: "p"}},
+		PostOnlyMutations: true,
+	}))
+	require.NoError(t, err)
+	h := srv.HTTPServer().Handler
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cmds/tracestatus", nil))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/cmds/tracestatus", nil)
+	r.SetBasicAuth("u", "p")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	r = httptest.NewRequest(http.MethodPost, "/cmds/disabletrace", nil)
+	r.SetBasicAuth("u", "p")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusForbidden, w.Code)
+
+	_, err = NewServer(WithAddr("localhost:0"), WithAuth(auth.Config{Users: []auth.User{{Username: "u"}}}))
+	require.Error(t, err)
+}
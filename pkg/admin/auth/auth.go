@@ -0,0 +1,167 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package auth provides TLS, authentication, authorization and path allowlist of the admin endpoints.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Role of a user or token
+type Role string
+
+const (
+	// RoleRead can access the read-only endpoints, e.g. /metrics, /debug/pprof/
+	RoleRead Role = "read"
+	// RoleMutate can access all endpoints, including the mutating ones, e.g. /cmds/disabletrace
+	RoleMutate Role = "mutate"
+)
+
+// Config security config of the admin endpoints, the zero value allows all requests over plain HTTP.
+type Config struct {
+	// TLS serves the standalone admin server over HTTPS
+	TLS TLSConfig `yaml:"tls"`
+	// Users basic auth users
+	Users []User `yaml:"users"`
+	// Tokens bearer tokens
+	Tokens []Token `yaml:"tokens"`
+	// AllowPaths only these paths are served if not empty, a path ending with / matches all paths under it
+	AllowPaths []string `yaml:"allow_paths"`
+	// PostOnlyMutations the mutating endpoints only accept POST
+	PostOnlyMutations bool `yaml:"post_only_mutations"`
+}
+
+// User basic auth user
+type User struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Role default RoleRead
+	Role Role `yaml:"role"`
+}
+
+// Token bearer token
+type Token struct {
+	Token string `yaml:"token"`
+	// Role default RoleRead
+	Role Role `yaml:"role"`
+}
+
+// Validate validates the config
+func (c Config) Validate() error {
+	for _, u := range c.Users {
+		if u.Username == "" || u.Password == "" {
+			return errors.New("username and password of users must not be empty")
+		}
+		if !validRole(u.Role) {
+			return fmt.Errorf("unknown role %q of user %s", u.Role, u.Username)
+		}
+	}
+	for _, t := range c.Tokens {
+		if t.Token == "" {
+			return errors.New("token must not be empty")
+		}
+		if !validRole(t.Role) {
+			return fmt.Errorf("unknown role %q of token", t.Role)
+		}
+	}
+	return c.TLS.validate()
+}
+
+func validRole(r Role) bool {
+	return r == "" || r == RoleRead || r == RoleMutate
+}
+
+// authEnabled reports whether the requests must be authenticated
+func (c Config) authEnabled() bool {
+	return len(c.Users) > 0 || len(c.Tokens) > 0
+}
+
+// Handler checks the path allowlist, the method and the role of the request before h.
+// mutating reports whether the request mutates, which requires RoleMutate and POST if PostOnlyMutations.
+func (c Config) Handler(h http.Handler, mutating func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !c.allowed(r.URL.Path) {
+			http.Error(w, "forbidden path", http.StatusForbidden)
+			return
+		}
+		mutate := mutating != nil && mutating(r)
+		if mutate && c.PostOnlyMutations && r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if c.authEnabled() {
+			role, ok := c.authenticate(r)
+			if !ok {
+				w.Header().Set("WWW-Authenticate", `Basic realm="admin"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if mutate && role != RoleMutate {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (c Config) allowed(path string) bool {
+	if len(c.AllowPaths) == 0 {
+		return true
+	}
+	for _, p := range c.AllowPaths {
+		if p == path || (strings.HasSuffix(p, "/") && strings.HasPrefix(path, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+// authenticate returns the role of the basic auth user or the bearer token of r
+func (c Config) authenticate(r *http.Request) (Role, bool) {
+	if username, password, ok := r.BasicAuth(); ok {
+		for _, u := range c.Users {
+			if equal(u.Username, username) && equal(u.Password, password) {
+				return roleOrRead(u.Role), true
+			}
+		}
+		return "", false
+	}
+	const prefix = "Bearer "
+	if h := r.Header.Get("Authorization"); len(h) > len(prefix) && strings.EqualFold(h[:len(prefix)], prefix) {
+		token := h[len(prefix):]
+		for _, t := range c.Tokens {
+			if equal(t.Token, token) {
+				return roleOrRead(t.Role), true
+			}
+		}
+	}
+	return "", false
+}
+
+func roleOrRead(r Role) Role {
+	if r == "" {
+		return RoleRead
+	}
+	return r
+}
+
+func equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,76 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	require.NoError(t, Config{}.Validate())
+	require.NoError(t, Config{Users: []User{{Username: "u", Password: "p", Role: RoleMutate}}}.Validate())
+	require.Error(t, Config{Users: []User{{Username: "u"}}}.Validate())
+	require.Error(t, Config{Tokens: []Token{{Token: "t", Role: "admin"}}}.Validate())
+	require.Error(t, Config{TLS: TLSConfig{CertFile: "cert.pem"}}.Validate())
+	require.Error(t, Config{TLS: TLSConfig{ClientCAFile: "ca.pem"}}.Validate())
+}
+
+func TestConfig_Handler(t *testing.T) {
+	c := Config{
+		Users:             []User{{Username: "reader", Password: "r"}, {Username: "admin", Password: "a", Role: RoleMutate}},
+		Tokens:            []Token{{Token: "secret", Role: RoleMutate}},
+		AllowPaths:        []string{"/metrics", "/cmds/"},
+		PostOnlyMutations: true,
+	}
+	mutating := func(r *http.Request) bool { return r.URL.Path == "/cmds/disabletrace" }
+	h := c.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), mutating)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		setup  func(r *http.Request)
+		want   int
+	}{
+		{"no credential", http.MethodGet, "/metrics", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"wrong password", http.MethodGet, "/metrics", func(r *http.Request) { r.SetBasicAuth("reader", "x") }, http.StatusUnauthorized},
+		{"reader", http.MethodGet, "/metrics", func(r *http.Request) { r.SetBasicAuth("reader", "r") }, http.StatusOK},
+		{"path not allowed", http.MethodGet, "/debug/pprof/", func(r *http.Request) { r.SetBasicAuth("admin", "a") }, http.StatusForbidden},
+		{"reader mutate", http.MethodPost, "/cmds/disabletrace", func(r *http.Request) { r.SetBasicAuth("reader", "r") }, http.StatusForbidden},
+		{"admin mutate", http.MethodPost, "/cmds/disabletrace", func(r *http.Request) { r.SetBasicAuth("admin", "a") }, http.StatusOK},
+		{"mutate by get", http.MethodGet, "/cmds/disabletrace", func(r *http.Request) { r.SetBasicAuth("admin", "a") }, http.StatusMethodNotAllowed},
+		{"bearer token", http.MethodPost, "/cmds/disabletrace", func(r *http.Request) { r.Header.Set("Authorization", "Bearer secret") }, http.StatusOK},
+		{"wrong token", http.MethodGet, "/metrics", func(r *http.Request) { r.Header.Set("Authorization", "Bearer x") }, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			tt.setup(r)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			require.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
+func TestConfig_HandlerNoAuth(t *testing.T) {
+	h := Config{}.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/cmds/disabletrace", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+}
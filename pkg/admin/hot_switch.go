@@ -14,10 +14,11 @@
 package admin
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"sync/atomic"
+	"time"
 )
 
 const (
@@ -27,37 +28,80 @@ const (
 	TraceFilterOff int32 = 1
 )
 
-var traceStatus int32 // trace filter：0-on，1-off
-
-// TraceDisabled ...
+// TraceDisabled reports whether the trace filter is disabled for all services, see Disabled for the rules
+// of a service or method.
 func TraceDisabled() bool {
-	return atomic.LoadInt32(&traceStatus) == TraceFilterOff
+	return Disabled(SignalTrace, "", "")
 }
 
 // DisableTrace ...
 func DisableTrace(w http.ResponseWriter, _ *http.Request) {
-	atomic.StoreInt32(&traceStatus, TraceFilterOff)
+	_ = DisableSignal(SwitchRule{Signal: SignalTrace})
 	log.Print("opentelemetry: close trace filter")
 	response(w, "disable trace filter success")
 }
 
 // EnableTrace ...
 func EnableTrace(w http.ResponseWriter, _ *http.Request) {
-	atomic.StoreInt32(&traceStatus, TraceFilterOn)
+	EnableSignal(SignalTrace, "", "")
 	log.Print("opentelemetry: open trace filter")
 	response(w, "enable trace filter success")
 }
 
 // TraceStatus ...
 func TraceStatus(w http.ResponseWriter, _ *http.Request) {
-	status := atomic.LoadInt32(&traceStatus)
-	if status == TraceFilterOff {
+	if TraceDisabled() {
 		response(w, "opentelemetry: trace filter is closed")
 	} else {
 		response(w, "opentelemetry: trace filter is opening")
 	}
 }
 
+// ListSwitches lists the switch rules in JSON
+func ListSwitches(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(Switches())
+}
+
+// DisableSwitch disables the signal of the query for the service and method of the query,
+// the rule expires after the ttl of the query (e.g. 10m) if set.
+func DisableSwitch(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	q := r.Form
+	rule := SwitchRule{Signal: Signal(q.Get("signal")), Service: q.Get("service"), Method: q.Get("method")}
+	if ttl := q.Get("ttl"); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			responseError(w, fmt.Sprintf("invalid ttl %q", ttl))
+			return
+		}
+		rule.ExpireAt = time.Now().Add(d)
+	}
+	if err := DisableSignal(rule); err != nil {
+		responseError(w, err.Error())
+		return
+	}
+	response(w, fmt.Sprintf("disable %s success", rule.Signal))
+}
+
+// EnableSwitch removes the rules of the signal, service and method of the query
+func EnableSwitch(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	q := r.Form
+	n := EnableSignal(Signal(q.Get("signal")), q.Get("service"), q.Get("method"))
+	response(w, fmt.Sprintf("enable %s success, %d rules removed", q.Get("signal"), n))
+}
+
+func responseError(w http.ResponseWriter, message string) {
+	w.WriteHeader(http.StatusBadRequest)
+	_, err := w.Write([]byte(fmt.Sprintf("{\"code\":1, \"message\": %q}", message)))
+	if err != nil {
+		log.Printf("write http response err: %v", err)
+	}
+}
+
 func response(w http.ResponseWriter, message string) {
 	_, err := w.Write([]byte(fmt.Sprintf("{\"code\":0, \"message\": %q}", message)))
 	if err != nil {
@@ -15,6 +15,8 @@ package admin
 
 import (
 	"errors"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
 )
 
 // Option is function for applying an option the admin server
@@ -27,13 +29,14 @@ type Options struct {
 	enablePprof      bool
 	enableHotSwitch  bool
 	enableZPage      bool
+	auth             auth.Config
 }
 
 func (o Options) validate() error {
 	if len(o.addr) == 0 {
 		return errors.New("must pass valid addr")
 	}
-	return nil
+	return o.auth.Validate()
 }
 
 // WithAddr set server listen addr
@@ -71,6 +74,13 @@ func WithEnableZPage(enable bool) Option {
 	}
 }
 
+// WithAuth set TLS, authentication, path allowlist and POST-only mutations of the admin server
+func WithAuth(c auth.Config) Option {
+	return func(o *Options) {
+		o.auth = c
+	}
+}
+
 func defaultOptions() *Options {
 	return new(Options)
 }
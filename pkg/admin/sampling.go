@@ -0,0 +1,89 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/trace"
+)
+
+// defaultBoostDuration duration of the sampling boost if the duration of the query is empty
+const defaultBoostDuration = 10 * time.Minute
+
+// Sampling boosts the sampling of the local Sampler by the query
+// fraction, duration (default 10m), service, method, deferred_error and deferred_slow (e.g. 100ms),
+// it shows the active boost if fraction is absent.
+func Sampling(w http.ResponseWriter, r *http.Request) {
+	_ = r.ParseForm()
+	q := r.Form
+	if q.Get("fraction") == "" {
+		w.Header().Set("Content-Type", "application/json")
+		b, ok := trace.ActiveSamplingBoost()
+		if !ok {
+			_, _ = w.Write([]byte("{}\n"))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(b)
+		return
+	}
+	b, err := parseSamplingBoost(q.Get("fraction"), q.Get("duration"), q.Get("deferred_error"), q.Get("deferred_slow"))
+	if err != nil {
+		responseError(w, err.Error())
+		return
+	}
+	b.Service, b.Method = q.Get("service"), q.Get("method")
+	if err := trace.SetSamplingBoost(b); err != nil {
+		responseError(w, err.Error())
+		return
+	}
+	response(w, fmt.Sprintf("sampling boost %g until %s", b.Fraction, b.ExpireAt.Format(time.RFC3339)))
+}
+
+// ResetSampling clears the sampling boost
+func ResetSampling(w http.ResponseWriter, _ *http.Request) {
+	trace.ClearSamplingBoost()
+	response(w, "sampling boost cleared")
+}
+
+func parseSamplingBoost(fraction, duration, deferredError, deferredSlow string) (trace.SamplingBoost, error) {
+	var b trace.SamplingBoost
+	f, err := strconv.ParseFloat(fraction, 64)
+	if err != nil {
+		return b, fmt.Errorf("invalid fraction %q", fraction)
+	}
+	b.Fraction = f
+	d := defaultBoostDuration
+	if duration != "" {
+		if d, err = time.ParseDuration(duration); err != nil {
+			return b, fmt.Errorf("invalid duration %q", duration)
+		}
+	}
+	b.ExpireAt = time.Now().Add(d)
+	if deferredError != "" {
+		if b.DeferredSampleError, err = strconv.ParseBool(deferredError); err != nil {
+			return b, fmt.Errorf("invalid deferred_error %q", deferredError)
+		}
+	}
+	if deferredSlow != "" {
+		if b.DeferredSampleSlowDuration, err = time.ParseDuration(deferredSlow); err != nil {
+			return b, fmt.Errorf("invalid deferred_slow %q", deferredSlow)
+		}
+	}
+	return b, nil
+}
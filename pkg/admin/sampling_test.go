@@ -0,0 +1,57 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/trace"
+)
+
+func TestSampling(t *testing.T) {
+	defer trace.ClearSamplingBoost()
+	w := httptest.NewRecorder()
+	Sampling(w, httptest.NewRequest(http.MethodGet, "/cmds/sampling", nil))
+	require.Equal(t, "{}\n", w.Body.String())
+
+	w = httptest.NewRecorder()
+	Sampling(w, httptest.NewRequest(http.MethodGet,
+		"/cmds/sampling?fraction=1&duration=5m&method=/SayHello&deferred_slow=100ms", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	b, ok := trace.ActiveSamplingBoost()
+	require.True(t, ok)
+	require.Equal(t, 1.0, b.Fraction)
+	require.Equal(t, "/SayHello", b.Method)
+	require.Equal(t, 100*time.Millisecond, b.DeferredSampleSlowDuration)
+	require.WithinDuration(t, time.Now().Add(5*time.Minute), b.ExpireAt, time.Minute)
+
+	w = httptest.NewRecorder()
+	Sampling(w, httptest.NewRequest(http.MethodGet, "/cmds/sampling", nil))
+	require.Contains(t, w.Body.String(), `"method":"/SayHello"`)
+
+	for _, query := range []string{"fraction=2", "fraction=x", "fraction=1&duration=48h", "fraction=1&deferred_error=x"} {
+		w = httptest.NewRecorder()
+		Sampling(w, httptest.NewRequest(http.MethodGet, "/cmds/sampling?"+query, nil))
+		require.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	ResetSampling(httptest.NewRecorder(), nil)
+	_, ok = trace.ActiveSamplingBoost()
+	require.False(t, ok)
+}
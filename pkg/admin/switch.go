@@ -0,0 +1,248 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package admin
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// Signal telemetry signal which can be disabled by the switches
+type Signal string
+
+const (
+	// SignalTrace the whole trace filter
+	SignalTrace Signal = "trace"
+	// SignalTraceBody the req/rsp body events of the spans
+	SignalTraceBody Signal = "trace_body"
+	// SignalFlowLog the flow logs and the access logs of the rpcs
+	SignalFlowLog Signal = "flow_log"
+	// SignalMetrics the rpc metrics filters
+	SignalMetrics Signal = "metrics"
+	// SignalLogExport the OTLP log export, the rule must not have service or method
+	SignalLogExport Signal = "log_export"
+)
+
+// Sources of the switch rules
+const (
+	SourceAdmin  = "admin"
+	SourceRemote = "remote"
+)
+
+var signals = map[Signal]bool{
+	SignalTrace: true, SignalTraceBody: true, SignalFlowLog: true, SignalMetrics: true, SignalLogExport: true,
+}
+
+// SwitchRule disables Signal for the rpcs of the callee Service and Method, empty or * matches all.
+type SwitchRule struct {
+	Signal  Signal `json:"signal"`
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+	// ExpireAt the rule is removed after it, zero never expires
+	ExpireAt time.Time `json:"expire_at"`
+	// Source SourceAdmin or SourceRemote, the remote rules are replaced by each remote config
+	Source string `json:"source"`
+}
+
+func (r SwitchRule) validate() error {
+	if !signals[r.Signal] {
+		return fmt.Errorf("unknown signal %q", r.Signal)
+	}
+	if r.Signal == SignalLogExport && !(isAny(r.Service) && isAny(r.Method)) {
+		return fmt.Errorf("signal %s does not support service or method", r.Signal)
+	}
+	return nil
+}
+
+func (r *SwitchRule) matches(signal Signal, service, method string) bool {
+	return r.Signal == signal && (isAny(r.Service) || r.Service == service) && (isAny(r.Method) || r.Method == method)
+}
+
+func (r *SwitchRule) sameKey(o SwitchRule) bool {
+	return r.Signal == o.Signal && normalize(r.Service) == normalize(o.Service) &&
+		normalize(r.Method) == normalize(o.Method)
+}
+
+func (r *SwitchRule) expired(now time.Time) bool {
+	return !r.ExpireAt.IsZero() && !now.Before(r.ExpireAt)
+}
+
+func isAny(s string) bool {
+	return s == "" || s == "*"
+}
+
+func normalize(s string) string {
+	if s == "*" {
+		return ""
+	}
+	return s
+}
+
+// switchTable copy-on-write rule table, Disabled reads it without lock
+type switchTable struct {
+	mu    sync.Mutex
+	rules atomic.Value // []SwitchRule
+	// n number of the rules, Disabled returns immediately if it is 0
+	n     int32
+	timer *time.Timer
+}
+
+var switches = &switchTable{}
+
+// Disabled reports whether signal is disabled for the rpc of the callee service and method.
+func Disabled(signal Signal, service, method string) bool {
+	if atomic.LoadInt32(&switches.n) == 0 {
+		return false
+	}
+	rules, _ := switches.rules.Load().([]SwitchRule)
+	var now time.Time
+	for i := range rules {
+		if !rules[i].matches(signal, service, method) {
+			continue
+		}
+		if !rules[i].ExpireAt.IsZero() {
+			if now.IsZero() {
+				now = time.Now()
+			}
+			if rules[i].expired(now) {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Switches returns the rules which have not expired.
+func Switches() []SwitchRule {
+	rules, _ := switches.rules.Load().([]SwitchRule)
+	now := time.Now()
+	active := make([]SwitchRule, 0, len(rules))
+	for _, r := range rules {
+		if !r.expired(now) {
+			active = append(active, r)
+		}
+	}
+	return active
+}
+
+// DisableSignal adds the rule, which replaces the rule of the same signal, service and method.
+// Source is SourceAdmin if empty.
+func DisableSignal(rule SwitchRule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+	if rule.Source == "" {
+		rule.Source = SourceAdmin
+	}
+	switches.update(func(rules []SwitchRule) []SwitchRule {
+		rules = removeRules(rules, func(r *SwitchRule) bool { return r.sameKey(rule) })
+		return append(rules, rule)
+	})
+	if rule.ExpireAt.IsZero() {
+		log.Printf("opentelemetry: disable %s of service %q method %q", rule.Signal, rule.Service, rule.Method)
+	} else {
+		log.Printf("opentelemetry: disable %s of service %q method %q until %s", rule.Signal, rule.Service,
+			rule.Method, rule.ExpireAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// EnableSignal removes the rules of the signal, service and method from all sources, and returns the number of
+// the removed rules.
+func EnableSignal(signal Signal, service, method string) int {
+	key := SwitchRule{Signal: signal, Service: service, Method: method}
+	var removed int
+	switches.update(func(rules []SwitchRule) []SwitchRule {
+		n := len(rules)
+		rules = removeRules(rules, func(r *SwitchRule) bool { return r.sameKey(key) })
+		removed = n - len(rules)
+		return rules
+	})
+	log.Printf("opentelemetry: enable %s of service %q method %q", signal, service, method)
+	return removed
+}
+
+// SetRemoteSwitches replaces all rules of SourceRemote with rules.
+func SetRemoteSwitches(rules []SwitchRule) error {
+	remote := make([]SwitchRule, 0, len(rules))
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		r.Source = SourceRemote
+		remote = append(remote, r)
+	}
+	switches.update(func(old []SwitchRule) []SwitchRule {
+		old = removeRules(old, func(r *SwitchRule) bool { return r.Source == SourceRemote })
+		return append(old, remote...)
+	})
+	return nil
+}
+
+// ApplyRemoteSwitches is a remote.ConfigApplyFunc which sets the switches of the Operation by SetRemoteSwitches.
+func ApplyRemoteSwitches(op *operation.Operation) error {
+	rules := make([]SwitchRule, 0, len(op.GetSwitches()))
+	for _, s := range op.GetSwitches() {
+		r := SwitchRule{Signal: Signal(s.GetSignal()), Service: s.GetService(), Method: s.GetMethod()}
+		if s.GetExpireAt() > 0 {
+			r.ExpireAt = time.Unix(s.GetExpireAt(), 0)
+		}
+		rules = append(rules, r)
+	}
+	return SetRemoteSwitches(rules)
+}
+
+// update replaces the rules by fn, the expired rules are removed and the timer is reset to the next expiry.
+func (t *switchTable) update(fn func([]SwitchRule) []SwitchRule) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, _ := t.rules.Load().([]SwitchRule)
+	rules := fn(append([]SwitchRule(nil), old...))
+	now := time.Now()
+	rules = removeRules(rules, func(r *SwitchRule) bool { return r.expired(now) })
+	t.rules.Store(rules)
+	atomic.StoreInt32(&t.n, int32(len(rules)))
+
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	var next time.Time
+	for _, r := range rules {
+		if !r.ExpireAt.IsZero() && (next.IsZero() || r.ExpireAt.Before(next)) {
+			next = r.ExpireAt
+		}
+	}
+	if !next.IsZero() {
+		t.timer = time.AfterFunc(next.Sub(now), func() {
+			t.update(func(rules []SwitchRule) []SwitchRule { return rules })
+		})
+	}
+}
+
+func removeRules(rules []SwitchRule, remove func(*SwitchRule) bool) []SwitchRule {
+	kept := rules[:0]
+	for i := range rules {
+		if !remove(&rules[i]) {
+			kept = append(kept, rules[i])
+		}
+	}
+	return kept
+}
@@ -0,0 +1,116 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package admin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+func resetSwitches() {
+	switches.update(func([]SwitchRule) []SwitchRule { return nil })
+}
+
+func TestDisabled(t *testing.T) {
+	defer resetSwitches()
+	require.False(t, Disabled(SignalTrace, "svc", "/m"))
+
+	require.NoError(t, DisableSignal(SwitchRule{Signal: SignalTraceBody, Service: "svc", Method: "/noisy"}))
+	require.NoError(t, DisableSignal(SwitchRule{Signal: SignalMetrics, Service: "svc", Method: "*"}))
+	require.True(t, Disabled(SignalTraceBody, "svc", "/noisy"))
+	require.False(t, Disabled(SignalTraceBody, "svc", "/other"))
+	require.False(t, Disabled(SignalTrace, "svc", "/noisy"))
+	require.True(t, Disabled(SignalMetrics, "svc", "/any"))
+	require.False(t, Disabled(SignalMetrics, "other", "/any"))
+	require.False(t, TraceDisabled())
+
+	require.Error(t, DisableSignal(SwitchRule{Signal: "unknown"}))
+	require.Error(t, DisableSignal(SwitchRule{Signal: SignalLogExport, Service: "svc"}))
+
+	require.Equal(t, 1, EnableSignal(SignalMetrics, "svc", ""))
+	require.False(t, Disabled(SignalMetrics, "svc", "/any"))
+	require.Len(t, Switches(), 1)
+}
+
+func TestDisabledExpire(t *testing.T) {
+	defer resetSwitches()
+	require.NoError(t, DisableSignal(SwitchRule{Signal: SignalFlowLog, ExpireAt: time.Now().Add(50 * time.Millisecond)}))
+	require.True(t, Disabled(SignalFlowLog, "svc", "/m"))
+	require.Eventually(t, func() bool {
+		return !Disabled(SignalFlowLog, "svc", "/m") && len(Switches()) == 0
+	}, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		switches.mu.Lock()
+		defer switches.mu.Unlock()
+		return len(switches.rules.Load().([]SwitchRule)) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestApplyRemoteSwitches(t *testing.T) {
+	defer resetSwitches()
+	require.NoError(t, DisableSignal(SwitchRule{Signal: SignalTrace}))
+	require.NoError(t, ApplyRemoteSwitches(&operation.Operation{Switches: []*operation.Switch{
+		{Signal: string(SignalLogExport)},
+		{Signal: string(SignalMetrics), Service: "svc", ExpireAt: time.Now().Add(time.Hour).Unix()},
+	}}))
+	require.True(t, Disabled(SignalLogExport, "", ""))
+	require.True(t, Disabled(SignalMetrics, "svc", "/m"))
+	require.Len(t, Switches(), 3)
+
+	// each remote config replaces the remote rules only
+	require.NoError(t, ApplyRemoteSwitches(&operation.Operation{}))
+	require.False(t, Disabled(SignalLogExport, "", ""))
+	require.True(t, TraceDisabled())
+	require.Len(t, Switches(), 1)
+
+	require.Error(t, ApplyRemoteSwitches(&operation.Operation{Switches: []*operation.Switch{{Signal: "unknown"}}}))
+}
+
+func TestSwitchHandlers(t *testing.T) {
+	defer resetSwitches()
+	w := httptest.NewRecorder()
+	DisableSwitch(w, httptest.NewRequest(http.MethodGet,
+		"/cmds/switches/disable?signal=trace_body&service=svc&method=/m&ttl=10m", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, Disabled(SignalTraceBody, "svc", "/m"))
+	rules := Switches()
+	require.Len(t, rules, 1)
+	require.Equal(t, SourceAdmin, rules[0].Source)
+	require.WithinDuration(t, time.Now().Add(10*time.Minute), rules[0].ExpireAt, time.Minute)
+
+	w = httptest.NewRecorder()
+	DisableSwitch(w, httptest.NewRequest(http.MethodGet, "/cmds/switches/disable?signal=trace&ttl=abc", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	ListSwitches(w, httptest.NewRequest(http.MethodGet, "/cmds/switches", nil))
+	require.Contains(t, w.Body.String(), `"signal": "trace_body"`)
+
+	w = httptest.NewRecorder()
+	EnableSwitch(w, httptest.NewRequest(http.MethodGet, "/cmds/switches/enable?signal=trace_body&service=svc&method=/m", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.False(t, Disabled(SignalTraceBody, "svc", "/m"))
+
+	DisableTrace(httptest.NewRecorder(), nil)
+	require.True(t, TraceDisabled())
+	require.True(t, Disabled(SignalTrace, "svc", "/m"))
+	EnableTrace(httptest.NewRecorder(), nil)
+	require.False(t, TraceDisabled())
+}
@@ -0,0 +1,280 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package alertrules generates Prometheus alerting rules from the Alert of operation.Operation.
+package alertrules
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/api"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+const (
+	// TypeMax fires if the value is greater than the threshold
+	TypeMax = "max"
+	// TypeMin fires if the value is less than the threshold
+	TypeMin = "min"
+	// TypeDeltaPercentMaxPrefix fires if the value changes by more than threshold percent compared with
+	// the value the duration ago, e.g. delta_percent_max_5m, delta_percent_max_1d
+	TypeDeltaPercentMaxPrefix = "delta_percent_max_"
+
+	// DefaultNamespace default namespace selector
+	DefaultNamespace = "Production"
+	// DefaultRateWindow default range of rate()
+	DefaultRateWindow = "1m"
+)
+
+// groupBy labels of the series of an alert, they are target labels of the registries
+var groupBy = []string{api.NamespaceKey, api.AppKey, api.ServerKey}
+
+// RuleGroups is a Prometheus rule file.
+type RuleGroups struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of Prometheus rules.
+type RuleGroup struct {
+	Name     string `yaml:"name"`
+	Interval string `yaml:"interval,omitempty"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+// Rule is a Prometheus alerting rule.
+type Rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// Marshal returns the YAML of the rule file.
+func (g *RuleGroups) Marshal() ([]byte, error) {
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(g); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(buf.String()), nil
+}
+
+type options struct {
+	rateWindow string
+}
+
+// Option Generate option
+type Option func(*options)
+
+// WithRateWindow sets the range of rate(), default 1m.
+func WithRateWindow(window string) Option {
+	return func(o *options) {
+		o.rateWindow = window
+	}
+}
+
+// Generate returns the alerting rules of op.Alert, all items are in a group named by the resource.
+//
+// Item.Name is either an indicator of the rpc metrics emitted by the SDK, see Indicators,
+// or a metric name which is summed up. Item.Expr replaces the query of Item.Name if it is set.
+// Series are selected by the namespace (regex, Item.Namespace, Alert.Namespace or Production),
+// the app and server of the resource and Item.Matchers, and summed by namespace, app and server.
+func Generate(op *operation.Operation, opts ...Option) (*RuleGroups, error) {
+	o := &options{rateWindow: DefaultRateWindow}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if _, err := model.ParseDuration(o.rateWindow); err != nil {
+		return nil, fmt.Errorf("invalid rate window %q: %w", o.rateWindow, err)
+	}
+	alert := op.GetAlert()
+	group := RuleGroup{
+		Name:     groupName(op.GetResource()),
+		Interval: alert.GetInterval(),
+		Rules:    make([]Rule, 0, len(alert.GetItems())),
+	}
+	for i, item := range alert.GetItems() {
+		rule, err := generateRule(op, item, o)
+		if err != nil {
+			return nil, fmt.Errorf("alert item %d %q: %w", i, item.GetName(), err)
+		}
+		group.Rules = append(group.Rules, rule)
+	}
+	return &RuleGroups{Groups: []RuleGroup{group}}, nil
+}
+
+func groupName(r *operation.Resource) string {
+	name := r.GetApp() + "." + r.GetServer()
+	if r.GetTenant() != "" {
+		name = r.GetTenant() + "/" + name
+	}
+	return name
+}
+
+func generateRule(op *operation.Operation, item *operation.Item, o *options) (Rule, error) {
+	if item.GetName() == "" && item.GetExpr() == "" {
+		return Rule{}, fmt.Errorf("name or expr is required")
+	}
+	sel, err := selector(op, item)
+	if err != nil {
+		return Rule{}, err
+	}
+	query := func(offset string) string {
+		if item.GetExpr() != "" {
+			if offset == "" {
+				return item.GetExpr()
+			}
+			// offset is not applicable to an arbitrary expression, use the last point of a subquery instead
+			return fmt.Sprintf("last_over_time((%s)[%s:]%s)", item.GetExpr(), o.rateWindow, offset)
+		}
+		if indicator, ok := indicators[item.GetName()]; ok {
+			return indicator.query(sel, o.rateWindow, offset)
+		}
+		return fmt.Sprintf("sum by (%s) (%s{%s}%s)", strings.Join(groupBy, ", "), item.GetName(), sel, offset)
+	}
+
+	threshold := strconv.FormatFloat(item.GetThreshold(), 'f', -1, 64)
+	var expr, summary string
+	switch typ := item.GetType(); {
+	case typ == TypeMax:
+		expr = operand(item, query("")) + " > " + threshold
+		summary = "greater than " + threshold
+	case typ == TypeMin:
+		expr = operand(item, query("")) + " < " + threshold
+		summary = "less than " + threshold
+	case strings.HasPrefix(typ, TypeDeltaPercentMaxPrefix):
+		d := strings.TrimPrefix(typ, TypeDeltaPercentMaxPrefix)
+		if _, err := model.ParseDuration(d); err != nil {
+			return Rule{}, fmt.Errorf("invalid duration of type %q: %w", typ, err)
+		}
+		current, previous := query(""), query(" offset "+d)
+		expr = fmt.Sprintf("abs((%s) - (%s)) / (%s) * 100 > %s", current, previous, previous, threshold)
+		summary = fmt.Sprintf("changed by more than %s%% compared with %s ago", threshold, d)
+	default:
+		return Rule{}, fmt.Errorf("unknown type %q", typ)
+	}
+
+	rule := Rule{
+		Alert:       item.GetAlert(),
+		Expr:        expr,
+		For:         item.GetFor(),
+		Labels:      copyMap(item.GetLabels()),
+		Annotations: copyMap(item.GetAnnotations()),
+	}
+	if rule.Alert == "" {
+		rule.Alert = alertName(item)
+	}
+	if rule.For == "" {
+		rule.For = op.GetAlert().GetFor()
+	}
+	if _, ok := rule.Annotations["summary"]; !ok {
+		if rule.Annotations == nil {
+			rule.Annotations = make(map[string]string)
+		}
+		name := item.GetName()
+		if name == "" {
+			name = "expr"
+		}
+		rule.Annotations["summary"] = fmt.Sprintf("{{ $labels.app }}.{{ $labels.server }} %s is %s, current value: {{ $value }}",
+			name, summary)
+	}
+	return rule, nil
+}
+
+// operand returns the query as an operand of a comparison, Item.Expr is parenthesized
+// as it may contain operators of lower precedence, such as `or`.
+func operand(item *operation.Item, query string) string {
+	if item.GetExpr() != "" {
+		return "(" + query + ")"
+	}
+	return query
+}
+
+// selector returns the label matchers of the series of item, without braces.
+func selector(op *operation.Operation, item *operation.Item) (string, error) {
+	namespace := item.GetNamespace()
+	if namespace == "" {
+		namespace = op.GetAlert().GetNamespace()
+	}
+	if namespace == "" {
+		namespace = DefaultNamespace
+	}
+	matchers := []string{matcher(api.NamespaceKey, "=~", namespace)}
+	if app := op.GetResource().GetApp(); app != "" {
+		matchers = append(matchers, matcher(api.AppKey, "=", app))
+	}
+	if server := op.GetResource().GetServer(); server != "" {
+		matchers = append(matchers, matcher(api.ServerKey, "=", server))
+	}
+	for _, m := range item.GetMatchers() {
+		typ, ok := matchTypes[m.GetType()]
+		if !ok {
+			return "", fmt.Errorf("unknown matcher type %q of %q", m.GetType(), m.GetName())
+		}
+		if !model.LabelName(m.GetName()).IsValid() {
+			return "", fmt.Errorf("invalid matcher label name %q", m.GetName())
+		}
+		matchers = append(matchers, matcher(m.GetName(), typ, m.GetValue()))
+	}
+	return strings.Join(matchers, ", "), nil
+}
+
+// matchTypes maps Matcher.Type to PromQL match operators, the empty type is equality.
+var matchTypes = map[string]string{
+	"": "=", "=": "=", "!=": "!=", "=~": "=~", "!~": "!~",
+	"eq": "=", "neq": "!=", "re": "=~", "nre": "!~",
+}
+
+func matcher(name, typ, value string) string {
+	return name + typ + strconv.Quote(value)
+}
+
+func alertName(item *operation.Item) string {
+	name := item.GetName()
+	if name == "" {
+		name = "expr"
+	}
+	return name + "_" + item.GetType()
+}
+
+func copyMap(m map[string]string) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+// Indicators returns the names of the indicators of the rpc metrics, which can be used as Item.Name.
+func Indicators() []string {
+	names := make([]string, 0, len(indicators))
+	for name := range indicators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
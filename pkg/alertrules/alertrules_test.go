@@ -0,0 +1,130 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package alertrules
+
+import (
+	"context"
+	"flag"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGenerate_Golden(t *testing.T) {
+	for _, name := range []string{"operation.yaml", "operation.json"} {
+		t.Run(name, func(t *testing.T) {
+			op, err := LoadOperation(filepath.Join("testdata", name))
+			require.NoError(t, err)
+			rules, err := Generate(op)
+			require.NoError(t, err)
+			got, err := rules.Marshal()
+			require.NoError(t, err)
+
+			golden := filepath.Join("testdata", strings.TrimSuffix(name, filepath.Ext(name))+"_"+
+				strings.TrimPrefix(filepath.Ext(name), ".")+".golden.yaml")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, got, 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), string(got))
+		})
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		item *operation.Item
+	}{
+		{"no name", &operation.Item{Type: TypeMax}},
+		{"unknown type", &operation.Item{Name: "rpc_server_requests", Type: "avg"}},
+		{"invalid delta duration", &operation.Item{Name: "rpc_server_requests", Type: "delta_percent_max_x"}},
+		{"unknown matcher type", &operation.Item{Name: "rpc_server_requests", Type: TypeMax,
+			Matchers: []*operation.Matcher{{Name: "code", Type: "like", Value: "1"}}}},
+		{"invalid matcher name", &operation.Item{Name: "rpc_server_requests", Type: TypeMax,
+			Matchers: []*operation.Matcher{{Name: "a-b", Value: "1"}}}},
+	}
+	for _, tt := range tests {
+		_, err := Generate(&operation.Operation{Alert: &operation.Alert{Items: []*operation.Item{tt.item}}})
+		assert.Error(t, err, tt.name)
+	}
+	_, err := Generate(&operation.Operation{}, WithRateWindow("1x"))
+	assert.Error(t, err)
+}
+
+func TestGenerate_RateWindow(t *testing.T) {
+	rules, err := Generate(&operation.Operation{Alert: &operation.Alert{Items: []*operation.Item{
+		{Name: "rpc_server_requests", Type: TypeMin, Threshold: 1},
+	}}}, WithRateWindow("5m"))
+	require.NoError(t, err)
+	require.Len(t, rules.Groups[0].Rules, 1)
+	assert.Equal(t, `sum by (namespace, app, server) (rate(rpc_server_handled_total{namespace=~"Production"}[5m])) < 1`,
+		rules.Groups[0].Rules[0].Expr)
+	assert.Equal(t, "rpc_server_requests_min", rules.Groups[0].Rules[0].Alert)
+}
+
+func TestLoadOperation(t *testing.T) {
+	_, err := LoadOperation(filepath.Join("testdata", "nonexistent.yaml"))
+	assert.Error(t, err)
+	path := filepath.Join(t.TempDir(), "op.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("alert: [1"), 0600))
+	_, err = LoadOperation(path)
+	assert.Error(t, err)
+	require.NoError(t, os.WriteFile(path, []byte("unknown_field: 1"), 0600))
+	_, err = LoadOperation(path)
+	assert.Error(t, err)
+}
+
+type testOperationServer struct {
+	operation.UnimplementedOperationServiceServer
+	op *operation.Operation
+}
+
+func (s *testOperationServer) GetOperation(ctx context.Context, req *operation.GetOperationRequest) (
+	*operation.GetOperationResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if len(md.Get("x-tps-tenantid")) == 0 || req.GetApp() != s.op.GetResource().GetApp() {
+		return &operation.GetOperationResponse{}, nil
+	}
+	return &operation.GetOperationResponse{Operation: s.op}, nil
+}
+
+func TestFetchOperation(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	want := &operation.Operation{Resource: &operation.Resource{App: "demo", Server: "greeter"}}
+	operation.RegisterOperationServiceServer(s, &testOperationServer{op: want})
+	go func() { _ = s.Serve(l) }()
+	defer s.Stop()
+
+	op, err := FetchOperation(context.Background(), l.Addr().String(), "default", "demo", "greeter")
+	require.NoError(t, err)
+	assert.Equal(t, "greeter", op.GetResource().GetServer())
+
+	_, err = FetchOperation(context.Background(), l.Addr().String(), "default", "other", "greeter")
+	assert.Error(t, err)
+}
@@ -0,0 +1,71 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package alertrules
+
+import (
+	"fmt"
+	"strings"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/config/codes"
+)
+
+// indicator is a PromQL template over the rpc metrics emitted by the SDK.
+type indicator struct {
+	// query returns the PromQL of the selector, the range of rate() and the offset modifier
+	query func(sel, window, offset string) string
+}
+
+// indicators of Item.Name, rates are percentages and latencies are seconds.
+var indicators = map[string]indicator{}
+
+func init() {
+	for _, side := range []string{"server", "client"} {
+		handled := "rpc_" + side + "_handled_total"
+		seconds := "rpc_" + side + "_handled_seconds"
+		indicators["rpc_"+side+"_requests"] = indicator{func(sel, window, offset string) string {
+			return sumRate(handled, sel, window, offset)
+		}}
+		for name, codeType := range map[string]string{
+			"error_rate":   codes.CodeTypeException.String(),
+			"timeout_rate": codes.CodeTypeTimeout.String(),
+			"success_rate": codes.CodeTypeSuccess.String(),
+		} {
+			codeType := codeType
+			indicators["rpc_"+side+"_"+name] = indicator{func(sel, window, offset string) string {
+				return fmt.Sprintf("%s / %s * 100",
+					sumRate(handled, sel+`, code_type="`+codeType+`"`, window, offset),
+					sumRate(handled, sel, window, offset))
+			}}
+		}
+		for name, q := range map[string]string{"p50": "0.5", "p90": "0.9", "p95": "0.95", "p99": "0.99"} {
+			q := q
+			indicators["rpc_"+side+"_latency_"+name] = indicator{func(sel, window, offset string) string {
+				return fmt.Sprintf("histogram_quantile(%s, sum by (%s, le) (rate(%s_bucket{%s}[%s]%s)))",
+					q, strings.Join(groupBy, ", "), seconds, sel, window, offset)
+			}}
+		}
+		indicators["rpc_"+side+"_latency_avg"] = indicator{func(sel, window, offset string) string {
+			return fmt.Sprintf("%s / %s",
+				sumRate(seconds+"_sum", sel, window, offset), sumRate(seconds+"_count", sel, window, offset))
+		}}
+	}
+	indicators["rpc_server_panics"] = indicator{func(sel, window, offset string) string {
+		return fmt.Sprintf("sum by (%s) (increase(rpc_server_panic_total{%s}[%s]%s))",
+			strings.Join(groupBy, ", "), sel, window, offset)
+	}}
+}
+
+func sumRate(name, sel, window, offset string) string {
+	return fmt.Sprintf("sum by (%s) (rate(%s{%s}[%s]%s))", strings.Join(groupBy, ", "), name, sel, window, offset)
+}
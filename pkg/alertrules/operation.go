@@ -0,0 +1,79 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package alertrules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// FetchOperation gets the operation of the server from the OperationService at addr.
+func FetchOperation(ctx context.Context, addr, tenant, app, server string) (*operation.Operation, error) {
+	cc, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dial %s err: %w", addr, err)
+	}
+	defer cc.Close()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+		"x-tps-tenantid": tenant,
+	}))
+	rsp, err := operation.NewOperationServiceClient(cc).GetOperation(ctx, &operation.GetOperationRequest{
+		Tenant: tenant,
+		App:    app,
+		Server: server,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetOperation err: %w", err)
+	}
+	if rsp.GetOperation() == nil {
+		return nil, fmt.Errorf("operation of %s.%s is not found", app, server)
+	}
+	return rsp.GetOperation(), nil
+}
+
+// LoadOperation reads an operation from a local file in the protobuf JSON format,
+// files with the .yaml or .yml extension are converted from YAML.
+func LoadOperation(path string) (*operation.Operation, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml %s err: %w", path, err)
+		}
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("convert yaml %s to json err: %w", path, err)
+		}
+	}
+	op := &operation.Operation{}
+	if err := protojson.Unmarshal(data, op); err != nil {
+		return nil, fmt.Errorf("unmarshal operation %s err: %w", path, err)
+	}
+	return op, nil
+}
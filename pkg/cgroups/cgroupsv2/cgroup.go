@@ -22,6 +22,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/shopspring/decimal"
 )
@@ -81,3 +82,27 @@ func (cg *CGroup) readDecimal(param string) (decimal.Decimal, error) {
 	}
 	return decimal.NewFromString(text)
 }
+
+// readStat reads a flat keyed file such as `memory.stat`, each line of which is `<key> <value>`.
+func (cg *CGroup) readStat(param string) (map[string]uint64, error) {
+	statFile, err := os.Open(cg.ParamPath(param))
+	if err != nil {
+		return nil, err
+	}
+	defer statFile.Close()
+
+	stat := make(map[string]uint64)
+	scanner := bufio.NewScanner(statFile)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		stat[fields[0]] = v
+	}
+	return stat, scanner.Err()
+}
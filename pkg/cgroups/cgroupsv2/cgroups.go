@@ -40,6 +40,20 @@ const (
 	_cgroupSubsysMemory = "memory"
 
 	_cgroupMemoryLimitBytes = "memory.limit_in_bytes"
+	_cgroupMemoryUsageBytes = "memory.usage_in_bytes"
+	// _cgroupMemoryStat is the file name for the CGroup memory statistics,
+	// it has the same name in CGroup-V1 and CGroup-V2.
+	_cgroupMemoryStat = "memory.stat"
+	// _cgroupMemoryStatInactiveFile is the key of inactive file cache in CGroup-V1 `memory.stat`,
+	// including the descendant cgroups.
+	_cgroupMemoryStatInactiveFile = "total_inactive_file"
+
+	// _cgroupCPUCFSQuotaUsParam is the file name for the CGroup CFS quota
+	// parameter.
+	_cgroupCPUCFSQuotaUsParam = "cpu.cfs_quota_us"
+	// _cgroupCPUCFSPeriodUsParam is the file name for the CGroup CFS period
+	// parameter.
+	_cgroupCPUCFSPeriodUsParam = "cpu.cfs_period_us"
 
 	// _cgroupv2MemoryMax is the file name for the CGroup-V2 Memory max
 	// parameter.
@@ -133,6 +147,60 @@ func (cg CGroups) MemoryQuota() (int64, bool, error) {
 	return int64(b), true, nil
 }
 
+// MemoryUsage returns the memory usage of the process, it is a result of `memory.usage_in_bytes`.
+func (cg CGroups) MemoryUsage() (int64, bool, error) {
+	memCGroup, exists := cg[_cgroupSubsysMemory]
+	if !exists {
+		return -1, false, nil
+	}
+	memUsageBytes, err := memCGroup.readInt(_cgroupMemoryUsageBytes)
+	if defined := memUsageBytes > 0; err != nil || !defined {
+		return -1, defined, err
+	}
+	return int64(memUsageBytes), true, nil
+}
+
+// MemoryWorkingSet returns the working set memory of the process,
+// which is `memory.usage_in_bytes` minus `total_inactive_file` in `memory.stat`, as kubelet does.
+func (cg CGroups) MemoryWorkingSet() (int64, bool, error) {
+	usage, defined, err := cg.MemoryUsage()
+	if err != nil || !defined {
+		return usage, defined, err
+	}
+	stat, err := cg[_cgroupSubsysMemory].readStat(_cgroupMemoryStat)
+	if err != nil {
+		return -1, false, err
+	}
+	return workingSet(usage, stat[_cgroupMemoryStatInactiveFile]), true, nil
+}
+
+// CPUQuota returns the CPU quota applied with the CPU cgroup controller.
+// It is a result of `cpu.cfs_quota_us / cpu.cfs_period_us`. If the value of
+// `cpu.cfs_quota_us` was not set (-1), the method returns `(-1, false, nil)`.
+func (cg CGroups) CPUQuota() (float64, bool, error) {
+	cpuCGroup, exists := cg[_cgroupSubsysCPU]
+	if !exists {
+		return -1, false, nil
+	}
+	cfsQuotaUs, err := cpuCGroup.readInt(_cgroupCPUCFSQuotaUsParam)
+	if defined := cfsQuotaUs > 0; err != nil || !defined {
+		return -1, defined, err
+	}
+	cfsPeriodUs, err := cpuCGroup.readInt(_cgroupCPUCFSPeriodUsParam)
+	if defined := cfsPeriodUs > 0; err != nil || !defined {
+		return -1, defined, err
+	}
+	return float64(cfsQuotaUs) / float64(cfsPeriodUs), true, nil
+}
+
+// workingSet returns usage minus inactive file cache, and 0 if negative.
+func workingSet(usage int64, inactiveFile uint64) int64 {
+	if uint64(usage) < inactiveFile {
+		return 0
+	}
+	return usage - int64(inactiveFile)
+}
+
 // IsCGroupV2 returns true if the system supports and uses cgroup2.
 // It gets the required information for deciding from mountinfo file.
 func IsCGroupV2() (bool, error) {
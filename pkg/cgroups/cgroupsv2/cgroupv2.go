@@ -0,0 +1,222 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// _cgroupv2CPUMax is the file name for the CGroup-V2 CPU max parameter,
+	// its content is `$MAX $PERIOD`, and $MAX is "max" if not limited.
+	_cgroupv2CPUMax = "cpu.max"
+	// _cgroupv2MemoryCurrent is the file name for the CGroup-V2 memory usage.
+	_cgroupv2MemoryCurrent = "memory.current"
+	// _cgroupv2MemoryStatInactiveFile is the key of inactive file cache in CGroup-V2 `memory.stat`.
+	_cgroupv2MemoryStatInactiveFile = "inactive_file"
+	// _cgroupv2Unlimited is the value of unlimited CGroup-V2 parameters.
+	_cgroupv2Unlimited = "max"
+)
+
+// CGroupV2 represents the CGroup-V2 (unified hierarchy) of a process.
+// Limits of a cgroup are also restricted by its ancestors, so the quota methods
+// walk up the nested hierarchy until the mount point and return the smallest limit.
+type CGroupV2 struct {
+	mountPoint string
+	cgroup     *CGroup
+}
+
+// NewCGroupV2 returns a new *CGroupV2 from the mount point of the cgroup2 file system
+// and the path of the cgroup, path must be mountPoint or a descendant of it.
+func NewCGroupV2(mountPoint, path string) *CGroupV2 {
+	return &CGroupV2{mountPoint: filepath.Clean(mountPoint), cgroup: NewCGroup(filepath.Clean(path))}
+}
+
+// NewCGroupV2FromProc parses procPathMountInfo and procPathCGroup (usually at `/proc/$PID/mountinfo`
+// and `/proc/$PID/cgroup`) and returns the CGroup-V2 of the process. It returns nil if the process
+// does not use CGroup-V2, that is, cgroup2 is not mounted, or any CGroup-V1 cpu/memory controller is mounted.
+func NewCGroupV2FromProc(procPathMountInfo, procPathCGroup string) (*CGroupV2, error) {
+	var (
+		v2Mount  *MountPoint
+		v1Mounts bool
+	)
+	newMountPoint := func(mp *MountPoint) error {
+		switch mp.FSType {
+		case _cgroupv2FSType:
+			if v2Mount == nil || mp.MountPoint == _cgroupv2MountPoint {
+				v2Mount = mp
+			}
+		case _cgroupFSType:
+			for _, opt := range mp.SuperOptions {
+				if opt == _cgroupSubsysCPU || opt == _cgroupSubsysMemory {
+					v1Mounts = true
+				}
+			}
+		}
+		return nil
+	}
+	if err := parseMountInfo(procPathMountInfo, newMountPoint); err != nil {
+		return nil, err
+	}
+	if v2Mount == nil || v1Mounts {
+		return nil, nil
+	}
+
+	subsystems, err := parseCGroupSubsystems(procPathCGroup)
+	if err != nil {
+		return nil, err
+	}
+	// the CGroup-V2 entry is always in the format `0::$PATH`
+	subsys, exists := subsystems[""]
+	if !exists || subsys.ID != 0 {
+		return NewCGroupV2(v2Mount.MountPoint, v2Mount.MountPoint), nil
+	}
+	path, err := v2Mount.Translate(subsys.Name)
+	if err != nil {
+		// the cgroup is not visible from the mount point, e.g. the container has its own cgroup namespace
+		// but mounts the cgroup2 of the host, the mount point is used as the cgroup of the process.
+		return NewCGroupV2(v2Mount.MountPoint, v2Mount.MountPoint), nil
+	}
+	if _, err := os.Stat(path); err != nil {
+		return NewCGroupV2(v2Mount.MountPoint, v2Mount.MountPoint), nil
+	}
+	return NewCGroupV2(v2Mount.MountPoint, path), nil
+}
+
+// NewCGroupV2ForCurrentProcess returns the CGroup-V2 of the current process,
+// it returns nil if the current process does not use CGroup-V2.
+func NewCGroupV2ForCurrentProcess() (*CGroupV2, error) {
+	return NewCGroupV2FromProc(_procPathMountInfo, _procPathCGroup)
+}
+
+// Path returns the path of the cgroup.
+func (cg *CGroupV2) Path() string {
+	return cg.cgroup.Path()
+}
+
+// hierarchy returns the cgroup and its ancestors until the mount point.
+func (cg *CGroupV2) hierarchy() []*CGroup {
+	cgroups := []*CGroup{cg.cgroup}
+	for path := cg.cgroup.Path(); path != cg.mountPoint; {
+		parent := filepath.Dir(path)
+		if parent == path || !strings.HasPrefix(parent, cg.mountPoint) {
+			break
+		}
+		path = parent
+		cgroups = append(cgroups, NewCGroup(path))
+	}
+	return cgroups
+}
+
+// CPUQuota returns the CPU quota of the process, it is a result of `$MAX / $PERIOD` in `cpu.max`.
+// The smallest quota in the nested hierarchy is returned, and `(-1, false, nil)` if no quota is set.
+func (cg *CGroupV2) CPUQuota() (float64, bool, error) {
+	quota, defined := float64(-1), false
+	for _, c := range cg.hierarchy() {
+		text, err := c.readFirstLine(_cgroupv2CPUMax)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return -1, false, err
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 || len(fields) > 2 {
+			return -1, false, cgroupParamFormatInvalidError{c.ParamPath(_cgroupv2CPUMax), text}
+		}
+		if fields[0] == _cgroupv2Unlimited {
+			continue
+		}
+		max, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return -1, false, err
+		}
+		period := int64(100000) // default period is 100ms
+		if len(fields) == 2 {
+			if period, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+				return -1, false, err
+			}
+		}
+		if max <= 0 || period <= 0 {
+			continue
+		}
+		if q := float64(max) / float64(period); !defined || q < quota {
+			quota, defined = q, true
+		}
+	}
+	return quota, defined, nil
+}
+
+// MemoryQuota returns the memory limit of the process, it is a result of `memory.max`.
+// The smallest limit in the nested hierarchy is returned, and `(-1, false, nil)` if no limit is set.
+func (cg *CGroupV2) MemoryQuota() (int64, bool, error) {
+	quota, defined := int64(-1), false
+	for _, c := range cg.hierarchy() {
+		text, err := c.readFirstLine(_cgroupv2MemoryMax)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return -1, false, err
+		}
+		text = strings.TrimSpace(text)
+		if text == _cgroupv2Unlimited {
+			continue
+		}
+		max, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return -1, false, err
+		}
+		if max > 0 && (!defined || max < quota) {
+			quota, defined = max, true
+		}
+	}
+	return quota, defined, nil
+}
+
+// MemoryUsage returns the memory usage of the process, it is a result of `memory.current`.
+func (cg *CGroupV2) MemoryUsage() (int64, bool, error) {
+	text, err := cg.cgroup.readFirstLine(_cgroupv2MemoryCurrent)
+	if os.IsNotExist(err) {
+		return -1, false, nil
+	}
+	if err != nil {
+		return -1, false, err
+	}
+	usage, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+	if defined := usage > 0; err != nil || !defined {
+		return -1, defined, err
+	}
+	return usage, true, nil
+}
+
+// MemoryWorkingSet returns the working set memory of the process,
+// which is `memory.current` minus `inactive_file` in `memory.stat`, as kubelet does.
+func (cg *CGroupV2) MemoryWorkingSet() (int64, bool, error) {
+	usage, defined, err := cg.MemoryUsage()
+	if err != nil || !defined {
+		return usage, defined, err
+	}
+	stat, err := cg.cgroup.readStat(_cgroupMemoryStat)
+	if err != nil {
+		return -1, false, err
+	}
+	return workingSet(usage, stat[_cgroupv2MemoryStatInactiveFile]), true, nil
+}
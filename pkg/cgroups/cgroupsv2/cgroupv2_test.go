@@ -0,0 +1,155 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mountInfoWithRoot copies the mountinfo fixture with mount points under `/sys/fs/cgroup` moved to root.
+func mountInfoWithRoot(t *testing.T, name, root string) string {
+	b, err := os.ReadFile(filepath.Join(testDataProcPath, "v2", name, "mountinfo"))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "mountinfo")
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(string(b), " /sys/fs/cgroup", " "+root)), 0600))
+	return path
+}
+
+func TestNewCGroupV2FromProc(t *testing.T) {
+	root := filepath.Join(testDataCGroupsPath, "v2", "nested")
+	cg, err := NewCGroupV2FromProc(mountInfoWithRoot(t, "nested", root),
+		filepath.Join(testDataProcPath, "v2", "nested", "cgroup"))
+	require.NoError(t, err)
+	require.NotNil(t, cg)
+	assert.Equal(t, filepath.Join(root, "kubepods", "pod1", "container1"), cg.Path())
+
+	// the cgroup is not visible, e.g. the container has its own cgroup namespace
+	cg, err = NewCGroupV2FromProc(mountInfoWithRoot(t, "nested", root),
+		filepath.Join(testDataProcPath, "cgroups", "cgroup"))
+	require.NoError(t, err)
+	assert.Equal(t, root, cg.Path())
+
+	for _, name := range []string{"cgroupv1", "cgroupv1v2"} {
+		cg, err = NewCGroupV2FromProc(filepath.Join(testDataProcPath, "v2", name, "mountinfo"),
+			filepath.Join(testDataProcPath, "v2", "nested", "cgroup"))
+		assert.NoError(t, err, name)
+		assert.Nil(t, cg, name)
+	}
+
+	_, err = NewCGroupV2FromProc("nonexistent", "nonexistent")
+	assert.Error(t, err)
+	_, err = NewCGroupV2FromProc(mountInfoWithRoot(t, "nested", root), "nonexistent")
+	assert.Error(t, err)
+}
+
+func TestCGroupV2Quota(t *testing.T) {
+	root := filepath.Join(testDataCGroupsPath, "v2", "nested")
+	tests := []struct {
+		name          string
+		path          string
+		cpuQuota      float64
+		cpuDefined    bool
+		memoryQuota   int64
+		memoryDefined bool
+	}{
+		// the smallest limit of the hierarchy: pod1 cpu and memory
+		{"container", filepath.Join(root, "kubepods", "pod1", "container1"), 2, true, 1073741824, true},
+		{"pod", filepath.Join(root, "kubepods", "pod1"), 2, true, 1073741824, true},
+		{"kubepods", filepath.Join(root, "kubepods"), 8, true, 4294967296, true},
+		{"root", root, -1, false, -1, false},
+	}
+	for _, tt := range tests {
+		cg := NewCGroupV2(root, tt.path)
+		cpuQuota, defined, err := cg.CPUQuota()
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.cpuQuota, cpuQuota, tt.name)
+		assert.Equal(t, tt.cpuDefined, defined, tt.name)
+
+		memoryQuota, defined, err := cg.MemoryQuota()
+		assert.NoError(t, err, tt.name)
+		assert.Equal(t, tt.memoryQuota, memoryQuota, tt.name)
+		assert.Equal(t, tt.memoryDefined, defined, tt.name)
+	}
+
+	invalid := filepath.Join(testDataCGroupsPath, "v2", "invalid")
+	_, _, err := NewCGroupV2(invalid, invalid).MemoryQuota()
+	assert.Error(t, err)
+	invalid = filepath.Join(testDataCGroupsPath, "v2", "invalid-cpu")
+	_, _, err = NewCGroupV2(invalid, invalid).CPUQuota()
+	assert.Error(t, err)
+}
+
+func TestCGroupV2MemoryUsage(t *testing.T) {
+	root := filepath.Join(testDataCGroupsPath, "v2", "nested")
+	cg := NewCGroupV2(root, filepath.Join(root, "kubepods", "pod1", "container1"))
+	usage, defined, err := cg.MemoryUsage()
+	assert.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, int64(536870912), usage)
+
+	workingSet, defined, err := cg.MemoryWorkingSet()
+	assert.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, int64(536870912-134217728), workingSet)
+
+	// memory.current does not exist
+	usage, defined, err = NewCGroupV2(root, root).MemoryWorkingSet()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+	assert.Equal(t, int64(-1), usage)
+}
+
+func TestCGroupsV1Usage(t *testing.T) {
+	cgroups := make(CGroups)
+	_, defined, err := cgroups.CPUQuota()
+	assert.False(t, defined)
+	assert.NoError(t, err)
+	_, defined, err = cgroups.MemoryWorkingSet()
+	assert.False(t, defined)
+	assert.NoError(t, err)
+
+	cgroups[_cgroupSubsysCPU] = NewCGroup(filepath.Join(testDataCGroupsPath, "cpu"))
+	cgroups[_cgroupSubsysMemory] = NewCGroup(filepath.Join(testDataCGroupsPath, "memory"))
+	quota, defined, err := cgroups.CPUQuota()
+	assert.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, 6.0, quota)
+
+	usage, defined, err := cgroups.MemoryUsage()
+	assert.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, int64(1073741824), usage)
+
+	workingSet, defined, err := cgroups.MemoryWorkingSet()
+	assert.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, int64(1073741824-268435456), workingSet)
+
+	cgroups[_cgroupSubsysCPU] = NewCGroup(filepath.Join(testDataCGroupsPath, "undefined"))
+	_, defined, err = cgroups.CPUQuota()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+	cgroups[_cgroupSubsysCPU] = NewCGroup(filepath.Join(testDataCGroupsPath, "undefined-period"))
+	_, _, err = cgroups.CPUQuota()
+	assert.Error(t, err)
+}
@@ -26,6 +26,11 @@ type mountPointFormatInvalidError struct {
 	line string
 }
 
+type cgroupParamFormatInvalidError struct {
+	path    string
+	content string
+}
+
 type pathNotExposedFromMountPointError struct {
 	mountPoint string
 	root       string
@@ -40,6 +45,10 @@ func (err mountPointFormatInvalidError) Error() string {
 	return fmt.Sprintf("invalid format for MountPoint: %q", err.line)
 }
 
+func (err cgroupParamFormatInvalidError) Error() string {
+	return fmt.Sprintf("invalid format for CGroup parameter %q: %q", err.path, err.content)
+}
+
 func (err pathNotExposedFromMountPointError) Error() string {
 	return fmt.Sprintf("path %q is not a descendant of mount point root %q and cannot be exposed from %q", err.path, err.root, err.mountPoint)
 }
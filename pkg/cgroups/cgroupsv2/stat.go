@@ -0,0 +1,242 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// _cgroupCPUStat is the file name for the CPU statistics, in both CGroup-V1 and CGroup-V2.
+	_cgroupCPUStat = "cpu.stat"
+	// _cgroupCPUAcctUsage is the file name for the CGroup-V1 CPU usage in nanoseconds.
+	_cgroupCPUAcctUsage = "cpuacct.usage"
+	// _cgroupMemoryOOMControl is the file name for the CGroup-V1 OOM control and statistics.
+	_cgroupMemoryOOMControl = "memory.oom_control"
+	// _cgroupv2MemoryEvents is the file name for the CGroup-V2 memory events.
+	_cgroupv2MemoryEvents = "memory.events"
+	// _cgroupv2PressureSuffix is the suffix of CGroup-V2 PSI files, e.g. cpu.pressure.
+	_cgroupv2PressureSuffix = ".pressure"
+
+	_statNrPeriods     = "nr_periods"
+	_statNrThrottled   = "nr_throttled"
+	_statThrottledTime = "throttled_time" // CGroup-V1, in nanoseconds
+	_statThrottledUsec = "throttled_usec" // CGroup-V2, in microseconds
+	_statUsageUsec     = "usage_usec"     // CGroup-V2, in microseconds
+	_statOOMKill       = "oom_kill"
+)
+
+const (
+	// PressureCPU is the CPU resource of PSI.
+	PressureCPU = "cpu"
+	// PressureMemory is the memory resource of PSI.
+	PressureMemory = "memory"
+	// PressureIO is the IO resource of PSI.
+	PressureIO = "io"
+)
+
+// CPUStat is the CPU statistics of a cgroup.
+type CPUStat struct {
+	// Usage is the total CPU time consumed, it is 0 if not available.
+	Usage time.Duration
+	// Periods is the number of CFS enforcement periods elapsed.
+	Periods uint64
+	// Throttled is the number of periods the cgroup has been throttled.
+	Throttled uint64
+	// ThrottledTime is the total time the cgroup has been throttled.
+	ThrottledTime time.Duration
+}
+
+// PSI is the pressure stall information of a resource,
+// see https://docs.kernel.org/accounting/psi.html for more information.
+type PSI struct {
+	// Some is the share of time in which at least some tasks are stalled on the resource.
+	Some PSIStats
+	// Full is the share of time in which all non-idle tasks are stalled on the resource simultaneously,
+	// it is always zero for CPU at the system level.
+	Full PSIStats
+}
+
+// PSIStats is a line of the pressure file.
+type PSIStats struct {
+	// Avg10, Avg60, Avg300 are the percentages of stall time over the last 10, 60 and 300 seconds.
+	Avg10  float64
+	Avg60  float64
+	Avg300 float64
+	// Total is the total stall time.
+	Total time.Duration
+}
+
+// CPUStat returns the CPU statistics of `cpu.stat` and `cpuacct.usage`.
+// It returns `(nil, false, nil)` if the cpu controller does not exist.
+func (cg CGroups) CPUStat() (*CPUStat, bool, error) {
+	cpuCGroup, exists := cg[_cgroupSubsysCPU]
+	if !exists {
+		return nil, false, nil
+	}
+	stat, err := cpuCGroup.readStat(_cgroupCPUStat)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	s := &CPUStat{
+		Periods:       stat[_statNrPeriods],
+		Throttled:     stat[_statNrThrottled],
+		ThrottledTime: time.Duration(stat[_statThrottledTime]),
+	}
+	if cpuacctCGroup, exists := cg[_cgroupSubsysCPUAcct]; exists {
+		if usage, err := cpuacctCGroup.readDecimal(_cgroupCPUAcctUsage); err == nil {
+			s.Usage = time.Duration(usage.IntPart())
+		}
+	}
+	return s, true, nil
+}
+
+// OOMKills returns the number of processes killed by the OOM killer in the cgroup,
+// it is `oom_kill` in `memory.oom_control`, which is available since linux 4.13.
+func (cg CGroups) OOMKills() (uint64, bool, error) {
+	memCGroup, exists := cg[_cgroupSubsysMemory]
+	if !exists {
+		return 0, false, nil
+	}
+	return readStatKey(memCGroup, _cgroupMemoryOOMControl, _statOOMKill)
+}
+
+// Pressure returns `(nil, false, nil)`, PSI is not available per cgroup in CGroup-V1.
+func (cg CGroups) Pressure(resource string) (*PSI, bool, error) {
+	return nil, false, nil
+}
+
+// CPUStat returns the CPU statistics of `cpu.stat`.
+// It returns `(nil, false, nil)` if `cpu.stat` does not exist.
+func (cg *CGroupV2) CPUStat() (*CPUStat, bool, error) {
+	stat, err := cg.cgroup.readStat(_cgroupCPUStat)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return &CPUStat{
+		Usage:         time.Duration(stat[_statUsageUsec]) * time.Microsecond,
+		Periods:       stat[_statNrPeriods],
+		Throttled:     stat[_statNrThrottled],
+		ThrottledTime: time.Duration(stat[_statThrottledUsec]) * time.Microsecond,
+	}, true, nil
+}
+
+// OOMKills returns the number of processes killed by the OOM killer in the cgroup,
+// it is `oom_kill` in `memory.events`.
+func (cg *CGroupV2) OOMKills() (uint64, bool, error) {
+	return readStatKey(cg.cgroup, _cgroupv2MemoryEvents, _statOOMKill)
+}
+
+// Pressure returns the PSI of the resource (cpu, memory or io) in `$RESOURCE.pressure`.
+// It returns `(nil, false, nil)` if PSI is not enabled.
+func (cg *CGroupV2) Pressure(resource string) (*PSI, bool, error) {
+	return ReadPressure(cg.cgroup.ParamPath(resource + _cgroupv2PressureSuffix))
+}
+
+// ReadPressure parses a pressure file, such as `/proc/pressure/cpu` or `cpu.pressure` of CGroup-V2.
+// It returns `(nil, false, nil)` if the file does not exist.
+func ReadPressure(path string) (*PSI, bool, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer f.Close()
+
+	psi := &PSI{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		var stats *PSIStats
+		switch fields[0] {
+		case "some":
+			stats = &psi.Some
+		case "full":
+			stats = &psi.Full
+		default:
+			return nil, false, cgroupParamFormatInvalidError{path, line}
+		}
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				return nil, false, cgroupParamFormatInvalidError{path, line}
+			}
+			if err := stats.set(kv[0], kv[1]); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	return psi, true, nil
+}
+
+func (s *PSIStats) set(key, value string) error {
+	if key == "total" {
+		total, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		s.Total = time.Duration(total) * time.Microsecond
+		return nil
+	}
+	avg, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	switch key {
+	case "avg10":
+		s.Avg10 = avg
+	case "avg60":
+		s.Avg60 = avg
+	case "avg300":
+		s.Avg300 = avg
+	}
+	return nil
+}
+
+// readStatKey reads the value of key in a flat keyed file,
+// it returns false if the file or the key does not exist.
+func readStatKey(cg *CGroup, param, key string) (uint64, bool, error) {
+	stat, err := cg.readStat(param)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	v, ok := stat[key]
+	return v, ok, nil
+}
@@ -0,0 +1,114 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package cgroups
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCGroupV2Stat(t *testing.T) {
+	root := filepath.Join(testDataCGroupsPath, "v2", "nested")
+	cg := NewCGroupV2(root, filepath.Join(root, "kubepods", "pod1", "container1"))
+
+	stat, defined, err := cg.CPUStat()
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, &CPUStat{
+		Usage:         12345678 * time.Microsecond,
+		Periods:       1000,
+		Throttled:     25,
+		ThrottledTime: 1500 * time.Millisecond,
+	}, stat)
+
+	oomKills, defined, err := cg.OOMKills()
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, uint64(2), oomKills)
+
+	psi, defined, err := cg.Pressure(PressureCPU)
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, &PSI{
+		Some: PSIStats{Avg10: 1.5, Avg60: 0.8, Avg300: 0.25, Total: 4 * time.Second},
+		Full: PSIStats{Avg10: 0.5, Avg60: 0.2, Avg300: 0.05, Total: time.Second},
+	}, psi)
+
+	// not enabled
+	_, defined, err = cg.Pressure(PressureIO)
+	assert.NoError(t, err)
+	assert.False(t, defined)
+	_, defined, err = NewCGroupV2(root, root).CPUStat()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+	_, defined, err = NewCGroupV2(root, root).OOMKills()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+
+	invalid := filepath.Join(testDataCGroupsPath, "v2", "invalid-cpu")
+	_, _, err = NewCGroupV2(invalid, invalid).Pressure(PressureCPU)
+	assert.Error(t, err)
+}
+
+func TestCGroupsV1Stat(t *testing.T) {
+	cgroups := make(CGroups)
+	_, defined, err := cgroups.CPUStat()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+	_, defined, err = cgroups.OOMKills()
+	assert.NoError(t, err)
+	assert.False(t, defined)
+
+	cgroups[_cgroupSubsysCPU] = NewCGroup(filepath.Join(testDataCGroupsPath, "cpu"))
+	cgroups[_cgroupSubsysCPUAcct] = cgroups[_cgroupSubsysCPU]
+	cgroups[_cgroupSubsysMemory] = NewCGroup(filepath.Join(testDataCGroupsPath, "memory"))
+	stat, defined, err := cgroups.CPUStat()
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, &CPUStat{
+		Usage:         7500 * time.Millisecond,
+		Periods:       2000,
+		Throttled:     100,
+		ThrottledTime: 3 * time.Second,
+	}, stat)
+
+	oomKills, defined, err := cgroups.OOMKills()
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, uint64(1), oomKills)
+
+	_, defined, err = cgroups.Pressure(PressureCPU)
+	assert.NoError(t, err)
+	assert.False(t, defined)
+}
+
+func TestReadPressure(t *testing.T) {
+	psi, defined, err := ReadPressure(filepath.Join(testDataProcPath, "pressure", "cpu"))
+	require.NoError(t, err)
+	assert.True(t, defined)
+	assert.Equal(t, 2.04, psi.Some.Avg10)
+	assert.Equal(t, 123456789*time.Microsecond, psi.Some.Total)
+	assert.Equal(t, PSIStats{}, psi.Full)
+
+	_, defined, err = ReadPressure(filepath.Join(testDataProcPath, "pressure", "nonexistent"))
+	assert.NoError(t, err)
+	assert.False(t, defined)
+}
@@ -0,0 +1,120 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package otelctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+const (
+	// FormatYAML YAML format
+	FormatYAML = "yaml"
+	// FormatJSON protobuf JSON format
+	FormatJSON = "json"
+)
+
+// MarshalOperation marshals op in format with sorted keys, so that the outputs can be diffed.
+func MarshalOperation(op *operation.Operation, format string) ([]byte, error) {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(op)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(v)
+	case FormatJSON:
+		data, err = json.MarshalIndent(v, "", "  ")
+		return append(data, '\n'), err
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// UnmarshalOperation unmarshals an operation in the protobuf JSON format or YAML.
+func UnmarshalOperation(data []byte) (*operation.Operation, error) {
+	if !json.Valid(data) {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml err: %w", err)
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("convert yaml to json err: %w", err)
+		}
+	}
+	op := &operation.Operation{}
+	if err := protojson.Unmarshal(data, op); err != nil {
+		return nil, fmt.Errorf("unmarshal operation err: %w", err)
+	}
+	return op, nil
+}
+
+// Diff returns the line diff from a to b, lines are prefixed with "-" if removed, "+" if added and
+// " " if unchanged. It returns "" if a and b are the same.
+func Diff(a, b string) string {
+	if a == b {
+		return ""
+	}
+	x, y := splitLines(a), splitLines(b)
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var sb strings.Builder
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			sb.WriteString(" " + x[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("-" + x[i] + "\n")
+			i++
+		default:
+			sb.WriteString("+" + y[j] + "\n")
+			j++
+		}
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
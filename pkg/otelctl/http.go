@@ -0,0 +1,155 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package otelctl
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+var (
+	_ sampler.SamplerServiceClient     = (*httpClient)(nil)
+	_ operation.OperationServiceClient = (*httpClient)(nil)
+)
+
+// httpClient calls SamplerService and OperationService through the grpc-gateway,
+// the outgoing metadata of the context is sent as http headers.
+type httpClient struct {
+	endpoint string
+	client   *http.Client
+}
+
+// newHTTPClient creates a new httpClient, endpoint is like http://127.0.0.1:12521.
+func newHTTPClient(endpoint string, client *http.Client) *httpClient {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	return &httpClient{endpoint: strings.TrimSuffix(endpoint, "/"), client: client}
+}
+
+func (c *httpClient) invoke(ctx context.Context, method, path string, in, out proto.Message) error {
+	var body io.Reader
+	if in != nil {
+		data, err := protojson.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.endpoint+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for k, vs := range md {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	rsp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s %s: %s: %s", method, path, rsp.Status, bytes.TrimSpace(data))
+	}
+	return protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, out)
+}
+
+// SetSampler implements sampler.SamplerServiceClient.
+func (c *httpClient) SetSampler(ctx context.Context, in *sampler.SetSamplerRequest,
+	_ ...grpc.CallOption) (*sampler.SetSamplerResponse, error) {
+	out := &sampler.SetSamplerResponse{}
+	return out, c.invoke(ctx, http.MethodPost, "/api/sampler", in, out)
+}
+
+// GetSampler implements sampler.SamplerServiceClient.
+func (c *httpClient) GetSampler(ctx context.Context, _ *sampler.GetSamplerRequest,
+	_ ...grpc.CallOption) (*sampler.GetSamplerResponse, error) {
+	out := &sampler.GetSamplerResponse{}
+	return out, c.invoke(ctx, http.MethodGet, "/api/sampler", nil, out)
+}
+
+// DelSampler implements sampler.SamplerServiceClient.
+func (c *httpClient) DelSampler(ctx context.Context, in *sampler.DelSamplerRequest,
+	_ ...grpc.CallOption) (*sampler.DelSamplerResponse, error) {
+	out := &sampler.DelSamplerResponse{}
+	path := "/api/sampler/" + url.PathEscape(in.GetKey()) + "/" + url.PathEscape(in.GetValue())
+	return out, c.invoke(ctx, http.MethodDelete, path, nil, out)
+}
+
+// JudgeSampler implements sampler.SamplerServiceClient.
+func (c *httpClient) JudgeSampler(ctx context.Context, in *sampler.JudgeSamplerRequest,
+	_ ...grpc.CallOption) (*sampler.JudgeSamplerResponse, error) {
+	out := &sampler.JudgeSamplerResponse{}
+	path := "/api/sampler/judge/" + url.PathEscape(in.GetKey()) + "/" + url.PathEscape(in.GetValue())
+	return out, c.invoke(ctx, http.MethodGet, path, nil, out)
+}
+
+// SetSamplerV2 implements sampler.SamplerServiceClient.
+func (c *httpClient) SetSamplerV2(ctx context.Context, in *sampler.SetSamplerV2Request,
+	_ ...grpc.CallOption) (*sampler.SetSamplerV2Response, error) {
+	out := &sampler.SetSamplerV2Response{}
+	return out, c.invoke(ctx, http.MethodPost, "/api/v2/sampler", in, out)
+}
+
+// GetSamplerV2 implements sampler.SamplerServiceClient.
+func (c *httpClient) GetSamplerV2(ctx context.Context, _ *sampler.GetSamplerV2Request,
+	_ ...grpc.CallOption) (*sampler.GetSamplerV2Response, error) {
+	out := &sampler.GetSamplerV2Response{}
+	return out, c.invoke(ctx, http.MethodGet, "/api/v2/sampler", nil, out)
+}
+
+// SetOperation implements operation.OperationServiceClient.
+func (c *httpClient) SetOperation(ctx context.Context, in *operation.SetOperationRequest,
+	_ ...grpc.CallOption) (*operation.SetOperationResponse, error) {
+	out := &operation.SetOperationResponse{}
+	return out, c.invoke(ctx, http.MethodPost, "/api/operation", in, out)
+}
+
+// GetOperation implements operation.OperationServiceClient.
+func (c *httpClient) GetOperation(ctx context.Context, in *operation.GetOperationRequest,
+	_ ...grpc.CallOption) (*operation.GetOperationResponse, error) {
+	out := &operation.GetOperationResponse{}
+	path := "/api/operation/tenant/" + url.PathEscape(in.GetTenant()) + "/app/" + url.PathEscape(in.GetApp()) +
+		"/server/" + url.PathEscape(in.GetServer())
+	return out, c.invoke(ctx, http.MethodGet, path, nil, out)
+}
+
+// ReportApplyStatus implements operation.OperationServiceClient, it has no http rule.
+func (c *httpClient) ReportApplyStatus(context.Context, *operation.ReportApplyStatusRequest,
+	...grpc.CallOption) (*operation.ReportApplyStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "ReportApplyStatus is not supported by the http gateway")
+}
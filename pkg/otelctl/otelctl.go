@@ -0,0 +1,333 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package otelctl manages the dyeing sampler rules of SamplerService and the operations of OperationService,
+// through grpc or the grpc-gateway.
+package otelctl
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// TenantHeader metadata key of the tenant, the same as Sampler and the remote configurator
+const TenantHeader = "x-tps-tenantid"
+
+// Usage usage of otelctl
+const Usage = `usage: otelctl [-addr host:port | -http http://host:port] [-tenant tenant] [-timeout 10s] <command>
+
+commands:
+  sampler list                                      list the dyeing key/values
+  sampler add [-ttl 1h] [-comment c] key value...   add sampled key/values, never expire if ttl is 0
+  sampler del key [value...]                        delete key/values, all values of the key if none is given
+  sampler judge key value                           test whether the key/value is sampled
+  operation get -app app -server server [-o yaml|json]
+  operation diff -f file [-app app -server server]  preview the changes of applying the file
+  operation apply -f file [-app app -server server] [-dry-run]
+`
+
+// ErrUsage is returned if the command line is invalid.
+var ErrUsage = errors.New("invalid usage")
+
+// ctl runs a command.
+type ctl struct {
+	tenant    string
+	stdout    io.Writer
+	samplers  sampler.SamplerServiceClient
+	operation operation.OperationServiceClient
+}
+
+// Run runs otelctl with args without the program name, results are written to stdout.
+func Run(ctx context.Context, args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("otelctl", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	var (
+		addr     = fs.String("addr", "", "grpc address of SamplerService and OperationService")
+		endpoint = fs.String("http", "", "http gateway endpoint, used instead of -addr")
+		tenant   = fs.String("tenant", "default", "tenant, sent as the x-tps-tenantid header")
+		timeout  = fs.Duration("timeout", 10*time.Second, "timeout of the command")
+	)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if fs.NArg() < 2 {
+		return ErrUsage
+	}
+	c := &ctl{tenant: *tenant, stdout: stdout}
+	switch {
+	case *endpoint != "":
+		hc := newHTTPClient(*endpoint, &http.Client{})
+		c.samplers, c.operation = hc, hc
+	case *addr != "":
+		cc, err := grpc.Dial(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			return fmt.Errorf("dial %s err: %w", *addr, err)
+		}
+		defer cc.Close()
+		c.samplers, c.operation = sampler.NewSamplerServiceClient(cc), operation.NewOperationServiceClient(cc)
+	default:
+		return fmt.Errorf("%w: -addr or -http is required", ErrUsage)
+	}
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+		TenantHeader: *tenant,
+	}))
+	return c.run(ctx, fs.Arg(0), fs.Arg(1), fs.Args()[2:])
+}
+
+func (c *ctl) run(ctx context.Context, group, cmd string, args []string) error {
+	switch group + " " + cmd {
+	case "sampler list":
+		return c.samplerList(ctx)
+	case "sampler add":
+		return c.samplerAdd(ctx, args)
+	case "sampler del":
+		return c.samplerDel(ctx, args)
+	case "sampler judge":
+		return c.samplerJudge(ctx, args)
+	case "operation get":
+		return c.operationGet(ctx, args)
+	case "operation diff":
+		return c.operationApply(ctx, args, true)
+	case "operation apply":
+		return c.operationApply(ctx, args, false)
+	default:
+		return fmt.Errorf("%w: unknown command %s %s", ErrUsage, group, cmd)
+	}
+}
+
+func (c *ctl) samplerList(ctx context.Context) error {
+	rsp, err := c.samplers.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	if err != nil {
+		return fmt.Errorf("GetSamplerV2 err: %w", err)
+	}
+	w := tabwriter.NewWriter(c.stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSAMPLED\tDEADLINE\tCOMMENT")
+	for _, kv := range rsp.GetAttributes() {
+		fmt.Fprintf(w, "%s\t%s\t%t\t%s\t%s\n", kv.GetKey(), kv.GetValue(), kv.GetSampled(),
+			formatDeadline(kv.GetDeadline()), kv.GetComment())
+	}
+	return w.Flush()
+}
+
+func (c *ctl) samplerAdd(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("sampler add", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	ttl := fs.Duration("ttl", 0, "time to live, never expire if 0")
+	comment := fs.String("comment", "", "comment")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if fs.NArg() < 2 {
+		return fmt.Errorf("%w: key and values are required", ErrUsage)
+	}
+	var deadline int64
+	if *ttl > 0 {
+		deadline = time.Now().Add(*ttl).Unix()
+	}
+	req := &sampler.SetSamplerV2Request{}
+	for _, v := range fs.Args()[1:] {
+		req.Attributes = append(req.Attributes, &sampler.KeyValue{
+			Key:      fs.Arg(0),
+			Value:    v,
+			Sampled:  true,
+			Deadline: deadline,
+			Comment:  *comment,
+		})
+	}
+	if _, err := c.samplers.SetSamplerV2(ctx, req); err != nil {
+		return fmt.Errorf("SetSamplerV2 err: %w", err)
+	}
+	fmt.Fprintf(c.stdout, "added %d values of %s, deadline %s\n", len(req.Attributes), fs.Arg(0),
+		formatDeadline(deadline))
+	return nil
+}
+
+func (c *ctl) samplerDel(ctx context.Context, args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("%w: key is required", ErrUsage)
+	}
+	key, values := args[0], args[1:]
+	if len(values) == 0 {
+		rsp, err := c.samplers.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+		if err != nil {
+			return fmt.Errorf("GetSamplerV2 err: %w", err)
+		}
+		for _, kv := range rsp.GetAttributes() {
+			if kv.GetKey() == key {
+				values = append(values, kv.GetValue())
+			}
+		}
+	}
+	for _, v := range values {
+		if _, err := c.samplers.DelSampler(ctx, &sampler.DelSamplerRequest{Key: key, Value: v}); err != nil {
+			return fmt.Errorf("DelSampler %s=%s err: %w", key, v, err)
+		}
+	}
+	fmt.Fprintf(c.stdout, "deleted %d values of %s\n", len(values), key)
+	return nil
+}
+
+func (c *ctl) samplerJudge(ctx context.Context, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%w: key and value are required", ErrUsage)
+	}
+	rsp, err := c.samplers.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: args[0], Value: args[1]})
+	if err != nil {
+		return fmt.Errorf("JudgeSampler err: %w", err)
+	}
+	fmt.Fprintf(c.stdout, "sampled: %t, deadline: %s\n", rsp.GetSampled(), formatDeadline(rsp.GetDeadline()))
+	return nil
+}
+
+func (c *ctl) getOperation(ctx context.Context, app, server string) (*operation.Operation, error) {
+	rsp, err := c.operation.GetOperation(ctx, &operation.GetOperationRequest{
+		Tenant: c.tenant,
+		App:    app,
+		Server: server,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("GetOperation err: %w", err)
+	}
+	return rsp.GetOperation(), nil
+}
+
+func (c *ctl) operationGet(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("operation get", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	app := fs.String("app", "", "app of the operation")
+	server := fs.String("server", "", "server of the operation")
+	format := fs.String("o", FormatYAML, "output format, yaml or json")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if *app == "" || *server == "" {
+		return fmt.Errorf("%w: -app and -server are required", ErrUsage)
+	}
+	op, err := c.getOperation(ctx, *app, *server)
+	if err != nil {
+		return err
+	}
+	if op == nil {
+		return fmt.Errorf("operation of %s.%s is not found", *app, *server)
+	}
+	data, err := MarshalOperation(op, *format)
+	if err != nil {
+		return err
+	}
+	_, err = c.stdout.Write(data)
+	return err
+}
+
+// operationApply prints the diff between the remote operation and the file, and sets it unless dryRun.
+func (c *ctl) operationApply(ctx context.Context, args []string, dryRun bool) error {
+	fs := flag.NewFlagSet("operation apply", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	file := fs.String("f", "", "operation file in YAML or protobuf JSON format")
+	app := fs.String("app", "", "app of the operation, default resource.app of the file")
+	server := fs.String("server", "", "server of the operation, default resource.server of the file")
+	fs.BoolVar(&dryRun, "dry-run", dryRun, "only print the diff")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if *file == "" {
+		return fmt.Errorf("%w: -f is required", ErrUsage)
+	}
+	data, err := os.ReadFile(filepath.Clean(*file))
+	if err != nil {
+		return err
+	}
+	op, err := UnmarshalOperation(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", *file, err)
+	}
+	if op.Resource == nil {
+		op.Resource = &operation.Resource{}
+	}
+	if *app != "" {
+		op.Resource.App = *app
+	}
+	if *server != "" {
+		op.Resource.Server = *server
+	}
+	if op.Resource.Tenant == "" {
+		op.Resource.Tenant = c.tenant
+	}
+	if op.Resource.App == "" || op.Resource.Server == "" {
+		return fmt.Errorf("%w: app and server are required", ErrUsage)
+	}
+
+	remote, err := c.getOperation(ctx, op.Resource.App, op.Resource.Server)
+	if err != nil {
+		return err
+	}
+	diff, err := diffOperation(remote, op)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Fprintln(c.stdout, "no changes")
+		return nil
+	}
+	fmt.Fprint(c.stdout, diff)
+	if dryRun {
+		return nil
+	}
+	if _, err := c.operation.SetOperation(ctx, &operation.SetOperationRequest{Operation: op}); err != nil {
+		return fmt.Errorf("SetOperation err: %w", err)
+	}
+	fmt.Fprintf(c.stdout, "applied operation of %s.%s\n", op.Resource.App, op.Resource.Server)
+	return nil
+}
+
+func diffOperation(remote, local *operation.Operation) (string, error) {
+	if proto.Equal(remote, local) {
+		return "", nil
+	}
+	var a []byte
+	if remote != nil {
+		var err error
+		if a, err = MarshalOperation(remote, FormatYAML); err != nil {
+			return "", err
+		}
+	}
+	b, err := MarshalOperation(local, FormatYAML)
+	if err != nil {
+		return "", err
+	}
+	return "--- remote\n+++ local\n" + Diff(string(a), string(b)), nil
+}
+
+func formatDeadline(deadline int64) string {
+	if deadline <= 0 {
+		return "-"
+	}
+	return time.Unix(deadline, 0).Format(time.RFC3339)
+}
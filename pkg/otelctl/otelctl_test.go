@@ -0,0 +1,180 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package otelctl
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/refserver"
+)
+
+// startServer starts a refserver in-process and returns the -addr and -http flags.
+func startServer(t *testing.T) (*refserver.Service, [][]string) {
+	s, err := refserver.New()
+	require.NoError(t, err)
+	grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, grpcLis, httpLis) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return s, [][]string{
+		{"-addr", grpcLis.Addr().String()},
+		{"-http", httpLis.Addr().String()},
+	}
+}
+
+func run(t *testing.T, flags []string, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := Run(context.Background(), append(append([]string{"-tenant", "tenant"}, flags...), args...), &out)
+	return out.String(), err
+}
+
+func tenantContext() context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+}
+
+func TestRun_Sampler(t *testing.T) {
+	s, modes := startServer(t)
+	for _, flags := range modes {
+		t.Run(flags[0], func(t *testing.T) {
+			_, err := run(t, flags, "sampler", "add", "-ttl", "1h", "-comment", "debug", "uid", "1", "2")
+			require.NoError(t, err)
+			_, err = run(t, flags, "sampler", "add", "openid", "x")
+			require.NoError(t, err)
+
+			rsp, err := s.GetSamplerV2(tenantContext(), &sampler.GetSamplerV2Request{})
+			require.NoError(t, err)
+			require.Len(t, rsp.GetAttributes(), 3)
+			assert.Equal(t, "debug", rsp.GetAttributes()[1].GetComment())
+			assert.Positive(t, rsp.GetAttributes()[1].GetDeadline())
+			assert.Zero(t, rsp.GetAttributes()[0].GetDeadline())
+
+			out, err := run(t, flags, "sampler", "list")
+			require.NoError(t, err)
+			lines := strings.Split(strings.TrimSpace(out), "\n")
+			require.Len(t, lines, 4)
+			assert.Regexp(t, `^KEY\s+VALUE\s+SAMPLED\s+DEADLINE\s+COMMENT$`, lines[0])
+			assert.Regexp(t, `^openid\s+x\s+true\s+-$`, strings.TrimSpace(lines[1]))
+			assert.Regexp(t, `^uid\s+1\s+true\s+\S+\s+debug$`, lines[2])
+
+			out, err = run(t, flags, "sampler", "judge", "uid", "1")
+			require.NoError(t, err)
+			assert.Contains(t, out, "sampled: true")
+			out, err = run(t, flags, "sampler", "judge", "uid", "3")
+			require.NoError(t, err)
+			assert.Equal(t, "sampled: false, deadline: -\n", out)
+
+			out, err = run(t, flags, "sampler", "del", "uid")
+			require.NoError(t, err)
+			assert.Equal(t, "deleted 2 values of uid\n", out)
+			_, err = run(t, flags, "sampler", "del", "openid", "x")
+			require.NoError(t, err)
+			rsp, err = s.GetSamplerV2(tenantContext(), &sampler.GetSamplerV2Request{})
+			require.NoError(t, err)
+			assert.Empty(t, rsp.GetAttributes())
+		})
+	}
+}
+
+func TestRun_Operation(t *testing.T) {
+	s, modes := startServer(t)
+	file := filepath.Join(t.TempDir(), "operation.yaml")
+	for i, flags := range modes {
+		t.Run(flags[0], func(t *testing.T) {
+			app := []string{"grpc", "http"}[i]
+			require.NoError(t, os.WriteFile(file, []byte(`
+resource:
+  app: `+app+`
+  server: greeter
+sampler:
+  fraction: 0.5
+`), 0644))
+
+			_, err := run(t, flags, "operation", "get", "-app", app, "-server", "greeter")
+			assert.Error(t, err)
+
+			out, err := run(t, flags, "operation", "apply", "-f", file, "-dry-run")
+			require.NoError(t, err)
+			assert.Contains(t, out, "+sampler:\n+    fraction: 0.5\n")
+			rsp, err := s.GetOperation(tenantContext(), &operation.GetOperationRequest{App: app, Server: "greeter"})
+			require.NoError(t, err)
+			assert.Nil(t, rsp.GetOperation())
+
+			out, err = run(t, flags, "operation", "apply", "-f", file)
+			require.NoError(t, err)
+			assert.Contains(t, out, "applied operation of "+app+".greeter")
+			out, err = run(t, flags, "operation", "diff", "-f", file)
+			require.NoError(t, err)
+			assert.Equal(t, "no changes\n", out)
+
+			out, err = run(t, flags, "operation", "get", "-app", app, "-server", "greeter")
+			require.NoError(t, err)
+			assert.Equal(t, "resource:\n    app: "+app+"\n    server: greeter\n    tenant: tenant\n"+
+				"sampler:\n    fraction: 0.5\n", out)
+			require.NoError(t, os.WriteFile(file, []byte(strings.ReplaceAll(out, "0.5", "0.1")), 0644))
+			out, err = run(t, flags, "operation", "diff", "-f", file)
+			require.NoError(t, err)
+			assert.Equal(t, "--- remote\n+++ local\n resource:\n     app: "+app+"\n     server: greeter\n"+
+				"     tenant: tenant\n sampler:\n-    fraction: 0.5\n+    fraction: 0.1\n", out)
+
+			out, err = run(t, flags, "operation", "get", "-app", app, "-server", "greeter", "-o", "json")
+			require.NoError(t, err)
+			op, err := UnmarshalOperation([]byte(out))
+			require.NoError(t, err)
+			assert.Equal(t, 0.5, op.GetSampler().GetFraction())
+		})
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	_, modes := startServer(t)
+	for _, args := range [][]string{
+		{},
+		{"sampler", "list"},
+		append(modes[0], "sampler"),
+		append(modes[0], "sampler", "unknown"),
+		append(modes[0], "sampler", "add", "uid"),
+		append(modes[0], "sampler", "judge", "uid"),
+		append(modes[0], "operation", "get", "-app", "app"),
+		append(modes[0], "operation", "apply"),
+		append(modes[0], "-unknown"),
+	} {
+		_, err := run(t, nil, args...)
+		assert.ErrorIs(t, err, ErrUsage, "%v", args)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	assert.Equal(t, "", Diff("a\n", "a\n"))
+	assert.Equal(t, "+a\n+b\n", Diff("", "a\nb\n"))
+	assert.Equal(t, " a\n-b\n+c\n d\n", Diff("a\nb\nd\n", "a\nc\nd\n"))
+}
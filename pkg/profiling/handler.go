@@ -0,0 +1,108 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package profiling
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime/pprof"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultProfileSeconds = 30
+	maxProfileSeconds     = 300
+	defaultTop            = 50
+)
+
+// filterLabels the labels filtering the samples by the query
+var filterLabels = []string{LabelTraceID, LabelSpanID, LabelRPCMethod, LabelSpanName}
+
+// CPUProfileHandler captures a CPU profile for the seconds of the query (default 30) and keeps the samples
+// whose labels match the query trace_id, span_id, rpc_method and span_name.
+// It downloads the filtered profile if the format query is pprof, otherwise it returns the CPU time
+// aggregated by the label of the by query (default rpc_method) as JSON, the top (default 50) values first.
+func CPUProfileHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		seconds, err := intQuery(q.Get("seconds"), defaultProfileSeconds)
+		if err != nil || seconds <= 0 || seconds > maxProfileSeconds {
+			http.Error(w, fmt.Sprintf("invalid seconds %q, must be in (0, %d]", q.Get("seconds"), maxProfileSeconds),
+				http.StatusBadRequest)
+			return
+		}
+		top, err := intQuery(q.Get("top"), defaultTop)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid top %q", q.Get("top")), http.StatusBadRequest)
+			return
+		}
+		by := q.Get("by")
+		if by == "" {
+			by = LabelRPCMethod
+		}
+		filter := make(map[string]string)
+		for _, k := range filterLabels {
+			if v := q.Get(k); v != "" {
+				filter[k] = v
+			}
+		}
+
+		var buf bytes.Buffer
+		if err := pprof.StartCPUProfile(&buf); err != nil {
+			http.Error(w, fmt.Sprintf("could not enable CPU profiling: %v", err), http.StatusInternalServerError)
+			return
+		}
+		select {
+		case <-time.After(time.Duration(seconds) * time.Second):
+		case <-r.Context().Done():
+		}
+		pprof.StopCPUProfile()
+
+		p, err := parseProfile(buf.Bytes())
+		if err != nil {
+			http.Error(w, fmt.Sprintf("parse profile: %v", err), http.StatusInternalServerError)
+			return
+		}
+		if q.Get("format") == "pprof" {
+			b, err := p.filter(filter)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+			_, _ = w.Write(b)
+			return
+		}
+		sum, err := p.aggregate(by, filter, top)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(sum)
+	})
+}
+
+func intQuery(v string, defaultValue int) (int, error) {
+	if v == "" {
+		return defaultValue, nil
+	}
+	return strconv.Atoi(v)
+}
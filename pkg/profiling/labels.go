@@ -0,0 +1,59 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package profiling correlates the pprof profiles with the traces by the goroutine labels of the active spans.
+package profiling
+
+import (
+	"context"
+	"runtime/pprof"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The pprof labels set on the goroutines handling a span
+const (
+	LabelTraceID   = "trace_id"
+	LabelSpanID    = "span_id"
+	LabelRPCMethod = "rpc_method"
+	LabelSpanName  = "span_name"
+)
+
+// enabled the goroutine labels are set if it is not 0, they cost a few allocations per span
+var enabled int32
+
+// SetEnabled sets whether to set the goroutine labels of the spans, default false
+func SetEnabled(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&enabled, v)
+}
+
+// Enabled reports whether the goroutine labels of the spans are set
+func Enabled() bool {
+	return atomic.LoadInt32(&enabled) != 0
+}
+
+// WithSpanLabels sets the trace_id and span_id of sc and the key-value pairs of labels on the current goroutine,
+// the labels of ctx are kept unless overridden. The goroutines started afterwards inherit them.
+// It returns the context carrying the labels and a func restoring the labels of ctx, which must be called
+// on the same goroutine when the span ends.
+func WithSpanLabels(ctx context.Context, sc trace.SpanContext, labels ...string) (context.Context, func()) {
+	kvs := append([]string{LabelTraceID, sc.TraceID().String(), LabelSpanID, sc.SpanID().String()}, labels...)
+	labelled := pprof.WithLabels(ctx, pprof.Labels(kvs...))
+	pprof.SetGoroutineLabels(labelled)
+	return labelled, func() { pprof.SetGoroutineLabels(ctx) }
+}
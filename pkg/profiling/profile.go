@@ -0,0 +1,301 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package profiling
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"sort"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of profile.proto, see https://github.com/google/pprof/blob/main/proto/profile.proto
+const (
+	profileSampleType  = 1
+	profileSample      = 2
+	profileStringTable = 6
+
+	valueTypeType = 1
+
+	sampleValue = 2
+	sampleLabel = 3
+
+	labelKey = 1
+	labelStr = 2
+)
+
+// field a top-level field of a profile kept in its wire format
+type field struct {
+	num protowire.Number
+	raw []byte
+}
+
+// sample of a profile with its string labels
+type sample struct {
+	values []int64
+	labels map[string]string
+}
+
+// profile a decoded pprof profile, only the sample types, samples and their string labels are decoded,
+// the other fields are kept as is.
+type profile struct {
+	fields      []field
+	strings     []string
+	sampleTypes []string
+	samples     []sample
+}
+
+// parseProfile decodes a gzipped or plain pprof profile
+func parseProfile(b []byte) (*profile, error) {
+	if len(b) > 1 && b[0] == 0x1f && b[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		if b, err = io.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	p := &profile{}
+	var sampleTypeIdx []int64
+	var rawSamples [][]byte
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		m := protowire.ConsumeFieldValue(num, typ, b[n:])
+		if m < 0 {
+			return nil, protowire.ParseError(m)
+		}
+		raw := b[:n+m]
+		value := b[n : n+m]
+		b = b[n+m:]
+		p.fields = append(p.fields, field{num: num, raw: raw})
+		switch {
+		case num == profileStringTable && typ == protowire.BytesType:
+			s, _ := protowire.ConsumeBytes(value)
+			p.strings = append(p.strings, string(s))
+		case num == profileSampleType && typ == protowire.BytesType:
+			msg, _ := protowire.ConsumeBytes(value)
+			idx, err := consumeInt(msg, valueTypeType)
+			if err != nil {
+				return nil, err
+			}
+			sampleTypeIdx = append(sampleTypeIdx, idx)
+		case num == profileSample && typ == protowire.BytesType:
+			msg, _ := protowire.ConsumeBytes(value)
+			rawSamples = append(rawSamples, msg)
+		}
+	}
+	for _, idx := range sampleTypeIdx {
+		p.sampleTypes = append(p.sampleTypes, p.str(idx))
+	}
+	for _, raw := range rawSamples {
+		s, err := p.parseSample(raw)
+		if err != nil {
+			return nil, err
+		}
+		p.samples = append(p.samples, s)
+	}
+	return p, nil
+}
+
+func (p *profile) str(idx int64) string {
+	if idx < 0 || idx >= int64(len(p.strings)) {
+		return ""
+	}
+	return p.strings[idx]
+}
+
+func (p *profile) parseSample(b []byte) (sample, error) {
+	s := sample{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return s, protowire.ParseError(n)
+		}
+		b = b[n:]
+		m := protowire.ConsumeFieldValue(num, typ, b)
+		if m < 0 {
+			return s, protowire.ParseError(m)
+		}
+		value := b[:m]
+		b = b[m:]
+		switch {
+		case num == sampleValue && typ == protowire.BytesType:
+			packed, _ := protowire.ConsumeBytes(value)
+			for len(packed) > 0 {
+				v, k := protowire.ConsumeVarint(packed)
+				if k < 0 {
+					return s, protowire.ParseError(k)
+				}
+				s.values = append(s.values, int64(v))
+				packed = packed[k:]
+			}
+		case num == sampleValue && typ == protowire.VarintType:
+			v, _ := protowire.ConsumeVarint(value)
+			s.values = append(s.values, int64(v))
+		case num == sampleLabel && typ == protowire.BytesType:
+			msg, _ := protowire.ConsumeBytes(value)
+			key, err := consumeInt(msg, labelKey)
+			if err != nil {
+				return s, err
+			}
+			str, err := consumeInt(msg, labelStr)
+			if err != nil {
+				return s, err
+			}
+			if str == 0 {
+				// numeric label
+				continue
+			}
+			if s.labels == nil {
+				s.labels = make(map[string]string)
+			}
+			s.labels[p.str(key)] = p.str(str)
+		}
+	}
+	return s, nil
+}
+
+// consumeInt returns the varint field num of the message b, 0 if absent
+func consumeInt(b []byte, num protowire.Number) (int64, error) {
+	var v int64
+	for len(b) > 0 {
+		n, typ, k := protowire.ConsumeTag(b)
+		if k < 0 {
+			return 0, protowire.ParseError(k)
+		}
+		b = b[k:]
+		m := protowire.ConsumeFieldValue(n, typ, b)
+		if m < 0 {
+			return 0, protowire.ParseError(m)
+		}
+		if n == num && typ == protowire.VarintType {
+			x, _ := protowire.ConsumeVarint(b[:m])
+			v = int64(x)
+		}
+		b = b[m:]
+	}
+	return v, nil
+}
+
+// valueIndex index of the sample value of type, the last one if absent
+func (p *profile) valueIndex(typ string) int {
+	for i, t := range p.sampleTypes {
+		if t == typ {
+			return i
+		}
+	}
+	return len(p.sampleTypes) - 1
+}
+
+// match reports whether the labels of s have all the labels of filter
+func (s sample) match(filter map[string]string) bool {
+	for k, v := range filter {
+		if s.labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// filter encodes the profile with only the samples matching the labels of filter, gzipped.
+func (p *profile) filter(filter map[string]string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	i := 0
+	for _, f := range p.fields {
+		if f.num == profileSample {
+			s := p.samples[i]
+			i++
+			if !s.match(filter) {
+				continue
+			}
+		}
+		if _, err := zw.Write(f.raw); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// LabelStat the samples of a label value
+type LabelStat struct {
+	Value   string `json:"value"`
+	Samples int64  `json:"samples"`
+	// CPUNanos cpu time of the samples in nanoseconds
+	CPUNanos int64  `json:"cpu_nanos"`
+	CPU      string `json:"cpu"`
+}
+
+// Summary the CPU time of a profile aggregated by a label
+type Summary struct {
+	By       string            `json:"by"`
+	Filter   map[string]string `json:"filter,omitempty"`
+	Samples  int64             `json:"samples"`
+	CPUNanos int64             `json:"cpu_nanos"`
+	CPU      string            `json:"cpu"`
+	// Stats the label values of the most cpu time first, the samples without the label have the empty value
+	Stats []LabelStat `json:"stats"`
+}
+
+// aggregate sums the samples matching filter by the label by, the top label values of the most cpu time are kept
+// if top is positive.
+func (p *profile) aggregate(by string, filter map[string]string, top int) (Summary, error) {
+	if len(p.sampleTypes) == 0 {
+		return Summary{}, errors.New("profile has no sample type")
+	}
+	countIdx, cpuIdx := p.valueIndex("samples"), p.valueIndex("cpu")
+	sum := Summary{By: by, Filter: filter}
+	stats := make(map[string]*LabelStat)
+	for _, s := range p.samples {
+		if !s.match(filter) || cpuIdx >= len(s.values) || countIdx >= len(s.values) {
+			continue
+		}
+		v := s.labels[by]
+		st, ok := stats[v]
+		if !ok {
+			st = &LabelStat{Value: v}
+			stats[v] = st
+		}
+		st.Samples += s.values[countIdx]
+		st.CPUNanos += s.values[cpuIdx]
+		sum.Samples += s.values[countIdx]
+		sum.CPUNanos += s.values[cpuIdx]
+	}
+	for _, st := range stats {
+		st.CPU = time.Duration(st.CPUNanos).String()
+		sum.Stats = append(sum.Stats, *st)
+	}
+	sort.Slice(sum.Stats, func(i, j int) bool {
+		if sum.Stats[i].CPUNanos != sum.Stats[j].CPUNanos {
+			return sum.Stats[i].CPUNanos > sum.Stats[j].CPUNanos
+		}
+		return sum.Stats[i].Value < sum.Stats[j].Value
+	})
+	if top > 0 && len(sum.Stats) > top {
+		sum.Stats = sum.Stats[:top]
+	}
+	sum.CPU = time.Duration(sum.CPUNanos).String()
+	return sum, nil
+}
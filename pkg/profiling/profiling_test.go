@@ -0,0 +1,145 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package profiling
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime/pprof"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestWithSpanLabels(t *testing.T) {
+	SetEnabled(true)
+	require.True(t, Enabled())
+	SetEnabled(false)
+	require.False(t, Enabled())
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: trace.TraceID{1}, SpanID: trace.SpanID{2}})
+	parent := pprof.WithLabels(context.Background(), pprof.Labels(LabelRPCMethod, "/trpc.app.server.Greeter/SayHello"))
+	ctx, restore := WithSpanLabels(parent, sc, LabelSpanName, "query")
+	defer restore()
+	traceID, _ := pprof.Label(ctx, LabelTraceID)
+	require.Equal(t, sc.TraceID().String(), traceID)
+	method, _ := pprof.Label(ctx, LabelRPCMethod)
+	require.Equal(t, "/trpc.app.server.Greeter/SayHello", method, "labels of the parent are kept")
+	name, _ := pprof.Label(ctx, LabelSpanName)
+	require.Equal(t, "query", name)
+}
+
+// testProfile encodes a CPU profile of samples, each is labels with the cpu nanoseconds
+func testProfile(samples []map[string]string, cpu []int64) []byte {
+	strings := []string{"", "samples", "count", "cpu", "nanoseconds"}
+	index := func(s string) uint64 {
+		for i, v := range strings {
+			if v == s {
+				return uint64(i)
+			}
+		}
+		strings = append(strings, s)
+		return uint64(len(strings) - 1)
+	}
+	var b []byte
+	for _, vt := range [][2]string{{"samples", "count"}, {"cpu", "nanoseconds"}} {
+		var msg []byte
+		msg = protowire.AppendTag(msg, valueTypeType, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, index(vt[0]))
+		msg = protowire.AppendTag(msg, 2, protowire.VarintType)
+		msg = protowire.AppendVarint(msg, index(vt[1]))
+		b = protowire.AppendTag(b, profileSampleType, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg)
+	}
+	for i, labels := range samples {
+		var msg, values []byte
+		values = protowire.AppendVarint(values, 1)
+		values = protowire.AppendVarint(values, uint64(cpu[i]))
+		msg = protowire.AppendTag(msg, sampleValue, protowire.BytesType)
+		msg = protowire.AppendBytes(msg, values)
+		for k, v := range labels {
+			var label []byte
+			label = protowire.AppendTag(label, labelKey, protowire.VarintType)
+			label = protowire.AppendVarint(label, index(k))
+			label = protowire.AppendTag(label, labelStr, protowire.VarintType)
+			label = protowire.AppendVarint(label, index(v))
+			msg = protowire.AppendTag(msg, sampleLabel, protowire.BytesType)
+			msg = protowire.AppendBytes(msg, label)
+		}
+		b = protowire.AppendTag(b, profileSample, protowire.BytesType)
+		b = protowire.AppendBytes(b, msg)
+	}
+	for _, s := range strings {
+		b = protowire.AppendTag(b, profileStringTable, protowire.BytesType)
+		b = protowire.AppendString(b, s)
+	}
+	return b
+}
+
+func TestProfile(t *testing.T) {
+	b := testProfile([]map[string]string{
+		{LabelRPCMethod: "/a", LabelTraceID: "t1"},
+		{LabelRPCMethod: "/a", LabelTraceID: "t2"},
+		{LabelRPCMethod: "/b", LabelTraceID: "t3"},
+		nil,
+	}, []int64{10e6, 20e6, 50e6, 5e6})
+	p, err := parseProfile(b)
+	require.NoError(t, err)
+	require.Equal(t, []string{"samples", "cpu"}, p.sampleTypes)
+	require.Len(t, p.samples, 4)
+
+	sum, err := p.aggregate(LabelRPCMethod, nil, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), sum.Samples)
+	require.Equal(t, int64(85e6), sum.CPUNanos)
+	require.Equal(t, []LabelStat{
+		{Value: "/b", Samples: 1, CPUNanos: 50e6, CPU: "50ms"},
+		{Value: "/a", Samples: 2, CPUNanos: 30e6, CPU: "30ms"},
+		{Value: "", Samples: 1, CPUNanos: 5e6, CPU: "5ms"},
+	}, sum.Stats)
+
+	sum, err = p.aggregate(LabelTraceID, map[string]string{LabelRPCMethod: "/a"}, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(30e6), sum.CPUNanos)
+	require.Equal(t, []LabelStat{{Value: "t2", Samples: 1, CPUNanos: 20e6, CPU: "20ms"}}, sum.Stats)
+
+	filtered, err := p.filter(map[string]string{LabelTraceID: "t3"})
+	require.NoError(t, err)
+	p, err = parseProfile(filtered)
+	require.NoError(t, err)
+	require.Len(t, p.samples, 1)
+	require.Equal(t, "/b", p.samples[0].labels[LabelRPCMethod])
+	require.Len(t, p.strings, 12, "the string table is kept")
+}
+
+func TestCPUProfileHandler(t *testing.T) {
+	w := httptest.NewRecorder()
+	CPUProfileHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/labels?seconds=0", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	CPUProfileHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/labels?seconds=1&top=x", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	CPUProfileHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/labels?seconds=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var sum Summary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &sum))
+	require.Equal(t, LabelRPCMethod, sum.By)
+}
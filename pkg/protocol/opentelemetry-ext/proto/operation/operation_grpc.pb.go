@@ -33,8 +33,9 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	OperationService_SetOperation_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/SetOperation"
-	OperationService_GetOperation_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/GetOperation"
+	OperationService_SetOperation_FullMethodName      = "/opentelemetry.ext.proto.operation.OperationService/SetOperation"
+	OperationService_GetOperation_FullMethodName      = "/opentelemetry.ext.proto.operation.OperationService/GetOperation"
+	OperationService_ReportApplyStatus_FullMethodName = "/opentelemetry.ext.proto.operation.OperationService/ReportApplyStatus"
 )
 
 // OperationServiceClient is the client API for OperationService service.
@@ -43,6 +44,7 @@ const (
 type OperationServiceClient interface {
 	SetOperation(ctx context.Context, in *SetOperationRequest, opts ...grpc.CallOption) (*SetOperationResponse, error)
 	GetOperation(ctx context.Context, in *GetOperationRequest, opts ...grpc.CallOption) (*GetOperationResponse, error)
+	ReportApplyStatus(ctx context.Context, in *ReportApplyStatusRequest, opts ...grpc.CallOption) (*ReportApplyStatusResponse, error)
 }
 
 type operationServiceClient struct {
@@ -71,12 +73,22 @@ func (c *operationServiceClient) GetOperation(ctx context.Context, in *GetOperat
 	return out, nil
 }
 
+func (c *operationServiceClient) ReportApplyStatus(ctx context.Context, in *ReportApplyStatusRequest, opts ...grpc.CallOption) (*ReportApplyStatusResponse, error) {
+	out := new(ReportApplyStatusResponse)
+	err := c.cc.Invoke(ctx, OperationService_ReportApplyStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OperationServiceServer is the server API for OperationService service.
 // All implementations must embed UnimplementedOperationServiceServer
 // for forward compatibility
 type OperationServiceServer interface {
 	SetOperation(context.Context, *SetOperationRequest) (*SetOperationResponse, error)
 	GetOperation(context.Context, *GetOperationRequest) (*GetOperationResponse, error)
+	ReportApplyStatus(context.Context, *ReportApplyStatusRequest) (*ReportApplyStatusResponse, error)
 	mustEmbedUnimplementedOperationServiceServer()
 }
 
@@ -90,6 +102,9 @@ func (UnimplementedOperationServiceServer) SetOperation(context.Context, *SetOpe
 func (UnimplementedOperationServiceServer) GetOperation(context.Context, *GetOperationRequest) (*GetOperationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetOperation not implemented")
 }
+func (UnimplementedOperationServiceServer) ReportApplyStatus(context.Context, *ReportApplyStatusRequest) (*ReportApplyStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReportApplyStatus not implemented")
+}
 func (UnimplementedOperationServiceServer) mustEmbedUnimplementedOperationServiceServer() {}
 
 // UnsafeOperationServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -139,6 +154,24 @@ func _OperationService_GetOperation_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OperationService_ReportApplyStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReportApplyStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OperationServiceServer).ReportApplyStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OperationService_ReportApplyStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OperationServiceServer).ReportApplyStatus(ctx, req.(*ReportApplyStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OperationService_ServiceDesc is the grpc.ServiceDesc for OperationService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,6 +187,10 @@ var OperationService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetOperation",
 			Handler:    _OperationService_GetOperation_Handler,
 		},
+		{
+			MethodName: "ReportApplyStatus",
+			Handler:    _OperationService_ReportApplyStatus_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "opentelemetry-ext/proto/operation/operation.proto",
@@ -0,0 +1,61 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package refserver
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// etagWriter buffers the response to compute its ETag.
+type etagWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *etagWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+// Write implements http.ResponseWriter.
+func (w *etagWriter) Write(b []byte) (int, error) {
+	return w.body.Write(b)
+}
+
+// withETag sets the ETag of the successful GET responses, and responds 304 if it matches If-None-Match.
+func withETag(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ew := &etagWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		if ew.status == http.StatusOK {
+			sum := sha256.Sum256(ew.body.Bytes())
+			etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(ew.body.Bytes())
+	})
+}
@@ -0,0 +1,117 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package refserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// snapshot is the persisted file, messages are in the protobuf JSON format.
+type snapshot struct {
+	Samplers   map[string][]json.RawMessage `json:"samplers"`
+	Operations []json.RawMessage            `json:"operations"`
+}
+
+// persistIf persists the state if changed, it must be called with mu held.
+func (s *Service) persistIf(changed bool) error {
+	if !changed {
+		return nil
+	}
+	return s.persist()
+}
+
+// persist writes the state to the file atomically, it must be called with mu held.
+func (s *Service) persist() error {
+	if s.path == "" {
+		return nil
+	}
+	snap := snapshot{Samplers: make(map[string][]json.RawMessage, len(s.samplers))}
+	for tenant, entries := range s.samplers {
+		for _, kv := range entries {
+			b, err := protojson.Marshal(kv)
+			if err != nil {
+				return err
+			}
+			snap.Samplers[tenant] = append(snap.Samplers[tenant], b)
+		}
+	}
+	for _, op := range s.operations {
+		b, err := protojson.Marshal(op)
+		if err != nil {
+			return err
+		}
+		snap.Operations = append(snap.Operations, b)
+	}
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("refserver: persist err: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("refserver: persist err: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("refserver: persist err: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("refserver: persist err: %w", err)
+	}
+	return nil
+}
+
+// load reads the state from the file, a nonexistent file is empty.
+func (s *Service) load() error {
+	data, err := os.ReadFile(filepath.Clean(s.path))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("refserver: load %s err: %w", s.path, err)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("refserver: load %s err: %w", s.path, err)
+	}
+	for tenant, entries := range snap.Samplers {
+		for _, b := range entries {
+			kv := &sampler.KeyValue{}
+			if err := protojson.Unmarshal(b, kv); err != nil {
+				return fmt.Errorf("refserver: load %s err: %w", s.path, err)
+			}
+			s.setEntry(tenant, kv)
+		}
+	}
+	for _, b := range snap.Operations {
+		op := &operation.Operation{}
+		if err := protojson.Unmarshal(b, op); err != nil {
+			return fmt.Errorf("refserver: load %s err: %w", s.path, err)
+		}
+		r := op.GetResource()
+		s.operations[operationKey{tenant: r.GetTenant(), app: r.GetApp(), server: r.GetServer()}] = op
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package refserver
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+// RegisterGRPC registers SamplerService and OperationService to gs.
+func (s *Service) RegisterGRPC(gs *grpc.Server) {
+	sampler.RegisterSamplerServiceServer(gs, s)
+	operation.RegisterOperationServiceServer(gs, s)
+}
+
+// HTTPHandler returns the grpc-gateway handler of SamplerService and OperationService,
+// the tenant is read from the X-Tps-TenantID header. GET responses have ETag and support If-None-Match.
+func (s *Service) HTTPHandler(ctx context.Context) (http.Handler, error) {
+	mux := runtime.NewServeMux(runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+		if strings.EqualFold(key, TenantHeader) {
+			return TenantHeader, true
+		}
+		return runtime.DefaultHeaderMatcher(key)
+	}))
+	if err := sampler.RegisterSamplerServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	if err := operation.RegisterOperationServiceHandlerServer(ctx, mux, s); err != nil {
+		return nil, err
+	}
+	return withETag(mux), nil
+}
+
+// Serve serves grpc on grpcLis and http on httpLis until ctx is done or any of them fails,
+// httpLis is optional.
+func (s *Service) Serve(ctx context.Context, grpcLis, httpLis net.Listener) error {
+	gs := grpc.NewServer()
+	s.RegisterGRPC(gs)
+	errCh := make(chan error, 2)
+	go func() { errCh <- gs.Serve(grpcLis) }()
+
+	var hs *http.Server
+	if httpLis != nil {
+		h, err := s.HTTPHandler(ctx)
+		if err != nil {
+			gs.Stop()
+			return err
+		}
+		hs = &http.Server{Handler: h}
+		go func() { errCh <- hs.Serve(httpLis) }()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-errCh:
+	}
+	if hs != nil {
+		_ = hs.Close()
+	}
+	gs.GracefulStop()
+	if errors.Is(err, http.ErrServerClosed) || errors.Is(err, grpc.ErrServerStopped) {
+		err = nil
+	}
+	return err
+}
@@ -0,0 +1,353 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package refserver is a reference implementation of SamplerService and OperationService,
+// it keeps the samplers and operations in memory with optional file persistence,
+// so that the dyeing sampler and the remote configuration can run locally or in CI.
+package refserver
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+)
+
+const (
+	// TenantHeader metadata key of the tenant, the same as the SDK clients
+	TenantHeader = "x-tps-tenantid"
+	// DefaultTenant tenant of requests without TenantHeader
+	DefaultTenant = "default"
+)
+
+var (
+	_ sampler.SamplerServiceServer     = (*Service)(nil)
+	_ operation.OperationServiceServer = (*Service)(nil)
+)
+
+// Service implements SamplerService and OperationService.
+//
+// Samplers are stored by tenant, each (key, value) is an entry of sampler.KeyValue.
+// Entries set by SetSampler are sampled and never expire, entries set by SetSamplerV2 expire at
+// the deadline (unix timestamp in seconds) if it is positive. GetSampler only returns the sampled entries.
+// Operations are stored by the tenant, app and server of Operation.Resource.
+type Service struct {
+	sampler.UnimplementedSamplerServiceServer
+	operation.UnimplementedOperationServiceServer
+
+	path string
+	now  func() time.Time
+
+	mu         sync.Mutex
+	samplers   map[string]map[samplerKey]*sampler.KeyValue // tenant => entries
+	operations map[operationKey]*operation.Operation
+	// statuses the last apply status of the instances, they are not persisted
+	statuses map[operationKey]map[string]*operation.ReportApplyStatusRequest
+}
+
+type samplerKey struct {
+	key   string
+	value string
+}
+
+type operationKey struct {
+	tenant string
+	app    string
+	server string
+}
+
+// Option Service option
+type Option func(*Service)
+
+// WithPersistFile persists the samplers and operations to path, they are loaded from it on New.
+func WithPersistFile(path string) Option {
+	return func(s *Service) {
+		s.path = path
+	}
+}
+
+// New creates a new Service.
+func New(opts ...Option) (*Service, error) {
+	s := &Service{
+		now:        time.Now,
+		samplers:   make(map[string]map[samplerKey]*sampler.KeyValue),
+		operations: make(map[operationKey]*operation.Operation),
+		statuses:   make(map[operationKey]map[string]*operation.ReportApplyStatusRequest),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.path != "" {
+		if err := s.load(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// tenant returns the tenant of the request.
+func tenant(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if v := md.Get(TenantHeader); len(v) > 0 && v[0] != "" {
+		return v[0]
+	}
+	return DefaultTenant
+}
+
+// entries returns the unexpired entries of the tenant, expired entries are deleted.
+// It must be called with mu held, and returns if any entry is deleted.
+func (s *Service) entries(tenant string) (map[samplerKey]*sampler.KeyValue, bool) {
+	entries := s.samplers[tenant]
+	now := s.now().Unix()
+	expired := false
+	for k, kv := range entries {
+		if kv.GetDeadline() > 0 && kv.GetDeadline() <= now {
+			delete(entries, k)
+			expired = true
+		}
+	}
+	return entries, expired
+}
+
+func (s *Service) setEntry(tenant string, kv *sampler.KeyValue) {
+	entries := s.samplers[tenant]
+	if entries == nil {
+		entries = make(map[samplerKey]*sampler.KeyValue)
+		s.samplers[tenant] = entries
+	}
+	entries[samplerKey{key: kv.GetKey(), value: kv.GetValue()}] = kv
+}
+
+// setEntries sets the validated entries of the tenant and persists them, the entries are rolled back
+// if persisting fails. It must be called with mu held.
+func (s *Service) setEntries(tenant string, kvs []*sampler.KeyValue) error {
+	prev := make(map[samplerKey]*sampler.KeyValue, len(kvs))
+	for _, kv := range kvs {
+		k := samplerKey{key: kv.GetKey(), value: kv.GetValue()}
+		if _, ok := prev[k]; !ok {
+			prev[k] = s.samplers[tenant][k]
+		}
+		s.setEntry(tenant, kv)
+	}
+	if err := s.persist(); err != nil {
+		entries := s.samplers[tenant]
+		for k, kv := range prev {
+			if kv == nil {
+				delete(entries, k)
+			} else {
+				entries[k] = kv
+			}
+		}
+		if len(entries) == 0 {
+			delete(s.samplers, tenant)
+		}
+		return err
+	}
+	return nil
+}
+
+// SetSampler implements sampler.SamplerServiceServer, values are added as sampled entries without deadline.
+func (s *Service) SetSampler(ctx context.Context, req *sampler.SetSamplerRequest) (*sampler.SetSamplerResponse, error) {
+	var kvs []*sampler.KeyValue
+	for _, attr := range req.GetAttributes() {
+		if attr.GetKey() == "" {
+			return nil, status.Error(codes.InvalidArgument, "key is required")
+		}
+		for _, v := range attr.GetValues() {
+			kvs = append(kvs, &sampler.KeyValue{Key: attr.GetKey(), Value: v, Sampled: true})
+		}
+	}
+	t := tenant(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &sampler.SetSamplerResponse{}, s.setEntries(t, kvs)
+}
+
+// GetSampler implements sampler.SamplerServiceServer, it returns the sampled entries grouped by key.
+func (s *Service) GetSampler(ctx context.Context, _ *sampler.GetSamplerRequest) (*sampler.GetSamplerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, expired := s.entries(tenant(ctx))
+	values := make(map[string][]string)
+	for k, kv := range entries {
+		if kv.GetSampled() {
+			values[k.key] = append(values[k.key], k.value)
+		}
+	}
+	rsp := &sampler.GetSamplerResponse{}
+	for key, vs := range values {
+		sort.Strings(vs)
+		rsp.Attributes = append(rsp.Attributes, &sampler.KeyValues{Key: key, Values: vs})
+	}
+	sort.Slice(rsp.Attributes, func(i, j int) bool { return rsp.Attributes[i].Key < rsp.Attributes[j].Key })
+	return rsp, s.persistIf(expired)
+}
+
+// DelSampler implements sampler.SamplerServiceServer, all values of the key are deleted if value is empty.
+func (s *Service) DelSampler(ctx context.Context, req *sampler.DelSamplerRequest) (*sampler.DelSamplerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries := s.samplers[tenant(ctx)]
+	deleted := make(map[samplerKey]*sampler.KeyValue)
+	for k, kv := range entries {
+		if k.key == req.GetKey() && (req.GetValue() == "" || k.value == req.GetValue()) {
+			deleted[k] = kv
+			delete(entries, k)
+		}
+	}
+	if err := s.persist(); err != nil {
+		for k, kv := range deleted {
+			entries[k] = kv
+		}
+		return nil, err
+	}
+	return &sampler.DelSamplerResponse{}, nil
+}
+
+// JudgeSampler implements sampler.SamplerServiceServer, unknown or expired entries are not sampled.
+func (s *Service) JudgeSampler(ctx context.Context,
+	req *sampler.JudgeSamplerRequest) (*sampler.JudgeSamplerResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, expired := s.entries(tenant(ctx))
+	rsp := &sampler.JudgeSamplerResponse{}
+	if kv, ok := entries[samplerKey{key: req.GetKey(), value: req.GetValue()}]; ok {
+		rsp.Sampled, rsp.Deadline = kv.GetSampled(), kv.GetDeadline()
+	}
+	return rsp, s.persistIf(expired)
+}
+
+// SetSamplerV2 implements sampler.SamplerServiceServer, entries are replaced by (key, value).
+func (s *Service) SetSamplerV2(ctx context.Context,
+	req *sampler.SetSamplerV2Request) (*sampler.SetSamplerV2Response, error) {
+	kvs := make([]*sampler.KeyValue, 0, len(req.GetAttributes()))
+	for _, kv := range req.GetAttributes() {
+		if kv.GetKey() == "" {
+			return nil, status.Error(codes.InvalidArgument, "key is required")
+		}
+		kvs = append(kvs, &sampler.KeyValue{
+			Key:      kv.GetKey(),
+			Value:    kv.GetValue(),
+			Sampled:  kv.GetSampled(),
+			Deadline: kv.GetDeadline(),
+			Comment:  kv.GetComment(),
+		})
+	}
+	t := tenant(ctx)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &sampler.SetSamplerV2Response{}, s.setEntries(t, kvs)
+}
+
+// GetSamplerV2 implements sampler.SamplerServiceServer, it returns all unexpired entries.
+func (s *Service) GetSamplerV2(ctx context.Context,
+	_ *sampler.GetSamplerV2Request) (*sampler.GetSamplerV2Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, expired := s.entries(tenant(ctx))
+	rsp := &sampler.GetSamplerV2Response{}
+	for _, kv := range entries {
+		rsp.Attributes = append(rsp.Attributes, &sampler.KeyValue{
+			Key:      kv.GetKey(),
+			Value:    kv.GetValue(),
+			Sampled:  kv.GetSampled(),
+			Deadline: kv.GetDeadline(),
+			Comment:  kv.GetComment(),
+		})
+	}
+	sort.Slice(rsp.Attributes, func(i, j int) bool {
+		a, b := rsp.Attributes[i], rsp.Attributes[j]
+		return a.Key < b.Key || (a.Key == b.Key && a.Value < b.Value)
+	})
+	return rsp, s.persistIf(expired)
+}
+
+// SetOperation implements operation.OperationServiceServer, the tenant of the resource defaults to
+// the tenant of the request.
+func (s *Service) SetOperation(ctx context.Context,
+	req *operation.SetOperationRequest) (*operation.SetOperationResponse, error) {
+	op := req.GetOperation()
+	if op.GetResource().GetApp() == "" || op.GetResource().GetServer() == "" {
+		return nil, status.Error(codes.InvalidArgument, "resource app and server are required")
+	}
+	key := operationKey{tenant: op.GetResource().GetTenant(), app: op.GetResource().GetApp(),
+		server: op.GetResource().GetServer()}
+	if key.tenant == "" {
+		key.tenant = tenant(ctx)
+		op = proto.Clone(op).(*operation.Operation)
+		op.Resource.Tenant = key.tenant
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prev, ok := s.operations[key]
+	s.operations[key] = op
+	if err := s.persist(); err != nil {
+		if ok {
+			s.operations[key] = prev
+		} else {
+			delete(s.operations, key)
+		}
+		return nil, err
+	}
+	return &operation.SetOperationResponse{}, nil
+}
+
+// GetOperation implements operation.OperationServiceServer, the operation is nil if it is not set.
+func (s *Service) GetOperation(ctx context.Context,
+	req *operation.GetOperationRequest) (*operation.GetOperationResponse, error) {
+	key := operationKey{tenant: req.GetTenant(), app: req.GetApp(), server: req.GetServer()}
+	if key.tenant == "" {
+		key.tenant = tenant(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &operation.GetOperationResponse{Operation: s.operations[key]}, nil
+}
+
+// ReportApplyStatus implements operation.OperationServiceServer, the last status of each instance is kept.
+func (s *Service) ReportApplyStatus(ctx context.Context,
+	req *operation.ReportApplyStatusRequest) (*operation.ReportApplyStatusResponse, error) {
+	key := operationKey{tenant: req.GetTenant(), app: req.GetApp(), server: req.GetServer()}
+	if key.tenant == "" {
+		key.tenant = tenant(ctx)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := s.statuses[key]
+	if statuses == nil {
+		statuses = make(map[string]*operation.ReportApplyStatusRequest)
+		s.statuses[key] = statuses
+	}
+	statuses[req.GetInstance()] = req
+	return &operation.ReportApplyStatusResponse{}, nil
+}
+
+// ApplyStatuses returns the last apply status of the instances of the server, sorted by instance.
+func (s *Service) ApplyStatuses(tenant, app, server string) []*operation.ReportApplyStatusRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var statuses []*operation.ReportApplyStatusRequest
+	for _, st := range s.statuses[operationKey{tenant: tenant, app: app, server: server}] {
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].GetInstance() < statuses[j].GetInstance() })
+	return statuses
+}
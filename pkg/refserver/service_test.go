@@ -0,0 +1,394 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package refserver
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/sampler"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/remote"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/trace"
+)
+
+// startServer starts s in-process and returns the grpc and http addresses.
+func startServer(t *testing.T, s *Service) (string, string) {
+	grpcLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	httpLis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- s.Serve(ctx, grpcLis, httpLis) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done)
+	})
+	return grpcLis.Addr().String(), httpLis.Addr().String()
+}
+
+func dial(t *testing.T, addr string) *grpc.ClientConn {
+	cc, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+	return cc
+}
+
+func tenantContext(tenant string) context.Context {
+	return metadata.NewOutgoingContext(context.Background(), metadata.Pairs(TenantHeader, tenant))
+}
+
+func TestService_Sampler(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	grpcAddr, _ := startServer(t, s)
+	client := sampler.NewSamplerServiceClient(dial(t, grpcAddr))
+
+	ctx := tenantContext("tenant")
+	_, err = client.SetSampler(ctx, &sampler.SetSamplerRequest{
+		Attributes: []*sampler.KeyValues{{Key: "uid", Values: []string{"123"}}}})
+	require.NoError(t, err)
+
+	ws := trace.NewSampler("tenant", trace.SamplerConfig{
+		SamplerServiceAddr: grpcAddr,
+		SyncInterval:       10 * time.Millisecond,
+	})
+	params := sdktrace.SamplingParameters{
+		ParentContext: context.Background(),
+		Attributes:    []attribute.KeyValue{attribute.String("uid", "123")},
+	}
+	assert.Eventually(t, func() bool {
+		return ws.ShouldSample(params).Decision == sdktrace.RecordAndSample
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// other tenants are isolated
+	rsp, err := client.GetSampler(tenantContext("other"), &sampler.GetSamplerRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, rsp.GetAttributes())
+
+	_, err = client.DelSampler(ctx, &sampler.DelSamplerRequest{Key: "uid", Value: "123"})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return ws.ShouldSample(params).Decision == sdktrace.Drop
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestService_SamplerV2Deadline(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s, err := New()
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Deadline: 1100, Comment: "debug"},
+		{Key: "uid", Value: "2", Sampled: false},
+		{Key: "uid", Value: "3", Sampled: true},
+	}})
+	require.NoError(t, err)
+
+	judge, err := s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "1"})
+	require.NoError(t, err)
+	assert.True(t, judge.GetSampled())
+	assert.Equal(t, int64(1100), judge.GetDeadline())
+	judge, err = s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "2"})
+	require.NoError(t, err)
+	assert.False(t, judge.GetSampled())
+
+	v1, err := s.GetSampler(ctx, &sampler.GetSamplerRequest{})
+	require.NoError(t, err)
+	require.Len(t, v1.GetAttributes(), 1)
+	assert.Equal(t, []string{"1", "3"}, v1.GetAttributes()[0].GetValues())
+
+	now = time.Unix(1100, 0)
+	judge, err = s.JudgeSampler(ctx, &sampler.JudgeSamplerRequest{Key: "uid", Value: "1"})
+	require.NoError(t, err)
+	assert.False(t, judge.GetSampled())
+	v2, err := s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	require.NoError(t, err)
+	require.Len(t, v2.GetAttributes(), 2)
+	assert.Equal(t, "2", v2.GetAttributes()[0].GetValue())
+	assert.Equal(t, "3", v2.GetAttributes()[1].GetValue())
+
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{{Value: "1"}}})
+	assert.Error(t, err)
+}
+
+func TestService_RemoteConfigurator(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	grpcAddr, _ := startServer(t, s)
+	client := operation.NewOperationServiceClient(dial(t, grpcAddr))
+
+	_, err = client.SetOperation(tenantContext("tenant"), &operation.SetOperationRequest{
+		Operation: &operation.Operation{
+			Resource: &operation.Resource{App: "app", Server: "server"},
+			Sampler:  &operation.Sampler{Fraction: 0.5},
+		}})
+	require.NoError(t, err)
+	_, err = client.SetOperation(tenantContext("tenant"), &operation.SetOperationRequest{
+		Operation: &operation.Operation{Resource: &operation.Resource{App: "app"}}})
+	assert.Error(t, err)
+
+	applied := make(chan *operation.Operation, 1)
+	rc := remote.NewRemoteConfigurator(grpcAddr, 10*time.Millisecond, "tenant", "app", "server")
+	rc.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		if op != nil {
+			select {
+			case applied <- op:
+			default:
+			}
+		}
+		return nil
+	})
+	select {
+	case op := <-applied:
+		assert.Equal(t, 0.5, op.GetSampler().GetFraction())
+		assert.Equal(t, "tenant", op.GetResource().GetTenant())
+	case <-time.After(5 * time.Second):
+		t.Fatal("operation is not applied")
+	}
+}
+
+func TestService_Persist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "refserver.json")
+	s, err := New(WithPersistFile(path))
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Comment: "debug"},
+	}})
+	require.NoError(t, err)
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Resource: &operation.Resource{App: "app", Server: "server"},
+		Sampler:  &operation.Sampler{Fraction: 0.1},
+	}})
+	require.NoError(t, err)
+
+	s, err = New(WithPersistFile(path))
+	require.NoError(t, err)
+	v2, err := s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+	require.NoError(t, err)
+	require.Len(t, v2.GetAttributes(), 1)
+	assert.Equal(t, "debug", v2.GetAttributes()[0].GetComment())
+	op, err := s.GetOperation(ctx, &operation.GetOperationRequest{Tenant: "tenant", App: "app", Server: "server"})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, op.GetOperation().GetSampler().GetFraction())
+
+	_, err = New(WithPersistFile(t.TempDir()))
+	assert.Error(t, err)
+}
+
+func TestService_PersistFailure(t *testing.T) {
+	dir := t.TempDir()
+	s, err := New(WithPersistFile(filepath.Join(dir, "refserver.json")))
+	require.NoError(t, err)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Comment: "before"},
+	}})
+	require.NoError(t, err)
+	assertEntries := func() {
+		v2, err := s.GetSamplerV2(ctx, &sampler.GetSamplerV2Request{})
+		require.NoError(t, err)
+		require.Len(t, v2.GetAttributes(), 1)
+		assert.Equal(t, "before", v2.GetAttributes()[0].GetComment())
+	}
+
+	// invalid entries are rejected before any change
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Comment: "after"}, {Value: "2"},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = s.SetSampler(ctx, &sampler.SetSamplerRequest{Attributes: []*sampler.KeyValues{
+		{Key: "uid", Values: []string{"2"}}, {Values: []string{"3"}},
+	}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assertEntries()
+
+	// changes are rolled back if persisting fails
+	s.path = filepath.Join(dir, "nonexistent", "refserver.json")
+	_, err = s.SetSamplerV2(ctx, &sampler.SetSamplerV2Request{Attributes: []*sampler.KeyValue{
+		{Key: "uid", Value: "1", Sampled: true, Comment: "after"}, {Key: "uid", Value: "2"},
+	}})
+	assert.Error(t, err)
+	_, err = s.SetSampler(context.Background(), &sampler.SetSamplerRequest{Attributes: []*sampler.KeyValues{
+		{Key: "uid", Values: []string{"2"}},
+	}})
+	assert.Error(t, err)
+	_, err = s.DelSampler(ctx, &sampler.DelSamplerRequest{Key: "uid"})
+	assert.Error(t, err)
+	assertEntries()
+	s.mu.Lock()
+	assert.NotContains(t, s.samplers, DefaultTenant)
+	s.mu.Unlock()
+
+	setOperation := func(app string, fraction float64) error {
+		_, err := s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+			Resource: &operation.Resource{App: app, Server: "server"},
+			Sampler:  &operation.Sampler{Fraction: fraction},
+		}})
+		return err
+	}
+	getOperation := func(app string) *operation.Operation {
+		rsp, err := s.GetOperation(ctx, &operation.GetOperationRequest{App: app, Server: "server"})
+		require.NoError(t, err)
+		return rsp.GetOperation()
+	}
+	s.path = filepath.Join(dir, "refserver.json")
+	require.NoError(t, setOperation("app", 0.1))
+	s.path = filepath.Join(dir, "nonexistent", "refserver.json")
+	assert.Error(t, setOperation("app", 0.2))
+	assert.Equal(t, 0.1, getOperation("app").GetSampler().GetFraction())
+	assert.Error(t, setOperation("other", 0.2))
+	assert.Nil(t, getOperation("other"))
+}
+
+func TestService_HTTPGateway(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	_, httpAddr := startServer(t, s)
+	do := func(method, path, body string) (int, map[string]interface{}) {
+		req, err := http.NewRequest(method, "http://"+httpAddr+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("X-Tps-TenantID", "tenant")
+		rsp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer rsp.Body.Close()
+		data, err := io.ReadAll(rsp.Body)
+		require.NoError(t, err)
+		var m map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &m), string(data))
+		return rsp.StatusCode, m
+	}
+
+	code, _ := do(http.MethodPost, "/api/v2/sampler",
+		`{"attributes":[{"key":"uid","value":"1","sampled":true,"deadline":"4102444800"}]}`)
+	require.Equal(t, http.StatusOK, code)
+	code, m := do(http.MethodGet, "/api/sampler/judge/uid/1", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, true, m["sampled"])
+	assert.Equal(t, "4102444800", m["deadline"])
+
+	code, m = do(http.MethodGet, "/api/sampler", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Len(t, m["attributes"], 1)
+
+	code, _ = do(http.MethodDelete, "/api/sampler/uid/1", "")
+	require.Equal(t, http.StatusOK, code)
+	_, m = do(http.MethodGet, "/api/sampler/judge/uid/1", "")
+	assert.Equal(t, false, m["sampled"])
+
+	code, _ = do(http.MethodPost, "/api/operation",
+		`{"operation":{"resource":{"app":"app","server":"server"},"sampler":{"fraction":0.2}}}`)
+	require.Equal(t, http.StatusOK, code)
+	code, m = do(http.MethodGet, "/api/operation/tenant/tenant/app/app/server/server", "")
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, 0.2, m["operation"].(map[string]interface{})["sampler"].(map[string]interface{})["fraction"])
+}
+
+func TestService_HTTPConfigurator(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	_, httpAddr := startServer(t, s)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Resource: &operation.Resource{App: "app", Server: "server"},
+		Sampler:  &operation.Sampler{Fraction: 0.5},
+	}})
+	require.NoError(t, err)
+
+	applied := make(chan *operation.Operation, 16)
+	rc := remote.NewHTTPConfigurator(httpAddr, 10*time.Millisecond, "tenant", "app", "server")
+	rc.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		applied <- op
+		return nil
+	})
+	receive := func() *operation.Operation {
+		select {
+		case op := <-applied:
+			return op
+		case <-time.After(5 * time.Second):
+			t.Fatal("operation is not applied")
+			return nil
+		}
+	}
+	assert.Equal(t, 0.5, receive().GetSampler().GetFraction())
+	// not modified
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, applied)
+
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Resource: &operation.Resource{App: "app", Server: "server"},
+		Sampler:  &operation.Sampler{Fraction: 0.1},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 0.1, receive().GetSampler().GetFraction())
+}
+
+func TestService_ReportApplyStatus(t *testing.T) {
+	s, err := New()
+	require.NoError(t, err)
+	grpcAddr, _ := startServer(t, s)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TenantHeader, "tenant"))
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Version:  "v1",
+		Resource: &operation.Resource{App: "app", Server: "server"},
+		Sampler:  &operation.Sampler{Fraction: 0.5},
+	}})
+	require.NoError(t, err)
+
+	rc := remote.NewRemoteConfigurator(grpcAddr, 10*time.Millisecond, "tenant", "app", "server",
+		remote.WithReportStatus(true), remote.WithInstance("127.0.0.1"))
+	assert.Eventually(t, func() bool { return len(s.ApplyStatuses("tenant", "app", "server")) == 1 },
+		5*time.Second, 10*time.Millisecond)
+	st := s.ApplyStatuses("tenant", "app", "server")[0]
+	assert.Equal(t, "127.0.0.1", st.GetInstance())
+	assert.Equal(t, "v1", st.GetVersion())
+	assert.True(t, st.GetSuccess())
+	assert.Equal(t, "v1", st.GetAppliedVersion())
+
+	_, err = s.SetOperation(ctx, &operation.SetOperationRequest{Operation: &operation.Operation{
+		Version:  "v2",
+		Resource: &operation.Resource{App: "app", Server: "server"},
+		Sampler:  &operation.Sampler{Fraction: 2},
+	}})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return s.ApplyStatuses("tenant", "app", "server")[0].GetVersion() == "v2"
+	}, 5*time.Second, 10*time.Millisecond)
+	st = s.ApplyStatuses("tenant", "app", "server")[0]
+	assert.False(t, st.GetSuccess())
+	assert.Contains(t, st.GetError(), "sampler fraction")
+	assert.Equal(t, "v1", st.GetAppliedVersion())
+	assert.Equal(t, "v1", rc.(remote.StatusReporter).Status().Version)
+}
@@ -0,0 +1,291 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package runtime
+
+import (
+	"log"
+	"math"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// RoundingFloor rounds the cpu quota down, e.g. 2.5 cores -> 2, default
+	RoundingFloor = "floor"
+	// RoundingCeil rounds the cpu quota up, e.g. 2.5 cores -> 3
+	RoundingCeil = "ceil"
+	// RoundingNearest rounds the cpu quota to the nearest integer, e.g. 2.5 cores -> 3, 2.4 cores -> 2
+	RoundingNearest = "nearest"
+
+	envGOMAXPROCS = "GOMAXPROCS"
+	envGOMEMLIMIT = "GOMEMLIMIT"
+)
+
+// AutoTuneConfig config of setting GOMAXPROCS and the Go soft memory limit from the container quotas.
+type AutoTuneConfig struct {
+	// GOMAXPROCS sets GOMAXPROCS from the cpu quota, it is ignored if the GOMAXPROCS env is set.
+	GOMAXPROCS bool `yaml:"gomaxprocs"`
+	// MinGOMAXPROCS minimum GOMAXPROCS, default 1
+	MinGOMAXPROCS int `yaml:"min_gomaxprocs"`
+	// Rounding how to round the cpu quota: floor/ceil/nearest, default floor
+	Rounding string `yaml:"rounding"`
+	// MemoryLimit sets debug.SetMemoryLimit from the memory quota (go1.19+),
+	// it is ignored if the GOMEMLIMIT env is set.
+	MemoryLimit bool `yaml:"memory_limit"`
+	// MemoryLimitRatio the soft memory limit is MemoryLimitRatio * memory quota, default 0.9
+	MemoryLimitRatio float64 `yaml:"memory_limit_ratio"`
+	// Interval interval of re-checking the quotas for in-place resizes, default 1m, negative disables re-checking
+	Interval time.Duration `yaml:"interval"`
+}
+
+// Enabled returns if any of the auto tuning is enabled.
+func (c AutoTuneConfig) Enabled() bool {
+	return c.GOMAXPROCS || c.MemoryLimit
+}
+
+func (c AutoTuneConfig) withDefaults() AutoTuneConfig {
+	if c.MinGOMAXPROCS <= 0 {
+		c.MinGOMAXPROCS = 1
+	}
+	if c.Rounding == "" {
+		c.Rounding = RoundingFloor
+	}
+	if c.MemoryLimitRatio <= 0 || c.MemoryLimitRatio > 1 {
+		c.MemoryLimitRatio = 0.9
+	}
+	if c.Interval == 0 {
+		c.Interval = time.Minute
+	}
+	return c
+}
+
+// AutoTuneStatus the applied values of auto tuning.
+type AutoTuneStatus struct {
+	// GOMAXPROCS applied GOMAXPROCS, 0 means not tuned
+	GOMAXPROCS int `json:"gomaxprocs"`
+	// CPUQuota cpu quota of the container, -1 means not limited
+	CPUQuota float64 `json:"cpu_quota"`
+	// MemoryLimit applied soft memory limit in bytes, 0 means not tuned
+	MemoryLimit int64 `json:"memory_limit"`
+	// MemoryQuota memory quota of the container in bytes, -1 means not limited
+	MemoryQuota int64 `json:"memory_quota"`
+	// UpdatedAt last time the values are changed
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// autoTuner tunes GOMAXPROCS and the soft memory limit, functions are replaced in tests.
+type autoTuner struct {
+	cfg AutoTuneConfig
+
+	cpuQuota       func() (float64, bool, error)
+	memoryQuota    func() (int64, bool, error)
+	setGOMAXPROCS  func(int) int
+	setMemoryLimit func(int64) int64
+	getenv         func(string) string
+	now            func() time.Time
+
+	// initial values before tuning, restored when the quota is removed or the tuner is stopped
+	initGOMAXPROCS  int
+	initMemoryLimit int64
+
+	mu      sync.Mutex
+	status  AutoTuneStatus
+	stopped bool
+}
+
+func newAutoTuner(cfg AutoTuneConfig) *autoTuner {
+	return &autoTuner{
+		cfg:            cfg.withDefaults(),
+		cpuQuota:       containerCPUQuota,
+		memoryQuota:    containerMemoryQuota,
+		setGOMAXPROCS:  runtime.GOMAXPROCS,
+		setMemoryLimit: setMemoryLimit,
+		getenv:         os.Getenv,
+		now:            time.Now,
+	}
+}
+
+func (t *autoTuner) init() {
+	t.initGOMAXPROCS = t.setGOMAXPROCS(0)
+	t.initMemoryLimit = t.setMemoryLimit(-1)
+	t.status = AutoTuneStatus{CPUQuota: -1, MemoryQuota: -1}
+}
+
+// tune checks the quotas and applies the changes.
+func (t *autoTuner) tune() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stopped {
+		return
+	}
+	changed := false
+	if t.cfg.GOMAXPROCS && t.getenv(envGOMAXPROCS) == "" {
+		changed = t.tuneGOMAXPROCS() || changed
+	}
+	if t.cfg.MemoryLimit && t.getenv(envGOMEMLIMIT) == "" {
+		changed = t.tuneMemoryLimit() || changed
+	}
+	if changed {
+		t.status.UpdatedAt = t.now()
+	}
+}
+
+func (t *autoTuner) tuneGOMAXPROCS() bool {
+	quota, defined, err := t.cpuQuota()
+	if err != nil {
+		log.Printf("opentelemetry: auto tune GOMAXPROCS read cpu quota err: %v", err)
+		return false
+	}
+	procs := t.initGOMAXPROCS
+	if defined {
+		procs = roundCPUQuota(quota, t.cfg.Rounding)
+		if procs < t.cfg.MinGOMAXPROCS {
+			procs = t.cfg.MinGOMAXPROCS
+		}
+	} else {
+		quota = -1
+	}
+	t.status.CPUQuota = quota
+	if procs == t.setGOMAXPROCS(0) {
+		return false
+	}
+	prev := t.setGOMAXPROCS(procs)
+	t.status.GOMAXPROCS = procs
+	log.Printf("opentelemetry: auto tune GOMAXPROCS %d -> %d, cpu quota: %v", prev, procs, quota)
+	return true
+}
+
+func (t *autoTuner) tuneMemoryLimit() bool {
+	quota, defined, err := t.memoryQuota()
+	if err != nil {
+		log.Printf("opentelemetry: auto tune memory limit read memory quota err: %v", err)
+		return false
+	}
+	limit := t.initMemoryLimit
+	if defined {
+		limit = int64(float64(quota) * t.cfg.MemoryLimitRatio)
+	} else {
+		quota = -1
+	}
+	t.status.MemoryQuota = quota
+	if limit == t.setMemoryLimit(-1) {
+		return false
+	}
+	prev := t.setMemoryLimit(limit)
+	t.status.MemoryLimit = limit
+	log.Printf("opentelemetry: auto tune memory limit %d -> %d, memory quota: %d", prev, limit, quota)
+	return true
+}
+
+// restore stops tuning and sets back the initial values if they are still the ones applied by the tuner,
+// so the next tuner starts from the process defaults instead of the tuned values.
+func (t *autoTuner) restore() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped = true
+	restored := false
+	if procs := t.status.GOMAXPROCS; procs != 0 && procs != t.initGOMAXPROCS && t.setGOMAXPROCS(0) == procs {
+		prev := t.setGOMAXPROCS(t.initGOMAXPROCS)
+		log.Printf("opentelemetry: auto tune restore GOMAXPROCS %d -> %d", prev, t.initGOMAXPROCS)
+		restored = true
+	}
+	if limit := t.status.MemoryLimit; limit != 0 && limit != t.initMemoryLimit && t.setMemoryLimit(-1) == limit {
+		prev := t.setMemoryLimit(t.initMemoryLimit)
+		log.Printf("opentelemetry: auto tune restore memory limit %d -> %d", prev, t.initMemoryLimit)
+		restored = true
+	}
+	t.status.GOMAXPROCS, t.status.MemoryLimit = 0, 0
+	if restored {
+		t.status.UpdatedAt = t.now()
+	}
+}
+
+func (t *autoTuner) getStatus() AutoTuneStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+func roundCPUQuota(quota float64, rounding string) int {
+	switch rounding {
+	case RoundingCeil:
+		return int(math.Ceil(quota))
+	case RoundingNearest:
+		return int(math.Round(quota))
+	default:
+		return int(math.Floor(quota))
+	}
+}
+
+var (
+	defaultAutoTunerMu sync.Mutex
+	defaultAutoTuner   *autoTuner
+)
+
+// AutoTune sets GOMAXPROCS and the Go soft memory limit from the container quotas according to cfg,
+// and re-checks the quotas periodically for in-place resizes until stop is called.
+// stop restores the values before tuning, so AutoTune can be called again after stop.
+// Values set by the GOMAXPROCS and GOMEMLIMIT env are respected.
+func AutoTune(cfg AutoTuneConfig) (stop func()) {
+	t := newAutoTuner(cfg)
+	t.init()
+	t.tune()
+	defaultAutoTunerMu.Lock()
+	defaultAutoTuner = t
+	defaultAutoTunerMu.Unlock()
+
+	done := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			close(done)
+			t.restore()
+		})
+	}
+	if t.cfg.Interval < 0 {
+		return stop
+	}
+	go func() {
+		ticker := time.NewTicker(t.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.tune()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return stop
+}
+
+// GetAutoTuneStatus returns the applied values of the last AutoTune,
+// ok is false if AutoTune is not called.
+func GetAutoTuneStatus() (status AutoTuneStatus, ok bool) {
+	defaultAutoTunerMu.Lock()
+	t := defaultAutoTuner
+	defaultAutoTunerMu.Unlock()
+	if t == nil {
+		return AutoTuneStatus{}, false
+	}
+	return t.getStatus(), true
+}
+
+// MemoryLimit returns the Go soft memory limit in bytes, it is math.MaxInt64 if not limited or before go1.19.
+func MemoryLimit() int64 {
+	return setMemoryLimit(-1)
+}
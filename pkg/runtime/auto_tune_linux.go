@@ -0,0 +1,35 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package runtime
+
+// containerCPUQuota returns the cpu quota of the cgroup, defined is false if not limited.
+func containerCPUQuota() (float64, bool, error) {
+	cg, err := CurrentCGroup()
+	if err != nil {
+		return -1, false, err
+	}
+	return cg.CPUQuota()
+}
+
+// containerMemoryQuota returns the memory quota of the cgroup, defined is false if not limited.
+func containerMemoryQuota() (int64, bool, error) {
+	cg, err := CurrentCGroup()
+	if err != nil {
+		return -1, false, err
+	}
+	return cg.MemoryQuota()
+}
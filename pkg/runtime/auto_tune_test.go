@@ -0,0 +1,159 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package runtime
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeQuotas struct {
+	cpu         float64
+	memory      int64
+	procs       int
+	memoryLimit int64
+	env         map[string]string
+}
+
+func newTestAutoTuner(cfg AutoTuneConfig, q *fakeQuotas) *autoTuner {
+	t := newAutoTuner(cfg)
+	t.cpuQuota = func() (float64, bool, error) { return q.cpu, q.cpu > 0, nil }
+	t.memoryQuota = func() (int64, bool, error) { return q.memory, q.memory > 0, nil }
+	t.setGOMAXPROCS = func(n int) int {
+		prev := q.procs
+		if n > 0 {
+			q.procs = n
+		}
+		return prev
+	}
+	t.setMemoryLimit = func(limit int64) int64 {
+		prev := q.memoryLimit
+		if limit >= 0 {
+			q.memoryLimit = limit
+		}
+		return prev
+	}
+	t.getenv = func(key string) string { return q.env[key] }
+	t.now = func() time.Time { return time.Unix(1700000000, 0) }
+	t.init()
+	return t
+}
+
+func TestAutoTuner(t *testing.T) {
+	q := &fakeQuotas{cpu: 2.5, memory: 1000, procs: 64, memoryLimit: math.MaxInt64}
+	tuner := newTestAutoTuner(AutoTuneConfig{GOMAXPROCS: true, MemoryLimit: true}, q)
+	tuner.tune()
+	assert.Equal(t, 2, q.procs)
+	assert.Equal(t, int64(900), q.memoryLimit)
+	status := tuner.getStatus()
+	assert.Equal(t, AutoTuneStatus{
+		GOMAXPROCS: 2, CPUQuota: 2.5, MemoryLimit: 900, MemoryQuota: 1000, UpdatedAt: time.Unix(1700000000, 0),
+	}, status)
+
+	// in-place resize
+	q.cpu, q.memory = 4, 2000
+	tuner.tune()
+	assert.Equal(t, 4, q.procs)
+	assert.Equal(t, int64(1800), q.memoryLimit)
+
+	// quota removed, initial values are restored
+	q.cpu, q.memory = -1, -1
+	tuner.tune()
+	assert.Equal(t, 64, q.procs)
+	assert.Equal(t, int64(math.MaxInt64), q.memoryLimit)
+	assert.Equal(t, -1.0, tuner.getStatus().CPUQuota)
+}
+
+func TestAutoTuner_Options(t *testing.T) {
+	q := &fakeQuotas{cpu: 0.5, memory: 1000, procs: 64, memoryLimit: math.MaxInt64}
+	tuner := newTestAutoTuner(AutoTuneConfig{GOMAXPROCS: true, MinGOMAXPROCS: 2, MemoryLimit: true,
+		MemoryLimitRatio: 0.5}, q)
+	tuner.tune()
+	assert.Equal(t, 2, q.procs)
+	assert.Equal(t, int64(500), q.memoryLimit)
+
+	// env is respected
+	q = &fakeQuotas{cpu: 2, memory: 1000, procs: 8, memoryLimit: 100,
+		env: map[string]string{envGOMAXPROCS: "8", envGOMEMLIMIT: "100B"}}
+	tuner = newTestAutoTuner(AutoTuneConfig{GOMAXPROCS: true, MemoryLimit: true}, q)
+	tuner.tune()
+	assert.Equal(t, 8, q.procs)
+	assert.Equal(t, int64(100), q.memoryLimit)
+	assert.Equal(t, AutoTuneStatus{CPUQuota: -1, MemoryQuota: -1}, tuner.getStatus())
+
+	// disabled
+	q = &fakeQuotas{cpu: 2, memory: 1000, procs: 8, memoryLimit: 100}
+	tuner = newTestAutoTuner(AutoTuneConfig{}, q)
+	tuner.tune()
+	assert.Equal(t, 8, q.procs)
+	assert.Equal(t, int64(100), q.memoryLimit)
+}
+
+func TestRoundCPUQuota(t *testing.T) {
+	assert.Equal(t, 2, roundCPUQuota(2.5, RoundingFloor))
+	assert.Equal(t, 3, roundCPUQuota(2.5, RoundingCeil))
+	assert.Equal(t, 3, roundCPUQuota(2.5, RoundingNearest))
+	assert.Equal(t, 2, roundCPUQuota(2.4, RoundingNearest))
+	assert.Equal(t, 0, roundCPUQuota(0.5, ""))
+}
+
+func TestAutoTune(t *testing.T) {
+	_, ok := GetAutoTuneStatus()
+	assert.False(t, ok)
+	stop := AutoTune(AutoTuneConfig{Interval: -1})
+	defer stop()
+	_, ok = GetAutoTuneStatus()
+	assert.True(t, ok)
+	stop()
+	assert.Greater(t, MemoryLimit(), int64(0))
+}
+
+func TestAutoTuner_Restore(t *testing.T) {
+	q := &fakeQuotas{cpu: 2.5, memory: 1000, procs: 64, memoryLimit: math.MaxInt64}
+	tuner := newTestAutoTuner(AutoTuneConfig{GOMAXPROCS: true, MemoryLimit: true}, q)
+	tuner.tune()
+	assert.Equal(t, 2, q.procs)
+	assert.Equal(t, int64(900), q.memoryLimit)
+
+	tuner.restore()
+	assert.Equal(t, 64, q.procs)
+	assert.Equal(t, int64(math.MaxInt64), q.memoryLimit)
+	status := tuner.getStatus()
+	assert.Equal(t, 0, status.GOMAXPROCS)
+	assert.Equal(t, int64(0), status.MemoryLimit)
+
+	// a stopped tuner no longer applies the quotas
+	tuner.tune()
+	assert.Equal(t, 64, q.procs)
+
+	// the next tuner starts from the restored values instead of the tuned ones
+	tuner = newTestAutoTuner(AutoTuneConfig{GOMAXPROCS: true, MemoryLimit: true}, q)
+	tuner.tune()
+	q.cpu, q.memory = -1, -1
+	tuner.tune()
+	assert.Equal(t, 64, q.procs)
+	assert.Equal(t, int64(math.MaxInt64), q.memoryLimit)
+
+	// values changed by others after tuning are kept
+	q.cpu = 4
+	tuner.tune()
+	assert.Equal(t, 4, q.procs)
+	q.procs = 8
+	tuner.restore()
+	assert.Equal(t, 8, q.procs)
+	assert.Equal(t, int64(math.MaxInt64), q.memoryLimit)
+}
@@ -0,0 +1,83 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"path/filepath"
+	"sync"
+
+	cgroupsv2 "trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups/cgroupsv2"
+)
+
+const (
+	procSelfMountInfo = "/proc/self/mountinfo"
+	// procPressureDir is the directory of the system-wide PSI files.
+	procPressureDir = "/proc/pressure"
+)
+
+// CGroup is the cgroup of the process, implemented by both CGroup-V1 and CGroup-V2.
+type CGroup interface {
+	CPUQuota() (float64, bool, error)
+	CPUStat() (*cgroupsv2.CPUStat, bool, error)
+	MemoryQuota() (int64, bool, error)
+	MemoryUsage() (int64, bool, error)
+	MemoryWorkingSet() (int64, bool, error)
+	OOMKills() (uint64, bool, error)
+	Pressure(resource string) (*cgroupsv2.PSI, bool, error)
+}
+
+var (
+	currentCGroupOnce sync.Once
+	currentCGroupVal  CGroup
+	currentCGroupErr  error
+)
+
+// NewCGroup returns the CGroup-V2 of the process if it uses the unified hierarchy,
+// otherwise the CGroup-V1.
+func NewCGroup(procPathMountInfo, procPathCGroup string) (CGroup, error) {
+	v2, err := cgroupsv2.NewCGroupV2FromProc(procPathMountInfo, procPathCGroup)
+	if err != nil {
+		return nil, err
+	}
+	if v2 != nil {
+		return v2, nil
+	}
+	return cgroupsv2.NewCGroups(procPathMountInfo, procPathCGroup)
+}
+
+// CurrentCGroup returns the cgroup of the current process, it is resolved only once.
+func CurrentCGroup() (CGroup, error) {
+	currentCGroupOnce.Do(func() {
+		currentCGroupVal, currentCGroupErr = NewCGroup(procSelfMountInfo, procSelfCgroup)
+	})
+	return currentCGroupVal, currentCGroupErr
+}
+
+// Pressure returns the PSI of the resource (cpu, memory or io) of cg,
+// and falls back to the system-wide PSI in /proc/pressure if it is not available per cgroup, e.g. CGroup-V1.
+func Pressure(cg CGroup, resource string) (*cgroupsv2.PSI, bool, error) {
+	return pressure(cg, procPressureDir, resource)
+}
+
+func pressure(cg CGroup, dir, resource string) (*cgroupsv2.PSI, bool, error) {
+	if cg != nil {
+		if psi, defined, err := cg.Pressure(resource); err != nil || defined {
+			return psi, defined, err
+		}
+	}
+	return cgroupsv2.ReadPressure(filepath.Join(dir, resource))
+}
@@ -0,0 +1,90 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFixtureCGroup returns the cgroup of fixture testdata/$name,
+// mount points under /sys/fs/cgroup in its mountinfo are moved into the fixture directory.
+func newFixtureCGroup(t *testing.T, name string) CGroup {
+	dir, err := filepath.Abs(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	b, err := os.ReadFile(filepath.Join(dir, "proc", "mountinfo"))
+	require.NoError(t, err)
+	mountInfo := filepath.Join(t.TempDir(), "mountinfo")
+	content := strings.ReplaceAll(string(b), " /sys/fs/cgroup", " "+filepath.Join(dir, "sys", "fs", "cgroup"))
+	require.NoError(t, os.WriteFile(mountInfo, []byte(content), 0600))
+	cg, err := NewCGroup(mountInfo, filepath.Join(dir, "proc", "cgroup"))
+	require.NoError(t, err)
+	return cg
+}
+
+func TestCGroupFixtures(t *testing.T) {
+	tests := []struct {
+		name        string
+		cpuQuota    float64
+		memoryQuota int64
+		usage       int64
+		workingSet  int64
+		// cpuSome avg10 of cpu pressure, v1 falls back to the system-wide PSI
+		cpuSome float64
+	}{
+		{"v1", 1.5, 2147483648, 1073741824, 1073741824 - 268435456, 0.4},
+		// limits of the pod are applied to the container
+		{"v2", 2.5, 3221225472, 1073741824, 1073741824 - 201326592, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cg := newFixtureCGroup(t, tt.name)
+			cpu, err := cpuQuota(cg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.cpuQuota, cpu)
+
+			memory, err := memoryQuota(cg)
+			require.NoError(t, err)
+			assert.Equal(t, tt.memoryQuota, memory)
+
+			usage, err := memoryUsage(cg.MemoryUsage)
+			require.NoError(t, err)
+			assert.Equal(t, tt.usage, usage)
+
+			workingSet, err := memoryUsage(cg.MemoryWorkingSet)
+			require.NoError(t, err)
+			assert.Equal(t, tt.workingSet, workingSet)
+
+			psi, defined, err := pressure(cg, filepath.Join("testdata", tt.name, "proc", "pressure"), "cpu")
+			require.NoError(t, err)
+			require.True(t, defined)
+			assert.Equal(t, tt.cpuSome, psi.Some.Avg10)
+		})
+	}
+}
+
+func TestIsContainerCgroups(t *testing.T) {
+	assert.True(t, isContainerCgroups("0::/\n"))
+	assert.True(t, isContainerCgroups("0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-1.scope\n"))
+	assert.True(t, isContainerCgroups("5:memory:/docker/abc\n4:cpu,cpuacct:/docker/abc\n"))
+	assert.False(t, isContainerCgroups("0::/user.slice/user-1000.slice/session-1.scope\n"))
+}
@@ -47,7 +47,14 @@ func hasDockerEnvPath() bool {
 
 func hasContainerCgroups() bool {
 	if bdata, err := ioutil.ReadFile(procSelfCgroup); err == nil {
-		return strings.Contains(string(bdata), ":/docker/") || strings.Contains(string(bdata), ":/kubepods/")
+		return isContainerCgroups(string(bdata))
 	}
 	return false
 }
+
+// isContainerCgroups returns if the content of /proc/self/cgroup belongs to a container.
+// With CGroup-V2 and cgroup namespace, the content of a container is only `0::/`.
+func isContainerCgroups(content string) bool {
+	return strings.Contains(content, ":/docker/") || strings.Contains(content, ":/kubepods/") ||
+		strings.Contains(content, ":/kubepods.slice/") || strings.TrimSpace(content) == "0::/"
+}
@@ -0,0 +1,24 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build !go1.19
+// +build !go1.19
+
+package runtime
+
+import "math"
+
+// setMemoryLimit is not supported before go1.19, it always returns math.MaxInt64.
+func setMemoryLimit(int64) int64 {
+	return math.MaxInt64
+}
@@ -18,12 +18,10 @@ package runtime
 
 import (
 	"runtime"
-
-	"trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups"
 )
 
 // CPUQuota returns the CPU quota applied with the CPU cgroup controller.
-// It is a result of `cpu.cfs_quota_us / cpu.cfs_period_us`.
+// It is a result of `cpu.max` in CGroup-V2 or `cpu.cfs_quota_us / cpu.cfs_period_us` in CGroup-V1.
 // If it is not in container env, return the number of cpu on host.
 // This implementation is meant for linux
 func CPUQuota() (float64, error) {
@@ -32,13 +30,17 @@ func CPUQuota() (float64, error) {
 		return float64(runtime.NumCPU()), nil
 	}
 	// uses cgroups to determine cpu quota.
-	cg, err := cgroups.NewCGroupsForCurrentProcess()
+	cg, err := CurrentCGroup()
 	if err != nil {
 		return float64(runtime.NumCPU()), err
 	}
-	cpuQuota, defined, err := cg.CPUQuota()
+	return cpuQuota(cg)
+}
+
+func cpuQuota(cg CGroup) (float64, error) {
+	quota, defined, err := cg.CPUQuota()
 	if err != nil || !defined {
 		return float64(runtime.NumCPU()), err
 	}
-	return cpuQuota, nil
+	return quota, nil
 }
@@ -19,39 +19,25 @@ package runtime
 import (
 	procmeminfo "github.com/guillermo/go.procmeminfo"
 	"github.com/shirou/gopsutil/v3/mem"
-
-	cgroups "trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups"
-	cgroupsv2 "trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups/cgroupsv2"
 )
 
 // MemoryQuota returns total available memory.
+// It is a result of `memory.max` in CGroup-V2 or `memory.limit_in_bytes` in CGroup-V1,
+// and the total memory of host if the limit is not set.
 // This implementation is meant for linux
 func MemoryQuota() (int64, error) {
-	var memoryQuota int64
-	var defined bool
-	var err error
-
-	isV2, err := cgroupsv2.IsCGroupV2()
+	cg, err := CurrentCGroup()
 	if err != nil {
 		return 0, err
 	}
+	return memoryQuota(cg)
+}
 
-	if isV2 {
-		memoryQuota, defined, err = cgroupsv2.MemoryQuotaV2()
-		if err != nil {
-			return 0, err
-		}
-	} else {
-		cgv1, err := cgroupsv2.NewCGroupsForCurrentProcess()
-		if err != nil {
-			return 0, err
-		}
-		memoryQuota, defined, err = cgv1.MemoryQuota()
-		if err != nil {
-			return 0, err
-		}
+func memoryQuota(cg CGroup) (int64, error) {
+	quota, defined, err := cg.MemoryQuota()
+	if err != nil {
+		return 0, err
 	}
-
 	if !defined {
 		totalMem, err := readMemInfo()
 		if err != nil {
@@ -59,30 +45,53 @@ func MemoryQuota() (int64, error) {
 		}
 		return int64(totalMem), nil
 	}
-
-	return memoryQuota, nil
+	return quota, nil
 }
 
 // MemoryUsage returns usage memory.
+// It is a result of `memory.current` in CGroup-V2 or `memory.usage_in_bytes` in CGroup-V1,
+// and the used memory of host if not in container.
 // This implementation is meant for linux
 func MemoryUsage() (int64, error) {
 	if !ProcessInContainer() {
-		memInfo := &procmeminfo.MemInfo{}
-		if err := memInfo.Update(); err != nil {
-			return 0, err
-		}
-		return int64(memInfo.Used()), nil
+		return hostMemoryUsed()
 	}
 	// uses cgroups to determine available memory.
-	cgroups, err := cgroups.NewCGroupsForCurrentProcess()
+	cg, err := CurrentCGroup()
+	if err != nil {
+		return 0, err
+	}
+	return memoryUsage(cg.MemoryUsage)
+}
+
+// MemoryWorkingSet returns working set memory, which is the memory usage minus inactive file cache,
+// it is what kubelet and the OOM killer care about.
+// This implementation is meant for linux
+func MemoryWorkingSet() (int64, error) {
+	if !ProcessInContainer() {
+		return hostMemoryUsed()
+	}
+	cg, err := CurrentCGroup()
 	if err != nil {
 		return 0, err
 	}
-	memoryUsage, defined, err := cgroups.MemoryUsage()
+	return memoryUsage(cg.MemoryWorkingSet)
+}
+
+func memoryUsage(read func() (int64, bool, error)) (int64, error) {
+	usage, defined, err := read()
 	if err != nil || !defined {
 		return 0, err
 	}
-	return memoryUsage, nil
+	return usage, nil
+}
+
+func hostMemoryUsed() (int64, error) {
+	memInfo := &procmeminfo.MemInfo{}
+	if err := memInfo.Update(); err != nil {
+		return 0, err
+	}
+	return int64(memInfo.Used()), nil
 }
 
 // readMemInfo returns the total memory
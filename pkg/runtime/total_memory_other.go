@@ -23,6 +23,7 @@ import (
 var (
 	errTotalMemoryNotAvailable = fmt.Errorf("reading cgroups total memory is available only on linux")
 	errUsageMemoryNotAvailable = fmt.Errorf("reading cgroups usage memory is available only on linux")
+	errWorkingSetNotAvailable  = fmt.Errorf("reading cgroups working set memory is available only on linux")
 )
 
 // MemoryQuota returns total available memory.
@@ -36,3 +37,9 @@ func MemoryQuota() (int64, error) {
 func MemoryUsage() (int64, error) {
 	return -1, errUsageMemoryNotAvailable
 }
+
+// MemoryWorkingSet returns working set memory.
+// This is non-Linux version that returns -1 and errWorkingSetNotAvailable.
+func MemoryWorkingSet() (int64, error) {
+	return -1, errWorkingSetNotAvailable
+}
@@ -0,0 +1,87 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package telemetrystatus
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+var page = template.Must(template.New("telemetry").Parse(`<!DOCTYPE html>
+<html>
+<head><title>telemetry status</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { background: #f6f8fa; padding: 1em; overflow: auto; }
+</style>
+</head>
+<body>
+<h1>telemetry status</h1>
+<p><a href="?format=json">json</a></p>
+{{range .}}<h2 id="{{.Name}}">{{.Name}}</h2>
+<pre>{{.JSON}}</pre>
+{{end}}</body>
+</html>
+`))
+
+type htmlSection struct {
+	Name string
+	JSON string
+}
+
+// Handler serves the Snapshot, as JSON if the format query is json or application/json is accepted,
+// otherwise as a HTML page.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sections := Snapshot()
+		if wantJSON(r) {
+			status := make(map[string]interface{}, len(sections))
+			for _, s := range sections {
+				status[s.Name] = s.Status
+			}
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			_ = enc.Encode(status)
+			return
+		}
+		hs := make([]htmlSection, 0, len(sections))
+		for _, s := range sections {
+			hs = append(hs, htmlSection{Name: s.Name, JSON: indentJSON(s.Status)})
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = page.Execute(w, hs)
+	})
+}
+
+// indentJSON marshals v without escaping HTML, the template escapes it.
+func indentJSON(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func wantJSON(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,176 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package telemetrystatus collects the status of the SDK components, which is served on /debug/telemetry.
+// Components register a Provider of their section, the last registered provider of a section wins.
+package telemetrystatus
+
+import (
+	"sort"
+	"sync"
+
+	dto "github.com/prometheus/client_model/go"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+)
+
+// Sections of the status, in the display order.
+const (
+	SectionConfig       = "config"
+	SectionResource     = "resource"
+	SectionSampler      = "sampler"
+	SectionSpans        = "spans"
+	SectionLogs         = "logs"
+	SectionExporters    = "exporters"
+	SectionRemote       = "remote"
+	SectionCarriers     = "carriers"
+	SectionRegistration = "registration"
+)
+
+var sectionOrder = []string{SectionConfig, SectionResource, SectionSampler, SectionSpans, SectionLogs,
+	SectionExporters, SectionRemote, SectionCarriers, SectionRegistration}
+
+// Provider returns the status of a section, the status must be marshalable to JSON.
+type Provider func() interface{}
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]Provider)
+	// exporters providers of SectionExporters by exporter name
+	exporters = make(map[string]func() ExporterStatus)
+)
+
+// Register registers the provider of section, replacing the previous one.
+func Register(section string, p Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[section] = p
+}
+
+// RegisterExporter registers the provider of the exporter in SectionExporters, replacing the previous one.
+func RegisterExporter(name string, p func() ExporterStatus) {
+	mu.Lock()
+	defer mu.Unlock()
+	exporters[name] = p
+}
+
+// Section a section of the status
+type Section struct {
+	Name   string      `json:"name"`
+	Status interface{} `json:"status"`
+}
+
+// Snapshot returns the status of all registered sections, the known sections are in the display order
+// and followed by the others sorted by name.
+func Snapshot() []Section {
+	ps := copyProviders()
+	var names []string
+	for _, name := range sectionOrder {
+		if _, ok := ps[name]; ok {
+			names = append(names, name)
+		}
+	}
+	var others []string
+	for name := range ps {
+		if !isKnownSection(name) {
+			others = append(others, name)
+		}
+	}
+	sort.Strings(others)
+	names = append(names, others...)
+
+	sections := make([]Section, 0, len(names))
+	for _, name := range names {
+		sections = append(sections, Section{Name: name, Status: ps[name]()})
+	}
+	return sections
+}
+
+// copyProviders copies the providers so that they are called without holding the lock,
+// the exporters are merged into SectionExporters.
+func copyProviders() map[string]Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	ps := make(map[string]Provider, len(providers)+1)
+	for k, v := range providers {
+		ps[k] = v
+	}
+	if len(exporters) == 0 {
+		return ps
+	}
+	es := make(map[string]func() ExporterStatus, len(exporters))
+	for k, v := range exporters {
+		es[k] = v
+	}
+	ps[SectionExporters] = func() interface{} {
+		status := make(map[string]ExporterStatus, len(es))
+		for name, p := range es {
+			status[name] = p()
+		}
+		return status
+	}
+	return ps
+}
+
+func isKnownSection(name string) bool {
+	for _, s := range sectionOrder {
+		if s == name {
+			return true
+		}
+	}
+	return false
+}
+
+// QueueStatus status of a batch processor
+type QueueStatus struct {
+	// Length number of the items in the queue
+	Length int `json:"length"`
+	// Capacity of the queue
+	Capacity int `json:"capacity"`
+	// Enqueued, Dropped, Exported and Failed are the totals of the telemetry in the process
+	Enqueued float64 `json:"enqueued"`
+	Dropped  float64 `json:"dropped"`
+	Exported float64 `json:"exported"`
+	Failed   float64 `json:"failed"`
+}
+
+// NewQueueStatus creates a QueueStatus of the queue, the totals are read from metrics.BatchProcessCounter
+// of the telemetry, traces or logs.
+func NewQueueStatus(length, capacity int, telemetry string) QueueStatus {
+	return QueueStatus{
+		Length:   length,
+		Capacity: capacity,
+		Enqueued: counterValue("enqueue", telemetry),
+		Dropped:  counterValue("dropped", telemetry),
+		Exported: counterValue("success", telemetry),
+		Failed:   counterValue("failed", telemetry),
+	}
+}
+
+func counterValue(status, telemetry string) float64 {
+	m := &dto.Metric{}
+	if err := metrics.BatchProcessCounter.WithLabelValues(status, telemetry).Write(m); err != nil {
+		return 0
+	}
+	return m.GetCounter().GetValue()
+}
+
+// ExporterStatus connection status of an exporter
+type ExporterStatus struct {
+	// Address of the exporter
+	Address string `json:"address,omitempty"`
+	// Connected is false if the last connection failed
+	Connected bool `json:"connected"`
+	// LastConnectError error of the last connection
+	LastConnectError string `json:"last_connect_error,omitempty"`
+}
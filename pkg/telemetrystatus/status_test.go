@@ -0,0 +1,97 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package telemetrystatus
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+)
+
+func resetProviders() {
+	mu.Lock()
+	defer mu.Unlock()
+	providers = make(map[string]Provider)
+	exporters = make(map[string]func() ExporterStatus)
+}
+
+func TestSnapshotOrder(t *testing.T) {
+	resetProviders()
+	defer resetProviders()
+	Register("zzz", func() interface{} { return 1 })
+	Register("aaa", func() interface{} { return 2 })
+	Register(SectionRemote, func() interface{} { return "remote" })
+	Register(SectionConfig, func() interface{} { return "old" })
+	Register(SectionConfig, func() interface{} { return "config" })
+	RegisterExporter("trace_exporter", func() ExporterStatus {
+		return ExporterStatus{Address: "localhost:4317", Connected: true}
+	})
+
+	sections := Snapshot()
+	var names []string
+	for _, s := range sections {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{SectionConfig, SectionExporters, SectionRemote, "aaa", "zzz"}, names)
+	assert.Equal(t, "config", sections[0].Status)
+	assert.Equal(t, map[string]ExporterStatus{
+		"trace_exporter": {Address: "localhost:4317", Connected: true},
+	}, sections[1].Status)
+}
+
+func TestNewQueueStatus(t *testing.T) {
+	before := NewQueueStatus(0, 0, "test_queue")
+	metrics.BatchProcessCounter.WithLabelValues("enqueue", "test_queue").Add(3)
+	metrics.BatchProcessCounter.WithLabelValues("dropped", "test_queue").Inc()
+	metrics.BatchProcessCounter.WithLabelValues("success", "test_queue").Add(2)
+	s := NewQueueStatus(1, 10, "test_queue")
+	assert.Equal(t, 1, s.Length)
+	assert.Equal(t, 10, s.Capacity)
+	assert.Equal(t, before.Enqueued+3, s.Enqueued)
+	assert.Equal(t, before.Dropped+1, s.Dropped)
+	assert.Equal(t, before.Exported+2, s.Exported)
+	assert.Equal(t, before.Failed, s.Failed)
+}
+
+func TestHandler(t *testing.T) {
+	resetProviders()
+	defer resetProviders()
+	Register(SectionResource, func() interface{} { return map[string]string{"service.name": "<svc>"} })
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/telemetry?format=json", nil))
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var status map[string]map[string]string
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &status))
+	assert.Equal(t, "<svc>", status[SectionResource]["service.name"])
+
+	r := httptest.NewRequest(http.MethodGet, "/debug/telemetry", nil)
+	r.Header.Set("Accept", "application/json")
+	w = httptest.NewRecorder()
+	Handler().ServeHTTP(w, r)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	w = httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/telemetry", nil))
+	assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "text/html"))
+	assert.Contains(t, w.Body.String(), `<h2 id="resource">resource</h2>`)
+	assert.Contains(t, w.Body.String(), "&lt;svc&gt;")
+}
@@ -0,0 +1,252 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package tracestore
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// defaultListLimit number of the traces listed if the limit query is absent
+const defaultListLimit = 100
+
+var listPage = template.Must(template.New("traces").Parse(`<!DOCTYPE html>
+<html>
+<head><title>recent traces</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+.error { color: #c00; }
+</style>
+</head>
+<body>
+<h1>recent traces</h1>
+<form>
+service <input name="service" value="{{.Filter.Service}}">
+method <input name="method" value="{{.Filter.Method}}">
+status <select name="status">
+<option value=""></option>
+<option value="ok"{{if eq .Filter.Status "ok"}} selected{{end}}>ok</option>
+<option value="error"{{if eq .Filter.Status "error"}} selected{{end}}>error</option>
+</select>
+min_duration <input name="min_duration" value="{{.MinDuration}}" placeholder="100ms">
+<input type="submit" value="filter">
+</form>
+<p><a href="?format=json">json</a></p>
+<table>
+<tr><th>trace id</th><th>name</th><th>service</th><th>method</th><th>start</th><th>duration</th><th>status</th><th>spans</th></tr>
+{{range .Traces}}<tr>
+<td><a href="traces/trace?id={{.TraceID}}">{{.TraceID}}</a></td><td>{{.Name}}</td><td>{{.Service}}</td><td>{{.Method}}</td>
+<td>{{.Start.Format "2006-01-02 15:04:05.000"}}</td><td>{{.Duration}}{{if .Slow}} (slow){{end}}</td>
+<td{{if eq .Status "error"}} class="error"{{end}}>{{.Status}}</td><td>{{.SpanCount}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+var tracePage = template.Must(template.New("trace").Parse(`<!DOCTYPE html>
+<html>
+<head><title>trace {{.TraceID}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+ul { list-style: none; padding-left: 1.5em; border-left: 1px dotted #ccc; }
+.error { color: #c00; }
+details pre { background: #f6f8fa; padding: 0.5em; }
+</style>
+</head>
+<body>
+<h1>trace {{.TraceID}}</h1>
+<p><a href="?id={{.TraceID}}&format=json">json</a> <a href="otlp?id={{.TraceID}}">otlp json</a></p>
+{{define "nodes"}}<ul>{{range .}}<li>
+<details><summary{{if eq .Status "Error"}} class="error"{{end}}>{{.Name}} [{{.Kind}}] {{.Duration}}
+{{if .StatusMessage}} {{.StatusMessage}}{{end}}</summary>
+<pre>{{.Details}}</pre></details>
+{{if .Children}}{{template "nodes" .Children}}{{end}}
+</li>{{end}}</ul>{{end}}
+{{template "nodes" .Roots}}
+</body>
+</html>
+`))
+
+type htmlList struct {
+	Filter      Filter
+	MinDuration string
+	Traces      []Summary
+}
+
+type htmlTrace struct {
+	TraceID string
+	Roots   []*htmlNode
+}
+
+type htmlNode struct {
+	*SpanNode
+	Details  string
+	Children []*htmlNode
+}
+
+// ListHandler lists the recent traces of the Default Processor filtered by the query
+// service, method, status (ok or error), min_duration (e.g. 100ms) and limit (default 100),
+// as JSON if the format query is json or application/json is accepted, otherwise as a HTML page.
+func ListHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p := Default()
+		if p == nil {
+			http.Error(w, "recent traces are not enabled", http.StatusNotFound)
+			return
+		}
+		f, err := parseFilter(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		traces := p.Traces(f)
+		if wantJSON(r) {
+			writeJSON(w, traces)
+			return
+		}
+		page := htmlList{Filter: f, MinDuration: r.URL.Query().Get("min_duration"), Traces: traces}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = listPage.Execute(w, page)
+	})
+}
+
+// TraceHandler shows the trace of the id query of the Default Processor as a tree of spans,
+// as JSON if the format query is json or application/json is accepted, otherwise as a HTML page.
+func TraceHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, spans, ok := lookupTrace(w, r)
+		if !ok {
+			return
+		}
+		roots := Tree(spans)
+		if wantJSON(r) {
+			writeJSON(w, roots)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = tracePage.Execute(w, htmlTrace{TraceID: id.String(), Roots: htmlNodes(roots)})
+	})
+}
+
+// OTLPHandler downloads the trace of the id query of the Default Processor as OTLP JSON
+func OTLPHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, spans, ok := lookupTrace(w, r)
+		if !ok {
+			return
+		}
+		b, err := protojson.Marshal(OTLP(spans))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=trace-%s.json", id))
+		_, _ = w.Write(b)
+	})
+}
+
+func lookupTrace(w http.ResponseWriter, r *http.Request) (trace.TraceID, []sdktrace.ReadOnlySpan, bool) {
+	p := Default()
+	if p == nil {
+		http.Error(w, "recent traces are not enabled", http.StatusNotFound)
+		return trace.TraceID{}, nil, false
+	}
+	id, err := trace.TraceIDFromHex(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("invalid trace id %q", r.URL.Query().Get("id")), http.StatusBadRequest)
+		return trace.TraceID{}, nil, false
+	}
+	spans, ok := p.Trace(id)
+	if !ok {
+		http.Error(w, "trace not found", http.StatusNotFound)
+		return trace.TraceID{}, nil, false
+	}
+	return id, spans, true
+}
+
+func parseFilter(r *http.Request) (Filter, error) {
+	q := r.URL.Query()
+	f := Filter{Service: q.Get("service"), Method: q.Get("method"), Status: q.Get("status"), Limit: defaultListLimit}
+	if f.Status != "" && f.Status != "ok" && f.Status != "error" {
+		return f, fmt.Errorf("invalid status %q, must be ok or error", f.Status)
+	}
+	if v := q.Get("min_duration"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid min_duration %q", v)
+		}
+		f.MinDuration = d
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return f, fmt.Errorf("invalid limit %q", v)
+		}
+		f.Limit = n
+	}
+	return f, nil
+}
+
+func htmlNodes(nodes []*SpanNode) []*htmlNode {
+	out := make([]*htmlNode, 0, len(nodes))
+	for _, n := range nodes {
+		details := struct {
+			SpanID     string                 `json:"span_id"`
+			Start      time.Time              `json:"start"`
+			Attributes map[string]interface{} `json:"attributes,omitempty"`
+			Events     []Event                `json:"events,omitempty"`
+		}{n.SpanID, n.Start, n.Attributes, n.Events}
+		out = append(out, &htmlNode{SpanNode: n, Details: indentJSON(details), Children: htmlNodes(n.Children)})
+	}
+	return out
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// indentJSON marshals v without escaping HTML, the template escapes it.
+func indentJSON(v interface{}) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err.Error()
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func wantJSON(r *http.Request) bool {
+	if f := r.URL.Query().Get("format"); f != "" {
+		return f == "json"
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
@@ -0,0 +1,153 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package tracestore
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	commonproto "go.opentelemetry.io/proto/otlp/common/v1"
+	resourceproto "go.opentelemetry.io/proto/otlp/resource/v1"
+	traceproto "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// OTLP transforms spans into OTLP TracesData, grouped by resource and instrumentation scope
+func OTLP(spans []sdktrace.ReadOnlySpan) *traceproto.TracesData {
+	data := &traceproto.TracesData{}
+	resourceSpans := make(map[attribute.Distinct]*traceproto.ResourceSpans)
+	scopeSpans := make(map[attribute.Distinct]map[string]*traceproto.ScopeSpans)
+	for _, s := range spans {
+		key := s.Resource().Equivalent()
+		rs, ok := resourceSpans[key]
+		if !ok {
+			rs = &traceproto.ResourceSpans{
+				Resource:  &resourceproto.Resource{Attributes: keyValues(s.Resource().Attributes())},
+				SchemaUrl: s.Resource().SchemaURL(),
+			}
+			resourceSpans[key] = rs
+			scopeSpans[key] = make(map[string]*traceproto.ScopeSpans)
+			data.ResourceSpans = append(data.ResourceSpans, rs)
+		}
+		scope := s.InstrumentationScope()
+		ss, ok := scopeSpans[key][scope.Name+"@"+scope.Version]
+		if !ok {
+			ss = &traceproto.ScopeSpans{
+				Scope:     &commonproto.InstrumentationScope{Name: scope.Name, Version: scope.Version},
+				SchemaUrl: scope.SchemaURL,
+			}
+			scopeSpans[key][scope.Name+"@"+scope.Version] = ss
+			rs.ScopeSpans = append(rs.ScopeSpans, ss)
+		}
+		ss.Spans = append(ss.Spans, otlpSpan(s))
+	}
+	return data
+}
+
+func otlpSpan(s sdktrace.ReadOnlySpan) *traceproto.Span {
+	traceID, spanID := s.SpanContext().TraceID(), s.SpanContext().SpanID()
+	span := &traceproto.Span{
+		TraceId:                traceID[:],
+		SpanId:                 spanID[:],
+		TraceState:             s.SpanContext().TraceState().String(),
+		Name:                   s.Name(),
+		Kind:                   traceproto.Span_SpanKind(s.SpanKind()),
+		StartTimeUnixNano:      uint64(s.StartTime().UnixNano()),
+		EndTimeUnixNano:        uint64(s.EndTime().UnixNano()),
+		Attributes:             keyValues(s.Attributes()),
+		DroppedAttributesCount: uint32(s.DroppedAttributes()),
+		DroppedEventsCount:     uint32(s.DroppedEvents()),
+		DroppedLinksCount:      uint32(s.DroppedLinks()),
+		Status:                 otlpStatus(s),
+	}
+	if s.Parent().IsValid() {
+		parentID := s.Parent().SpanID()
+		span.ParentSpanId = parentID[:]
+	}
+	for _, e := range s.Events() {
+		span.Events = append(span.Events, &traceproto.Span_Event{
+			TimeUnixNano:           uint64(e.Time.UnixNano()),
+			Name:                   e.Name,
+			Attributes:             keyValues(e.Attributes),
+			DroppedAttributesCount: uint32(e.DroppedAttributeCount),
+		})
+	}
+	for _, l := range s.Links() {
+		linkTraceID, linkSpanID := l.SpanContext.TraceID(), l.SpanContext.SpanID()
+		span.Links = append(span.Links, &traceproto.Span_Link{
+			TraceId:                linkTraceID[:],
+			SpanId:                 linkSpanID[:],
+			TraceState:             l.SpanContext.TraceState().String(),
+			Attributes:             keyValues(l.Attributes),
+			DroppedAttributesCount: uint32(l.DroppedAttributeCount),
+		})
+	}
+	return span
+}
+
+func otlpStatus(s sdktrace.ReadOnlySpan) *traceproto.Status {
+	code := traceproto.Status_STATUS_CODE_UNSET
+	switch s.Status().Code {
+	case codes.Ok:
+		code = traceproto.Status_STATUS_CODE_OK
+	case codes.Error:
+		code = traceproto.Status_STATUS_CODE_ERROR
+	}
+	return &traceproto.Status{Code: code, Message: s.Status().Description}
+}
+
+func keyValues(kvs []attribute.KeyValue) []*commonproto.KeyValue {
+	if len(kvs) == 0 {
+		return nil
+	}
+	out := make([]*commonproto.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		out = append(out, &commonproto.KeyValue{Key: string(kv.Key), Value: anyValue(kv.Value)})
+	}
+	return out
+}
+
+func anyValue(v attribute.Value) *commonproto.AnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		return &commonproto.AnyValue{Value: &commonproto.AnyValue_BoolValue{BoolValue: v.AsBool()}}
+	case attribute.INT64:
+		return &commonproto.AnyValue{Value: &commonproto.AnyValue_IntValue{IntValue: v.AsInt64()}}
+	case attribute.FLOAT64:
+		return &commonproto.AnyValue{Value: &commonproto.AnyValue_DoubleValue{DoubleValue: v.AsFloat64()}}
+	case attribute.STRING:
+		return &commonproto.AnyValue{Value: &commonproto.AnyValue_StringValue{StringValue: v.AsString()}}
+	case attribute.BOOLSLICE:
+		values := v.AsBoolSlice()
+		return arrayValue(len(values), func(i int) attribute.Value { return attribute.BoolValue(values[i]) })
+	case attribute.INT64SLICE:
+		values := v.AsInt64Slice()
+		return arrayValue(len(values), func(i int) attribute.Value { return attribute.Int64Value(values[i]) })
+	case attribute.FLOAT64SLICE:
+		values := v.AsFloat64Slice()
+		return arrayValue(len(values), func(i int) attribute.Value { return attribute.Float64Value(values[i]) })
+	case attribute.STRINGSLICE:
+		values := v.AsStringSlice()
+		return arrayValue(len(values), func(i int) attribute.Value { return attribute.StringValue(values[i]) })
+	default:
+		return &commonproto.AnyValue{Value: &commonproto.AnyValue_StringValue{StringValue: v.Emit()}}
+	}
+}
+
+func arrayValue(n int, value func(i int) attribute.Value) *commonproto.AnyValue {
+	values := make([]*commonproto.AnyValue, 0, n)
+	for i := 0; i < n; i++ {
+		values = append(values, anyValue(value(i)))
+	}
+	return &commonproto.AnyValue{Value: &commonproto.AnyValue_ArrayValue{ArrayValue: &commonproto.ArrayValue{Values: values}}}
+}
@@ -0,0 +1,342 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package tracestore keeps the recently completed local traces in memory for debugging without a backend.
+package tracestore
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config config of the recent traces store
+type Config struct {
+	// Enabled keeps the recent traces, default false
+	Enabled bool `yaml:"enabled"`
+	// MaxTraces number of the normal traces kept, default 100
+	MaxTraces int `yaml:"max_traces"`
+	// MaxSlowTraces number of the slow traces kept besides the normal ones, default 50
+	MaxSlowTraces int `yaml:"max_slow_traces"`
+	// MaxErrorTraces number of the error traces kept besides the normal and slow ones, default 50
+	MaxErrorTraces int `yaml:"max_error_traces"`
+	// SlowThreshold a trace is slow if its local root span takes at least it, default 1s
+	SlowThreshold time.Duration `yaml:"slow_threshold"`
+	// MaxSpansPerTrace spans of a trace beyond it are dropped, default 500
+	MaxSpansPerTrace int `yaml:"max_spans_per_trace"`
+	// MaxPendingTraces number of the traces whose local root span is not ended, the oldest is dropped beyond it,
+	// default 1000
+	MaxPendingTraces int `yaml:"max_pending_traces"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.MaxTraces <= 0 {
+		c.MaxTraces = 100
+	}
+	if c.MaxSlowTraces <= 0 {
+		c.MaxSlowTraces = 50
+	}
+	if c.MaxErrorTraces <= 0 {
+		c.MaxErrorTraces = 50
+	}
+	if c.SlowThreshold <= 0 {
+		c.SlowThreshold = time.Second
+	}
+	if c.MaxSpansPerTrace <= 0 {
+		c.MaxSpansPerTrace = 500
+	}
+	if c.MaxPendingTraces <= 0 {
+		c.MaxPendingTraces = 1000
+	}
+	return c
+}
+
+// Keys of the service and method of the local root span, the tRPC keys take precedence.
+const (
+	trpcCalleeServiceKey = attribute.Key("trpc.callee_service")
+	trpcCalleeMethodKey  = attribute.Key("trpc.callee_method")
+	rpcServiceKey        = attribute.Key("rpc.service")
+	rpcMethodKey         = attribute.Key("rpc.method")
+)
+
+// class of a completed trace, each class has its own ring so the error and slow traces are not
+// evicted by the normal ones
+type class int
+
+const (
+	classNormal class = iota
+	classSlow
+	classError
+	numClasses
+)
+
+// storedTrace spans of a trace, the summary is set when its local root span ends
+type storedTrace struct {
+	id      trace.TraceID
+	spans   []sdktrace.ReadOnlySpan
+	dropped int
+	root    sdktrace.ReadOnlySpan
+	isError bool
+}
+
+// Processor a SpanProcessor keeping the recently completed local traces,
+// a trace is completed when its local root span (without parent or with a remote parent) ends.
+type Processor struct {
+	cfg Config
+
+	mu           sync.Mutex
+	pending      map[trace.TraceID]*storedTrace
+	pendingOrder []trace.TraceID
+	completed    map[trace.TraceID]*storedTrace
+	rings        [numClasses][]trace.TraceID
+}
+
+var _ sdktrace.SpanProcessor = (*Processor)(nil)
+
+// NewProcessor creates a Processor
+func NewProcessor(cfg Config) *Processor {
+	return &Processor{
+		cfg:       cfg.withDefaults(),
+		pending:   make(map[trace.TraceID]*storedTrace),
+		completed: make(map[trace.TraceID]*storedTrace),
+	}
+}
+
+var (
+	defaultProcessor   *Processor
+	defaultProcessorMu sync.RWMutex
+)
+
+// SetDefault sets the Processor served by the handlers
+func SetDefault(p *Processor) {
+	defaultProcessorMu.Lock()
+	defer defaultProcessorMu.Unlock()
+	defaultProcessor = p
+}
+
+// Default returns the Processor served by the handlers, nil if the recent traces are not enabled
+func Default() *Processor {
+	defaultProcessorMu.RLock()
+	defer defaultProcessorMu.RUnlock()
+	return defaultProcessor
+}
+
+// OnStart implements sdktrace.SpanProcessor
+func (p *Processor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor
+func (p *Processor) OnEnd(s sdktrace.ReadOnlySpan) {
+	id := s.SpanContext().TraceID()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.completed[id]; ok {
+		// span ended after the local root, e.g. by an async goroutine
+		p.appendSpan(t, s)
+		t.isError = t.isError || s.Status().Code == codes.Error
+		return
+	}
+	t, ok := p.pending[id]
+	if !ok {
+		t = &storedTrace{id: id}
+		p.addPending(t)
+	}
+	p.appendSpan(t, s)
+	if !isLocalRoot(s) {
+		return
+	}
+	delete(p.pending, id)
+	t.root = s
+	t.isError = hasError(t.spans)
+	p.complete(t)
+}
+
+// Shutdown implements sdktrace.SpanProcessor
+func (p *Processor) Shutdown(context.Context) error { return nil }
+
+// ForceFlush implements sdktrace.SpanProcessor
+func (p *Processor) ForceFlush(context.Context) error { return nil }
+
+func (p *Processor) appendSpan(t *storedTrace, s sdktrace.ReadOnlySpan) {
+	if len(t.spans) >= p.cfg.MaxSpansPerTrace {
+		t.dropped++
+		return
+	}
+	t.spans = append(t.spans, s)
+}
+
+func (p *Processor) addPending(t *storedTrace) {
+	for len(p.pending) >= p.cfg.MaxPendingTraces && len(p.pendingOrder) > 0 {
+		delete(p.pending, p.pendingOrder[0])
+		p.pendingOrder = p.pendingOrder[1:]
+	}
+	p.pending[t.id] = t
+	p.pendingOrder = append(p.pendingOrder, t.id)
+	// the completed ones are left in pendingOrder, compact it when it is much longer than pending
+	if len(p.pendingOrder) > 2*p.cfg.MaxPendingTraces {
+		order := make([]trace.TraceID, 0, len(p.pending))
+		for _, id := range p.pendingOrder {
+			if _, ok := p.pending[id]; ok {
+				order = append(order, id)
+			}
+		}
+		p.pendingOrder = order
+	}
+}
+
+func (p *Processor) complete(t *storedTrace) {
+	c := classNormal
+	switch {
+	case t.isError:
+		c = classError
+	case duration(t.root) >= p.cfg.SlowThreshold:
+		c = classSlow
+	}
+	limit := [numClasses]int{p.cfg.MaxTraces, p.cfg.MaxSlowTraces, p.cfg.MaxErrorTraces}[c]
+	ring := p.rings[c]
+	if len(ring) >= limit {
+		delete(p.completed, ring[0])
+		ring = ring[1:]
+	}
+	p.rings[c] = append(ring, t.id)
+	p.completed[t.id] = t
+}
+
+func isLocalRoot(s sdktrace.ReadOnlySpan) bool {
+	parent := s.Parent()
+	return !parent.IsValid() || parent.IsRemote()
+}
+
+func hasError(spans []sdktrace.ReadOnlySpan) bool {
+	for _, s := range spans {
+		if s.Status().Code == codes.Error {
+			return true
+		}
+	}
+	return false
+}
+
+func duration(s sdktrace.ReadOnlySpan) time.Duration {
+	return s.EndTime().Sub(s.StartTime())
+}
+
+// Filter of the traces listed by Traces, the zero value matches all traces
+type Filter struct {
+	Service string
+	Method  string
+	// Status "ok" or "error", empty matches both
+	Status      string
+	MinDuration time.Duration
+	// Limit max number of the traces, all if not positive
+	Limit int
+}
+
+// Summary of a completed trace
+type Summary struct {
+	TraceID   string        `json:"trace_id"`
+	Name      string        `json:"name"`
+	Service   string        `json:"service"`
+	Method    string        `json:"method"`
+	Start     time.Time     `json:"start"`
+	Duration  time.Duration `json:"duration"`
+	Status    string        `json:"status"`
+	Slow      bool          `json:"slow"`
+	SpanCount int           `json:"span_count"`
+	Dropped   int           `json:"dropped_spans,omitempty"`
+}
+
+// Traces returns the summaries of the completed traces matching f, the most recent first.
+func (p *Processor) Traces(f Filter) []Summary {
+	p.mu.Lock()
+	summaries := make([]Summary, 0, len(p.completed))
+	for _, t := range p.completed {
+		if s := p.summary(t); f.match(s) {
+			summaries = append(summaries, s)
+		}
+	}
+	p.mu.Unlock()
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Start.Add(summaries[i].Duration).After(summaries[j].Start.Add(summaries[j].Duration))
+	})
+	if f.Limit > 0 && len(summaries) > f.Limit {
+		summaries = summaries[:f.Limit]
+	}
+	return summaries
+}
+
+func (p *Processor) summary(t *storedTrace) Summary {
+	service, method := serviceMethod(t.root)
+	status := "ok"
+	if t.isError {
+		status = "error"
+	}
+	d := duration(t.root)
+	return Summary{
+		TraceID:   t.id.String(),
+		Name:      t.root.Name(),
+		Service:   service,
+		Method:    method,
+		Start:     t.root.StartTime(),
+		Duration:  d,
+		Status:    status,
+		Slow:      d >= p.cfg.SlowThreshold,
+		SpanCount: len(t.spans),
+		Dropped:   t.dropped,
+	}
+}
+
+func (f Filter) match(s Summary) bool {
+	return (f.Service == "" || f.Service == s.Service) &&
+		(f.Method == "" || f.Method == s.Method) &&
+		(f.Status == "" || f.Status == s.Status) &&
+		s.Duration >= f.MinDuration
+}
+
+// Trace returns the spans of the completed trace id
+func (p *Processor) Trace(id trace.TraceID) ([]sdktrace.ReadOnlySpan, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	t, ok := p.completed[id]
+	if !ok {
+		return nil, false
+	}
+	return append([]sdktrace.ReadOnlySpan(nil), t.spans...), true
+}
+
+func serviceMethod(s sdktrace.ReadOnlySpan) (service, method string) {
+	var rpcService, rpcMethod string
+	for _, kv := range s.Attributes() {
+		switch kv.Key {
+		case trpcCalleeServiceKey:
+			service = kv.Value.AsString()
+		case trpcCalleeMethodKey:
+			method = kv.Value.AsString()
+		case rpcServiceKey:
+			rpcService = kv.Value.AsString()
+		case rpcMethodKey:
+			rpcMethod = kv.Value.AsString()
+		}
+	}
+	if service == "" {
+		service = rpcService
+	}
+	if method == "" {
+		method = rpcMethod
+	}
+	return service, method
+}
@@ -0,0 +1,153 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package tracestore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	traceproto "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// startTrace records a server span of service/method with a child span, it returns the trace id
+func startTrace(tp trace.TracerProvider, method string, d time.Duration, isError bool) trace.TraceID {
+	tracer := tp.Tracer("test")
+	start := time.Now().Add(-d)
+	ctx, root := tracer.Start(context.Background(), "/trpc.app.server.Greeter"+method,
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithTimestamp(start),
+		trace.WithAttributes(attribute.String("trpc.callee_service", "trpc.app.server.Greeter"),
+			attribute.String("trpc.callee_method", method)))
+	_, child := tracer.Start(ctx, "db", trace.WithTimestamp(start))
+	if isError {
+		child.SetStatus(codes.Error, "timeout")
+	}
+	child.End()
+	root.End(trace.WithTimestamp(start.Add(d)))
+	return root.SpanContext().TraceID()
+}
+
+func TestProcessor(t *testing.T) {
+	p := NewProcessor(Config{MaxTraces: 2, MaxSlowTraces: 1, MaxErrorTraces: 1, SlowThreshold: time.Second})
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p))
+
+	errorID := startTrace(tp, "/Error", time.Millisecond, true)
+	slowID := startTrace(tp, "/Slow", 2*time.Second, false)
+	for i := 0; i < 3; i++ {
+		startTrace(tp, "/SayHello", time.Millisecond, false)
+	}
+
+	all := p.Traces(Filter{})
+	require.Len(t, all, 4, "normal traces beyond MaxTraces are evicted, error and slow ones are kept")
+	errors := p.Traces(Filter{Status: "error"})
+	require.Len(t, errors, 1)
+	require.Equal(t, errorID.String(), errors[0].TraceID)
+	require.Equal(t, "trpc.app.server.Greeter", errors[0].Service)
+	require.Equal(t, 2, errors[0].SpanCount)
+	slow := p.Traces(Filter{MinDuration: time.Second})
+	require.Len(t, slow, 1)
+	require.Equal(t, slowID.String(), slow[0].TraceID)
+	require.True(t, slow[0].Slow)
+	require.Len(t, p.Traces(Filter{Method: "/SayHello"}), 2)
+	require.Len(t, p.Traces(Filter{Limit: 1}), 1)
+
+	spans, ok := p.Trace(errorID)
+	require.True(t, ok)
+	roots := Tree(spans)
+	require.Len(t, roots, 1)
+	require.Equal(t, "/trpc.app.server.Greeter/Error", roots[0].Name)
+	require.Len(t, roots[0].Children, 1)
+	require.Equal(t, "Error", roots[0].Children[0].Status)
+
+	// the error trace is evicted by a newer one
+	startTrace(tp, "/Error", time.Millisecond, true)
+	_, ok = p.Trace(errorID)
+	require.False(t, ok)
+}
+
+func TestProcessor_MaxPendingTraces(t *testing.T) {
+	p := NewProcessor(Config{MaxPendingTraces: 1})
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p))
+	tracer := tp.Tracer("test")
+	ctx1, root1 := tracer.Start(context.Background(), "first")
+	_, child1 := tracer.Start(ctx1, "child")
+	child1.End()
+	ctx2, root2 := tracer.Start(context.Background(), "second")
+	_, child2 := tracer.Start(ctx2, "child")
+	child2.End()
+	root2.End()
+	root1.End()
+
+	spans, ok := p.Trace(root1.SpanContext().TraceID())
+	require.True(t, ok)
+	require.Len(t, spans, 1, "the pending child of the first trace is dropped")
+	spans, ok = p.Trace(root2.SpanContext().TraceID())
+	require.True(t, ok)
+	require.Len(t, spans, 2)
+}
+
+func TestHandlers(t *testing.T) {
+	SetDefault(nil)
+	w := httptest.NewRecorder()
+	ListHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces", nil))
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	p := NewProcessor(Config{})
+	SetDefault(p)
+	defer SetDefault(nil)
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(p))
+	id := startTrace(tp, "/SayHello", time.Millisecond, true)
+
+	w = httptest.NewRecorder()
+	ListHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces?status=error&format=json", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var summaries []Summary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 1)
+
+	w = httptest.NewRecorder()
+	ListHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces?min_duration=x", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	ListHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces", nil))
+	require.Contains(t, w.Body.String(), "traces/trace?id="+id.String())
+
+	w = httptest.NewRecorder()
+	TraceHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces/trace?id="+id.String(), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "/trpc.app.server.Greeter/SayHello")
+
+	w = httptest.NewRecorder()
+	TraceHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces/trace?id=abc", nil))
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	OTLPHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/traces/otlp?id="+id.String(), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Header().Get("Content-Disposition"), id.String())
+	var data traceproto.TracesData
+	require.NoError(t, protojson.Unmarshal(w.Body.Bytes(), &data))
+	require.Len(t, data.ResourceSpans, 1)
+	require.Len(t, data.ResourceSpans[0].ScopeSpans[0].Spans, 2)
+}
@@ -0,0 +1,101 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package tracestore
+
+import (
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanNode a span in the tree of a trace
+type SpanNode struct {
+	SpanID        string                 `json:"span_id"`
+	ParentSpanID  string                 `json:"parent_span_id,omitempty"`
+	Name          string                 `json:"name"`
+	Kind          string                 `json:"kind"`
+	Start         time.Time              `json:"start"`
+	Duration      time.Duration          `json:"duration"`
+	Status        string                 `json:"status"`
+	StatusMessage string                 `json:"status_message,omitempty"`
+	Attributes    map[string]interface{} `json:"attributes,omitempty"`
+	Events        []Event                `json:"events,omitempty"`
+	Children      []*SpanNode            `json:"children,omitempty"`
+}
+
+// Event an event of a span
+type Event struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// Tree builds the trees of spans, the spans whose parent is absent are the roots, ordered by start time.
+func Tree(spans []sdktrace.ReadOnlySpan) []*SpanNode {
+	nodes := make(map[string]*SpanNode, len(spans))
+	for _, s := range spans {
+		nodes[s.SpanContext().SpanID().String()] = newSpanNode(s)
+	}
+	var roots []*SpanNode
+	for _, s := range spans {
+		n := nodes[s.SpanContext().SpanID().String()]
+		if parent, ok := nodes[n.ParentSpanID]; ok && n.ParentSpanID != "" {
+			parent.Children = append(parent.Children, n)
+			continue
+		}
+		roots = append(roots, n)
+	}
+	sortNodes(roots)
+	return roots
+}
+
+func sortNodes(nodes []*SpanNode) {
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Start.Before(nodes[j].Start) })
+	for _, n := range nodes {
+		sortNodes(n.Children)
+	}
+}
+
+func newSpanNode(s sdktrace.ReadOnlySpan) *SpanNode {
+	n := &SpanNode{
+		SpanID:        s.SpanContext().SpanID().String(),
+		Name:          s.Name(),
+		Kind:          s.SpanKind().String(),
+		Start:         s.StartTime(),
+		Duration:      duration(s),
+		Status:        s.Status().Code.String(),
+		StatusMessage: s.Status().Description,
+		Attributes:    attributeMap(s.Attributes()),
+	}
+	if s.Parent().IsValid() {
+		n.ParentSpanID = s.Parent().SpanID().String()
+	}
+	for _, e := range s.Events() {
+		n.Events = append(n.Events, Event{Name: e.Name, Time: e.Time, Attributes: attributeMap(e.Attributes)})
+	}
+	return n
+}
+
+func attributeMap(kvs []attribute.KeyValue) map[string]interface{} {
+	if len(kvs) == 0 {
+		return nil
+	}
+	m := make(map[string]interface{}, len(kvs))
+	for _, kv := range kvs {
+		m[string(kv.Key)] = kv.Value.AsInterface()
+	}
+	return m
+}
@@ -25,6 +25,7 @@ import (
 
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/debug"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 )
 
 const (
@@ -121,6 +122,11 @@ func (bp *BatchProcessor) Enqueue(rl *logsproto.ResourceLogs) {
 	}
 }
 
+// QueueStatus returns the depth of the queue and the totals of the logs.
+func (bp *BatchProcessor) QueueStatus() telemetrystatus.QueueStatus {
+	return telemetrystatus.NewQueueStatus(len(bp.queue), cap(bp.queue), "logs")
+}
+
 func (bp *BatchProcessor) shouldProcessInBatch() bool {
 	if len(bp.batch) == DefaultMaxExportBatchSize {
 		return true
@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"time"
 
+	"trpc.group/trpc-go/trpc-opentelemetry/exporter/retry"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/remote"
 )
 
@@ -30,8 +32,14 @@ type Config struct {
 	Enabled bool `yaml:"enabled"`
 	// EnabledRegister default enabled
 	EnabledRegister bool `yaml:"enable_register"`
+	// Registry registry of EnabledRegister: etcd (default), file_sd or http_sd
+	Registry string `yaml:"registry"`
 	// RegistryEndpoints registry addrs
 	RegistryEndpoints []string `yaml:"registry_endpoints"`
+	// FileSD file_sd registry config
+	FileSD FileSDConfig `yaml:"file_sd"`
+	// HTTPSD http_sd registry config
+	HTTPSD HTTPSDConfig `yaml:"http_sd"`
 	// TLS credentials
 	TLSCert TLSCert `yaml:"tls_cert"`
 	// TTL Time to live
@@ -54,8 +62,29 @@ type Config struct {
 	ClientStreamRecvHistogramBuckets []float64 `yaml:"client_stream_recv_histogram_buckets"`
 	// ClientStreamSendHistogramBuckets user can config client stream recv histogram buckets
 	ClientStreamSendHistogramBuckets []float64 `yaml:"client_stream_send_histogram_buckets"`
+	// ClientHistogramBucketsOverrides user can override client histogram buckets by callee service/method
+	ClientHistogramBucketsOverrides []HistogramBucketsOverride `yaml:"client_histogram_buckets_overrides"`
+	// ServerHistogramBucketsOverrides user can override server histogram buckets by callee service/method
+	ServerHistogramBucketsOverrides []HistogramBucketsOverride `yaml:"server_histogram_buckets_overrides"`
+	// Sinks sinks of the trpc metrics API: prometheus (default) and otlp
+	Sinks []string `yaml:"sinks"`
+	// SinkHistogramBuckets histogram buckets of the trpc metrics API by metric name
+	SinkHistogramBuckets map[string][]float64 `yaml:"sink_histogram_buckets"`
+	// EnabledPayloadSizeHistogram report request/response body and metadata size histograms,
+	// and message size histograms of stream rpc
+	EnabledPayloadSizeHistogram bool `yaml:"enabled_payload_size_histogram"`
+	// PeerMetrics per-peer client metrics config
+	PeerMetrics PeerMetricsConfig `yaml:"peer_metrics"`
+	// RuntimeMetrics Go runtime/metrics collector config
+	RuntimeMetrics RuntimeMetricsConfig `yaml:"runtime_metrics"`
+	// NativeHistogram prometheus native histogram config
+	NativeHistogram NativeHistogramConfig `yaml:"native_histogram"`
 	// PrometheusPush prometheus push config
 	PrometheusPush PrometheusPushConfig `yaml:"prometheus_push"`
+	// RemoteWrite prometheus remote-write config
+	RemoteWrite RemoteWriteConfig `yaml:"remote_write"`
+	// AdminAuth TLS, authentication and path allowlist of the admin endpoints
+	AdminAuth auth.Config `yaml:"admin_auth"`
 	// EnabledZPage zPage option
 	EnabledZPage bool
 }
@@ -64,6 +93,16 @@ type Config struct {
 func DefaultConfig() *Config {
 	return &Config{
 		EnabledRegister: true,
+		NativeHistogram: DefaultNativeHistogramConfig(),
+	}
+}
+
+// DefaultNativeHistogramConfig default native histogram config
+func DefaultNativeHistogramConfig() NativeHistogramConfig {
+	return NativeHistogramConfig{
+		Schema:           3,
+		MaxBucketNumber:  160,
+		MinResetDuration: time.Hour,
 	}
 }
 
@@ -144,6 +183,49 @@ func (ins *Instance) GetValue() string {
 	return "{}"
 }
 
+const (
+	// RegistryEtcd registers the instance to etcd with RegistryEndpoints
+	RegistryEtcd = "etcd"
+	// RegistryFileSD writes the instance to a Prometheus file_sd JSON file
+	RegistryFileSD = "file_sd"
+	// RegistryHTTPSD registers the instance to an http_sd registration endpoint with heartbeats
+	RegistryHTTPSD = "http_sd"
+)
+
+const (
+	// SinkPrometheus reports the trpc metrics API to prometheus collectors
+	SinkPrometheus = "prometheus"
+	// SinkOTLP reports the trpc metrics API to the OTLP MeterProvider
+	SinkOTLP = "otlp"
+)
+
+// SinkEnabled returns if the sink of the trpc metrics API is in sinks, prometheus is enabled if sinks is empty.
+func SinkEnabled(sinks []string, sink string) bool {
+	if len(sinks) == 0 {
+		return sink == SinkPrometheus
+	}
+	for _, s := range sinks {
+		if s == sink {
+			return true
+		}
+	}
+	return false
+}
+
+// FileSDConfig file_sd registry config
+type FileSDConfig struct {
+	// Path file_sd JSON file, use a glob such as `targets/*.json` in file_sd_configs for multiple processes
+	Path string `yaml:"path"`
+}
+
+// HTTPSDConfig http_sd registry config
+type HTTPSDConfig struct {
+	// URL registration endpoint, see NewHTTPSDHandler
+	URL string `yaml:"url"`
+	// HTTPHeaders headers of registration requests, e.g. Authorization
+	HTTPHeaders map[string]string `yaml:"http_headers"`
+}
+
 // PrometheusPushConfig prometheus push config
 type PrometheusPushConfig struct {
 	Enabled      bool              `yaml:"enabled"`
@@ -156,3 +238,108 @@ type PrometheusPushConfig struct {
 	Grouping     map[string]string `yaml:"grouping"`
 	HTTPHeaders  map[string]string `yaml:"http_headers"`
 }
+
+// RemoteWriteConfig prometheus remote-write config, metrics of the default registry are sent to URL
+// every Interval, with Instance.Metadata and ExternalLabels as external labels.
+type RemoteWriteConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// URL remote-write endpoint, e.g. http://127.0.0.1:9090/api/v1/write
+	URL string `yaml:"url"`
+	// Interval interval of gathering and sending, default 15s
+	Interval time.Duration `yaml:"interval"`
+	// Timeout timeout of each request, default 10s
+	Timeout time.Duration `yaml:"timeout"`
+	// Username basic auth username, basic auth is disabled if empty
+	Username string `yaml:"username"`
+	// Password basic auth password
+	Password string `yaml:"password"`
+	// BearerToken sent as `Authorization: Bearer <token>`, it takes precedence over basic auth
+	BearerToken string `yaml:"bearer_token"`
+	// ExternalLabels labels added to all series besides Instance.Metadata, they override the metadata
+	ExternalLabels map[string]string `yaml:"external_labels"`
+	// HTTPHeaders extra headers of requests
+	HTTPHeaders map[string]string `yaml:"http_headers"`
+	// MaxRetryElapsedTime max time of retrying a failed request with backoff, default 5s, negative disables retry
+	MaxRetryElapsedTime time.Duration `yaml:"max_retry_elapsed_time"`
+}
+
+func (c RemoteWriteConfig) withDefaults() RemoteWriteConfig {
+	if c.Interval <= 0 {
+		c.Interval = 15 * time.Second
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.MaxRetryElapsedTime == 0 {
+		c.MaxRetryElapsedTime = retry.DefaultConfig.MaxElapsedTime
+	}
+	return c
+}
+
+// NativeHistogramConfig prometheus native (sparse) histogram config.
+// Native histograms are only exposed by the protobuf scrape format,
+// classic buckets are still exposed at the same time for compatibility.
+type NativeHistogramConfig struct {
+	// Enabled open or close
+	Enabled bool `yaml:"enabled"`
+	// Schema resolution of buckets, from -4 (coarsest) to 8 (finest).
+	// The growth factor from one bucket to the next is 2^(2^-schema), e.g. schema 3 means about 1.09.
+	Schema int32 `yaml:"schema"`
+	// ZeroThreshold observations whose absolute value is not greater than it are counted in the zero bucket,
+	// 0 means prometheus.DefNativeHistogramZeroThreshold.
+	ZeroThreshold float64 `yaml:"zero_threshold"`
+	// MaxBucketNumber max number of populated buckets, 0 means unlimited
+	MaxBucketNumber uint32 `yaml:"max_bucket_number"`
+	// MinResetDuration histogram is reset when MaxBucketNumber is exceeded and MinResetDuration has passed
+	MinResetDuration time.Duration `yaml:"min_reset_duration"`
+}
+
+// HistogramBucketsOverride overrides the histogram buckets of matched service/method
+type HistogramBucketsOverride struct {
+	// Service empty means full match
+	Service string `yaml:"service"`
+	// Method empty means full match
+	Method string `yaml:"method"`
+	// Buckets histogram buckets, same limits as ClientHistogramBuckets/ServerHistogramBuckets
+	Buckets []float64 `yaml:"buckets"`
+}
+
+// PeerMetricsConfig per-peer (callee instance address) client metrics config.
+// Error rate and latency percentiles of each peer are computed over a sliding window,
+// a peer is flagged as outlier if it deviates from the median of peers of the same callee service.
+type PeerMetricsConfig struct {
+	// Enabled open or close, default false
+	Enabled bool `yaml:"enabled"`
+	// MaxPeers max number of tracked callee_service/peer pairs, default 100, including the one of
+	// callee_service "other" and peer "other", which aggregates the new peers beyond it.
+	MaxPeers int `yaml:"max_peers"`
+	// Window sliding window, default 1m, shorter windows are raised to 6ms, i.e. 1ms per slot
+	Window time.Duration `yaml:"window"`
+	// MinRequests peers with fewer requests in the window are not judged, default 20
+	MinRequests int `yaml:"min_requests"`
+	// ErrorRateThreshold peer is an outlier if its error rate exceeds the median by it, default 0.1
+	ErrorRateThreshold float64 `yaml:"error_rate_threshold"`
+	// LatencyRatio peer is an outlier if its p99 latency exceeds the median p99 * LatencyRatio, default 3
+	LatencyRatio float64 `yaml:"latency_ratio"`
+}
+
+func (c PeerMetricsConfig) withDefaults() PeerMetricsConfig {
+	if c.MaxPeers <= 0 {
+		c.MaxPeers = 100
+	}
+	if c.Window <= 0 {
+		c.Window = time.Minute
+	} else if c.Window < minPeerWindow {
+		c.Window = minPeerWindow
+	}
+	if c.MinRequests <= 0 {
+		c.MinRequests = 20
+	}
+	if c.ErrorRateThreshold <= 0 {
+		c.ErrorRateThreshold = 0.1
+	}
+	if c.LatencyRatio <= 0 {
+		c.LatencyRatio = 3
+	}
+	return c
+}
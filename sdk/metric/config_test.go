@@ -14,7 +14,11 @@
 // Package metric
 package metric
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func Test_toKey(t *testing.T) {
 	type args struct {
@@ -106,3 +110,11 @@ func Test_toValue(t *testing.T) {
 		})
 	}
 }
+
+func TestSinkEnabled(t *testing.T) {
+	assert.True(t, SinkEnabled(nil, SinkPrometheus))
+	assert.False(t, SinkEnabled(nil, SinkOTLP))
+	assert.False(t, SinkEnabled([]string{SinkOTLP}, SinkPrometheus))
+	assert.True(t, SinkEnabled([]string{SinkOTLP}, SinkOTLP))
+	assert.True(t, SinkEnabled([]string{SinkPrometheus, SinkOTLP}, SinkPrometheus))
+}
@@ -0,0 +1,137 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package metric
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	cgroups "trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups/cgroupsv2"
+	pkgruntime "trpc.group/trpc-go/trpc-opentelemetry/pkg/runtime"
+)
+
+// pressureFunc reads the PSI of a resource.
+type pressureFunc func(resource string) (*cgroups.PSI, bool, error)
+
+func init() {
+	if !pkgruntime.ProcessInContainer() {
+		return
+	}
+	cg, err := pkgruntime.CurrentCGroup()
+	if err != nil {
+		return
+	}
+	prometheus.MustRegister(newContainerCollectors(cg, func(resource string) (*cgroups.PSI, bool, error) {
+		return pkgruntime.Pressure(cg, resource)
+	})...)
+}
+
+// newContainerCollectors returns the collectors of container cpu, memory and pressure.
+// They are GaugeFuncs reading cgroup files lazily on collecting,
+// and only the metrics available on the current system are returned.
+func newContainerCollectors(cg pkgruntime.CGroup, readPressure pressureFunc) []prometheus.Collector {
+	var collectors []prometheus.Collector
+	newGaugeFunc := func(name, help string, f func() float64) {
+		collectors = append(collectors, prometheus.NewGaugeFunc(
+			prometheus.GaugeOpts{Subsystem: "container", Name: name, Help: help}, f))
+	}
+
+	if _, defined, err := cg.CPUStat(); err == nil && defined {
+		cpuStat := func(f func(*cgroups.CPUStat) float64) func() float64 {
+			return func() float64 {
+				s, defined, err := cg.CPUStat()
+				if err != nil || !defined {
+					return 0
+				}
+				return f(s)
+			}
+		}
+		newGaugeFunc("cpu_usage_seconds", "Total CPU time consumed by the container in seconds.",
+			cpuStat(func(s *cgroups.CPUStat) float64 { return s.Usage.Seconds() }))
+		newGaugeFunc("cpu_periods", "Number of elapsed CFS enforcement periods (nr_periods in cpu.stat).",
+			cpuStat(func(s *cgroups.CPUStat) float64 { return float64(s.Periods) }))
+		newGaugeFunc("cpu_throttled_periods", "Number of throttled CFS periods (nr_throttled in cpu.stat).",
+			cpuStat(func(s *cgroups.CPUStat) float64 { return float64(s.Throttled) }))
+		newGaugeFunc("cpu_throttled_seconds", "Total time the container has been throttled in seconds.",
+			cpuStat(func(s *cgroups.CPUStat) float64 { return s.ThrottledTime.Seconds() }))
+	}
+
+	if _, defined, err := cg.MemoryWorkingSet(); err == nil && defined {
+		newGaugeFunc("memory_working_set_bytes",
+			"Working set memory of the container, which is the usage minus inactive file cache.",
+			func() float64 {
+				v, _, _ := cg.MemoryWorkingSet()
+				return float64(v)
+			})
+	}
+
+	if _, defined, err := cg.OOMKills(); err == nil && defined {
+		newGaugeFunc("memory_oom_kills", "Number of processes killed by the OOM killer in the container.",
+			func() float64 {
+				v, _, _ := cg.OOMKills()
+				return float64(v)
+			})
+	}
+
+	for _, resource := range []string{cgroups.PressureCPU, cgroups.PressureMemory, cgroups.PressureIO} {
+		if _, defined, err := readPressure(resource); err != nil || !defined {
+			continue
+		}
+		collectors = append(collectors, newPressureCollectors(resource, readPressure)...)
+	}
+	return collectors
+}
+
+// newPressureCollectors returns the stall percentages over 10s/60s/300s and the total stall time
+// of some/full tasks of the resource.
+func newPressureCollectors(resource string, readPressure pressureFunc) []prometheus.Collector {
+	var collectors []prometheus.Collector
+	read := func(f func(*cgroups.PSI) float64) func() float64 {
+		return func() float64 {
+			psi, defined, err := readPressure(resource)
+			if err != nil || !defined {
+				return 0
+			}
+			return f(psi)
+		}
+	}
+	for kind, stats := range map[string]func(*cgroups.PSI) *cgroups.PSIStats{
+		"some": func(psi *cgroups.PSI) *cgroups.PSIStats { return &psi.Some },
+		"full": func(psi *cgroups.PSI) *cgroups.PSIStats { return &psi.Full },
+	} {
+		stats := stats
+		for window, avg := range map[string]func(*cgroups.PSIStats) float64{
+			"10s":  func(s *cgroups.PSIStats) float64 { return s.Avg10 },
+			"60s":  func(s *cgroups.PSIStats) float64 { return s.Avg60 },
+			"300s": func(s *cgroups.PSIStats) float64 { return s.Avg300 },
+		} {
+			avg := avg
+			collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+				Subsystem:   "container",
+				Name:        "pressure_stall_percent",
+				Help:        "Percentage of time tasks are stalled on the resource over the window (PSI).",
+				ConstLabels: prometheus.Labels{"resource": resource, "kind": kind, "window": window},
+			}, read(func(psi *cgroups.PSI) float64 { return avg(stats(psi)) })))
+		}
+		collectors = append(collectors, prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Subsystem:   "container",
+			Name:        "pressure_stall_seconds",
+			Help:        "Total time tasks are stalled on the resource in seconds (PSI).",
+			ConstLabels: prometheus.Labels{"resource": resource, "kind": kind},
+		}, read(func(psi *cgroups.PSI) float64 { return stats(psi).Total.Seconds() })))
+	}
+	return collectors
+}
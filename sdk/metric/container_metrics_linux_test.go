@@ -0,0 +1,106 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build linux
+// +build linux
+
+package metric
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cgroups "trpc.group/trpc-go/trpc-opentelemetry/pkg/cgroups/cgroupsv2"
+)
+
+type fakeCGroup struct {
+	cpuStat    *cgroups.CPUStat
+	workingSet int64
+}
+
+func (c *fakeCGroup) CPUQuota() (float64, bool, error) { return 2, true, nil }
+
+func (c *fakeCGroup) CPUStat() (*cgroups.CPUStat, bool, error) {
+	return c.cpuStat, c.cpuStat != nil, nil
+}
+
+func (c *fakeCGroup) MemoryQuota() (int64, bool, error) { return 1 << 30, true, nil }
+
+func (c *fakeCGroup) MemoryUsage() (int64, bool, error) { return c.workingSet, true, nil }
+
+func (c *fakeCGroup) MemoryWorkingSet() (int64, bool, error) { return c.workingSet, true, nil }
+
+func (c *fakeCGroup) OOMKills() (uint64, bool, error) { return 0, false, nil }
+
+func (c *fakeCGroup) Pressure(string) (*cgroups.PSI, bool, error) { return nil, false, nil }
+
+func TestContainerCollectors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "memory"), []byte(
+		"some avg10=1.25 avg60=0.50 avg300=0.10 total=3000000\n"+
+			"full avg10=0.75 avg60=0.25 avg300=0.05 total=1000000\n"), 0600))
+	readPressure := func(resource string) (*cgroups.PSI, bool, error) {
+		return cgroups.ReadPressure(filepath.Join(dir, resource))
+	}
+
+	cg := &fakeCGroup{
+		cpuStat:    &cgroups.CPUStat{Usage: 90 * time.Second, Periods: 100, Throttled: 10, ThrottledTime: time.Second},
+		workingSet: 1 << 20,
+	}
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(newContainerCollectors(cg, readPressure)...)
+
+	// values are read lazily
+	cg.cpuStat.Throttled = 20
+	mfs := gatherFamilies(t, reg)
+	gauge := func(name string) float64 {
+		require.Contains(t, mfs, name)
+		return mfs[name].GetMetric()[0].GetGauge().GetValue()
+	}
+	assert.Equal(t, 90.0, gauge("container_cpu_usage_seconds"))
+	assert.Equal(t, 100.0, gauge("container_cpu_periods"))
+	assert.Equal(t, 20.0, gauge("container_cpu_throttled_periods"))
+	assert.Equal(t, 1.0, gauge("container_cpu_throttled_seconds"))
+	assert.Equal(t, float64(1<<20), gauge("container_memory_working_set_bytes"))
+	assert.NotContains(t, mfs, "container_memory_oom_kills")
+
+	// only memory pressure is available
+	require.Len(t, mfs["container_pressure_stall_percent"].GetMetric(), 6)
+	for _, m := range mfs["container_pressure_stall_percent"].GetMetric() {
+		assert.Equal(t, "memory", labelValue(m, "resource"))
+		if labelValue(m, "kind") == "some" && labelValue(m, "window") == "10s" {
+			assert.Equal(t, 1.25, m.GetGauge().GetValue())
+		}
+	}
+	stall := map[string]float64{}
+	for _, m := range mfs["container_pressure_stall_seconds"].GetMetric() {
+		stall[labelValue(m, "kind")] = m.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"some": 3, "full": 1}, stall)
+}
+
+func TestContainerCollectors_Unavailable(t *testing.T) {
+	readPressure := func(string) (*cgroups.PSI, bool, error) { return nil, false, nil }
+	collectors := newContainerCollectors(&fakeCGroup{workingSet: -1}, readPressure)
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors...)
+	mfs := gatherFamilies(t, reg)
+	assert.NotContains(t, mfs, "container_cpu_usage_seconds")
+	assert.NotContains(t, mfs, "container_pressure_stall_percent")
+}
@@ -0,0 +1,137 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"log"
+	"math"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	minNativeHistogramSchema = -4
+	maxNativeHistogramSchema = 8
+)
+
+// histogramVec is a HistogramVec whose buckets can be overridden by service/method.
+// All the underlying HistogramVecs share the same Desc, so it is registered as one collector.
+type histogramVec struct {
+	defaultVec *prometheus.HistogramVec
+	overrides  []histogramVecOverride
+}
+
+type histogramVecOverride struct {
+	service string
+	method  string
+	vec     *prometheus.HistogramVec
+}
+
+// newHistogramVec creates a histogramVec, invalid overrides are ignored.
+func newHistogramVec(opts prometheus.HistogramOpts, labelNames []string,
+	overrides []HistogramBucketsOverride) *histogramVec {
+	h := &histogramVec{defaultVec: prometheus.NewHistogramVec(opts, labelNames)}
+	for _, o := range sortHistogramBucketsOverrides(overrides) {
+		buckets, ok := validateBuckets(o.Buckets)
+		if !ok {
+			log.Printf("opentelemetry: invalid histogram buckets override of %s, service:%s method:%s buckets:%v",
+				opts.Name, o.Service, o.Method, o.Buckets)
+			continue
+		}
+		overrideOpts := opts
+		overrideOpts.Buckets = buckets
+		h.overrides = append(h.overrides, histogramVecOverride{
+			service: o.Service,
+			method:  o.Method,
+			vec:     prometheus.NewHistogramVec(overrideOpts, labelNames),
+		})
+	}
+	return h
+}
+
+// sortHistogramBucketsOverrides sorts overrides by precedence:
+// service+method > service > method > neither, keeping the configured order within the same level.
+func sortHistogramBucketsOverrides(overrides []HistogramBucketsOverride) []HistogramBucketsOverride {
+	precedence := func(o HistogramBucketsOverride) int {
+		switch {
+		case o.Service != "" && o.Method != "":
+			return 0
+		case o.Service != "":
+			return 1
+		case o.Method != "":
+			return 2
+		default:
+			return 3
+		}
+	}
+	sorted := make([]HistogramBucketsOverride, len(overrides))
+	copy(sorted, overrides)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return precedence(sorted[i]) < precedence(sorted[j])
+	})
+	return sorted
+}
+
+// vec returns the HistogramVec matched by service and method.
+func (h *histogramVec) vec(service, method string) *prometheus.HistogramVec {
+	for _, o := range h.overrides {
+		if (o.service == "" || o.service == service) && (o.method == "" || o.method == method) {
+			return o.vec
+		}
+	}
+	return h.defaultVec
+}
+
+// Describe implements prometheus.Collector.
+func (h *histogramVec) Describe(ch chan<- *prometheus.Desc) {
+	h.defaultVec.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (h *histogramVec) Collect(ch chan<- prometheus.Metric) {
+	h.defaultVec.Collect(ch)
+	for _, o := range h.overrides {
+		o.vec.Collect(ch)
+	}
+}
+
+// Delete deletes the metric where the variable labels are the same as those passed in as labels.
+func (h *histogramVec) Delete(labels prometheus.Labels) bool {
+	deleted := h.defaultVec.Delete(labels)
+	for _, o := range h.overrides {
+		deleted = o.vec.Delete(labels) || deleted
+	}
+	return deleted
+}
+
+// Reset deletes all metrics.
+func (h *histogramVec) Reset() {
+	h.defaultVec.Reset()
+	for _, o := range h.overrides {
+		o.vec.Reset()
+	}
+}
+
+// nativeHistogramBucketFactor converts schema to prometheus.HistogramOpts.NativeHistogramBucketFactor.
+func nativeHistogramBucketFactor(schema int32) float64 {
+	if schema < minNativeHistogramSchema {
+		schema = minNativeHistogramSchema
+	}
+	if schema > maxNativeHistogramSchema {
+		schema = maxNativeHistogramSchema
+	}
+	// the factor is slightly enlarged, so that client_golang picks exactly the configured schema.
+	return math.Pow(2, math.Pow(2, -float64(schema))*1.0001)
+}
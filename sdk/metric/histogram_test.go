@@ -0,0 +1,152 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramVec_Overrides(t *testing.T) {
+	h := newHistogramVec(prometheus.HistogramOpts{
+		Name:    "test_histogram_vec_seconds",
+		Buckets: []float64{1, 2},
+	}, []string{"callee_service", "callee_method"}, []HistogramBucketsOverride{
+		{Method: "m1", Buckets: []float64{3}},
+		{Service: "s1", Buckets: []float64{4}},
+		{Service: "s1", Method: "m1", Buckets: []float64{5}},
+		{Service: "s2", Buckets: []float64{2, 1}}, // invalid, ignored
+	})
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(&LimitCardinalityCollector{h, "test", 10})
+
+	tests := []struct {
+		service string
+		method  string
+		want    []float64
+	}{
+		{"s1", "m1", []float64{5}},
+		{"s1", "m2", []float64{4}},
+		{"s2", "m1", []float64{3}},
+		{"s2", "m2", []float64{1, 2}},
+		{"s3", "m3", []float64{1, 2}},
+	}
+	for _, tt := range tests {
+		h.vec(tt.service, tt.method).WithLabelValues(tt.service, tt.method).Observe(0.5)
+	}
+	mfs, err := reg.Gather()
+	require.NoError(t, err)
+	require.Len(t, mfs, 1)
+	require.Len(t, mfs[0].GetMetric(), len(tests))
+	got := make(map[string][]float64)
+	for _, m := range mfs[0].GetMetric() {
+		var bounds []float64
+		for _, b := range m.GetHistogram().GetBucket() {
+			bounds = append(bounds, b.GetUpperBound())
+		}
+		got[labelValue(m, "callee_service")+"/"+labelValue(m, "callee_method")] = bounds
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, got[tt.service+"/"+tt.method], tt.service+"/"+tt.method)
+	}
+
+	assert.True(t, h.Delete(prometheus.Labels{"callee_service": "s1", "callee_method": "m1"}))
+	h.Reset()
+	mfs, err = reg.Gather()
+	require.NoError(t, err)
+	assert.Len(t, mfs, 0)
+}
+
+func TestWithHistogramNative(t *testing.T) {
+	for schema := int32(minNativeHistogramSchema - 1); schema <= maxNativeHistogramSchema+1; schema++ {
+		cfg := DefaultNativeHistogramConfig()
+		cfg.Enabled = true
+		cfg.Schema = schema
+		cfg.ZeroThreshold = 0.0001
+		opts := prometheus.HistogramOpts{Name: "test_native_seconds", Buckets: []float64{1}}
+		WithHistogramNative(cfg)(&opts)
+		h := prometheus.NewHistogram(opts)
+		h.Observe(0.5)
+		m := &dto.Metric{}
+		require.NoError(t, h.Write(m))
+
+		want := schema
+		if want < minNativeHistogramSchema {
+			want = minNativeHistogramSchema
+		}
+		if want > maxNativeHistogramSchema {
+			want = maxNativeHistogramSchema
+		}
+		assert.Equal(t, want, m.GetHistogram().GetSchema())
+		assert.Equal(t, 0.0001, m.GetHistogram().GetZeroThreshold())
+		assert.Len(t, m.GetHistogram().GetBucket(), 1, "classic buckets kept")
+	}
+
+	opts := prometheus.HistogramOpts{Name: "test_classic_seconds"}
+	WithHistogramNative(NativeHistogramConfig{Schema: 3})(&opts)
+	assert.Zero(t, opts.NativeHistogramBucketFactor)
+}
+
+func TestLimitMetricsHandler_NativeHistogramProtobuf(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	prometheus.DefaultGatherer = reg
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+	}()
+	cfg := DefaultNativeHistogramConfig()
+	cfg.Enabled = true
+	opts := prometheus.HistogramOpts{Name: "test_native_handler_seconds", Buckets: []float64{1}}
+	WithHistogramNative(cfg)(&opts)
+	h := prometheus.NewHistogram(opts)
+	reg.MustRegister(h)
+	h.Observe(0.5)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+	rec := httptest.NewRecorder()
+	LimitMetricsHandler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	dec := expfmt.NewDecoder(rec.Body, expfmt.ResponseFormat(rec.Header()))
+	var found bool
+	for {
+		mf := &dto.MetricFamily{}
+		if err := dec.Decode(mf); err != nil {
+			break
+		}
+		if mf.GetName() == "test_native_handler_seconds" {
+			found = true
+			assert.Equal(t, int32(3), mf.GetMetric()[0].GetHistogram().GetSchema())
+		}
+	}
+	assert.True(t, found)
+}
+
+func labelValue(m *dto.Metric, name string) string {
+	for _, l := range m.GetLabel() {
+		if l.GetName() == name {
+			return l.GetValue()
+		}
+	}
+	return ""
+}
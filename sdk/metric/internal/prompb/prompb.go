@@ -0,0 +1,175 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+// Package prompb is a minimal protobuf encoding of the Prometheus remote-write 1.0 messages,
+// compatible with github.com/prometheus/prometheus/prompb without depending on the prometheus server module.
+// Only the fields used by the remote-write sender are supported, unknown fields are skipped on decoding.
+package prompb
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// WriteRequest is the body of a remote-write request.
+type WriteRequest struct {
+	Timeseries []TimeSeries // field 1
+}
+
+// TimeSeries is a series of samples with the same labels, labels must be sorted by name.
+type TimeSeries struct {
+	Labels  []Label  // field 1
+	Samples []Sample // field 2
+}
+
+// Label is a name/value pair.
+type Label struct {
+	Name  string // field 1
+	Value string // field 2
+}
+
+// Sample is a value at a timestamp in milliseconds.
+type Sample struct {
+	Value     float64 // field 1
+	Timestamp int64   // field 2
+}
+
+var errInvalidMessage = errors.New("prompb: invalid message")
+
+// Marshal encodes the request in protobuf wire format.
+func (m *WriteRequest) Marshal() ([]byte, error) {
+	var b []byte
+	for i := range m.Timeseries {
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, m.Timeseries[i].marshal())
+	}
+	return b, nil
+}
+
+func (m *TimeSeries) marshal() []byte {
+	var b []byte
+	for _, l := range m.Labels {
+		var lb []byte
+		lb = protowire.AppendTag(lb, 1, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Name)
+		lb = protowire.AppendTag(lb, 2, protowire.BytesType)
+		lb = protowire.AppendString(lb, l.Value)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, lb)
+	}
+	for _, s := range m.Samples {
+		var sb []byte
+		sb = protowire.AppendTag(sb, 1, protowire.Fixed64Type)
+		sb = protowire.AppendFixed64(sb, math.Float64bits(s.Value))
+		sb = protowire.AppendTag(sb, 2, protowire.VarintType)
+		sb = protowire.AppendVarint(sb, uint64(s.Timestamp))
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, sb)
+	}
+	return b
+}
+
+// Unmarshal decodes the request from protobuf wire format.
+func (m *WriteRequest) Unmarshal(b []byte) error {
+	*m = WriteRequest{}
+	return walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if num != 1 || typ != protowire.BytesType {
+			return nil
+		}
+		var ts TimeSeries
+		if err := ts.unmarshal(v); err != nil {
+			return err
+		}
+		m.Timeseries = append(m.Timeseries, ts)
+		return nil
+	})
+}
+
+func (m *TimeSeries) unmarshal(b []byte) error {
+	return walk(b, func(num protowire.Number, typ protowire.Type, v []byte) error {
+		if typ != protowire.BytesType {
+			return nil
+		}
+		switch num {
+		case 1:
+			var l Label
+			err := walk(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				if typ != protowire.BytesType {
+					return nil
+				}
+				switch num {
+				case 1:
+					l.Name = string(v)
+				case 2:
+					l.Value = string(v)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			m.Labels = append(m.Labels, l)
+		case 2:
+			var s Sample
+			err := walk(v, func(num protowire.Number, typ protowire.Type, v []byte) error {
+				switch {
+				case num == 1 && typ == protowire.Fixed64Type:
+					bits, _ := protowire.ConsumeFixed64(v)
+					s.Value = math.Float64frombits(bits)
+				case num == 2 && typ == protowire.VarintType:
+					ts, _ := protowire.ConsumeVarint(v)
+					s.Timestamp = int64(ts)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			m.Samples = append(m.Samples, s)
+		}
+		return nil
+	})
+}
+
+// walk calls f with each field of the message, v is the raw value of fixed and varint fields,
+// and the payload of bytes fields.
+func walk(b []byte, f func(num protowire.Number, typ protowire.Type, v []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errInvalidMessage
+		}
+		b = b[n:]
+		var v []byte
+		switch typ {
+		case protowire.BytesType:
+			payload, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return errInvalidMessage
+			}
+			v, b = payload, b[n:]
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return errInvalidMessage
+			}
+			v, b = b[:n], b[n:]
+		}
+		if err := f(num, typ, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// fileRegistry writes instances to a Prometheus file_sd JSON file,
+// instances registered by the same registry are written to the same file.
+type fileRegistry struct {
+	statusTracker
+	path string
+
+	mu     sync.Mutex
+	groups map[string]TargetGroup
+}
+
+// NewFileRegistry new registry writing file_sd target groups to path, the file is removed when all
+// instances are unregistered. Use a glob such as `targets/*.json` in file_sd_configs to discover the files
+// of multiple processes.
+func NewFileRegistry(path string) Registry {
+	return &fileRegistry{
+		statusTracker: newStatusTracker("file_sd"),
+		path:          filepath.Clean(path),
+		groups:        make(map[string]TargetGroup),
+	}
+}
+
+// Register writes the target group of the instance to the file, ttl is not used as the file does not expire,
+// the returned context.CancelFunc removes the instance from the file.
+func (f *fileRegistry) Register(ctx context.Context, ins Instance, _ time.Duration) (context.CancelFunc, error) {
+	group, err := newTargetGroup(ins)
+	if err != nil {
+		return nil, err
+	}
+	key := ins.GetKey()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.groups[key] = group
+	if err := f.write(); err != nil {
+		delete(f.groups, key)
+		f.failure(ins, err, false)
+		return nil, err
+	}
+	f.success(ins)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			delete(f.groups, key)
+			_ = f.write()
+			f.remove(ins)
+		})
+	}, nil
+}
+
+// write writes all target groups to a temporary file and renames it, so that Prometheus never reads a partial file.
+func (f *fileRegistry) write() error {
+	if len(f.groups) == 0 {
+		if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(sortedTargetGroups(f.groups), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(f.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), "."+filepath.Base(f.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,94 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTargetGroups(t *testing.T, path string) []TargetGroup {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var groups []TargetGroup
+	require.NoError(t, json.Unmarshal(data, &groups))
+	return groups
+}
+
+func TestFileRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "targets", "app.json")
+	r := NewFileRegistry(path)
+
+	cancel1, err := r.Register(context.Background(), &testInstance{
+		key:   "/b",
+		value: `{"addr":"127.0.0.1:2","tenant_id":"t1","metadata":{"app":"app1","env-name":"test"}}`,
+	}, 0)
+	require.NoError(t, err)
+	cancel2, err := r.Register(context.Background(), &testInstance{
+		key:   "/a",
+		value: `{"addr":"127.0.0.1:1","tenant_id":"t1"}`,
+	}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []TargetGroup{
+		{Targets: []string{"127.0.0.1:1"}, Labels: map[string]string{"tenant_id": "t1"}},
+		{Targets: []string{"127.0.0.1:2"}, Labels: map[string]string{"tenant_id": "t1", "app": "app1",
+			"env_name": "test"}},
+	}, readTargetGroups(t, path))
+
+	cancel2()
+	cancel2()
+	groups := readTargetGroups(t, path)
+	require.Len(t, groups, 1)
+	assert.Equal(t, []string{"127.0.0.1:2"}, groups[0].Targets)
+
+	// the file is removed when all instances are unregistered
+	cancel1()
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = r.Register(context.Background(), &testInstance{key: "/c", value: "{}"}, 0)
+	assert.Error(t, err)
+	_, err = r.Register(context.Background(), &testInstance{key: "/c", value: "invalid"}, 0)
+	assert.Error(t, err)
+}
+
+func TestFileRegistry_Status(t *testing.T) {
+	r := NewFileRegistry(filepath.Join(t.TempDir(), "app.json"))
+	ins := &testInstance{key: "/a", value: `{"addr":"127.0.0.1:1"}`}
+	cancel, err := r.Register(context.Background(), ins, 0)
+	require.NoError(t, err)
+	statuses := r.(StatusReporter).Status()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "file_sd", statuses[0].Registry)
+	assert.Equal(t, "/a", statuses[0].Key)
+	assert.True(t, statuses[0].Registered)
+	assert.False(t, statuses[0].LastSuccess.IsZero())
+	cancel()
+	assert.Empty(t, r.(StatusReporter).Status())
+}
+
+func TestSanitizeLabelName(t *testing.T) {
+	assert.Equal(t, "env_name", SanitizeLabelName("env-name"))
+	assert.Equal(t, "_1a", SanitizeLabelName("1a"))
+	assert.Equal(t, "container_name", SanitizeLabelName("container_name"))
+}
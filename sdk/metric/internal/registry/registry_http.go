@@ -0,0 +1,267 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// HTTPRegistration is the body of a registration or heartbeat request of the http registry.
+type HTTPRegistration struct {
+	TargetGroup
+	// Key Instance.GetKey
+	Key string `json:"key"`
+	// Value Instance.GetValue
+	Value string `json:"value"`
+	// TTLSeconds the registration expires if no heartbeat is received within it
+	TTLSeconds int64 `json:"ttl_seconds"`
+}
+
+// httpRegistry registers instances to an http_sd registration endpoint:
+// PUT registers the instance and is repeated as heartbeat every ttl/3,
+// DELETE ?key=$KEY unregisters it, and GET returns the target groups in the Prometheus http_sd format.
+type httpRegistry struct {
+	statusTracker
+	url     string
+	client  *http.Client
+	headers map[string]string
+}
+
+// HTTPOption http registry option
+type HTTPOption func(*httpRegistry)
+
+// WithHTTPHeaders headers of registration requests, e.g. Authorization
+func WithHTTPHeaders(headers map[string]string) HTTPOption {
+	return func(r *httpRegistry) {
+		r.headers = headers
+	}
+}
+
+// WithHTTPClient http client of registration requests
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return func(r *httpRegistry) {
+		r.client = client
+	}
+}
+
+// NewHTTPRegistry new registry registering instances to the http_sd registration endpoint at url,
+// see NewHTTPSDHandler for the server side.
+func NewHTTPRegistry(url string, opts ...HTTPOption) Registry {
+	r := &httpRegistry{
+		statusTracker: newStatusTracker("http_sd"),
+		url:           url,
+		client:        &http.Client{Timeout: DefaultDialTimeout},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Register registers the instance and sends heartbeats every ttl/3 until the returned context.CancelFunc
+// is called, which unregisters the instance.
+func (h *httpRegistry) Register(ctx context.Context, ins Instance, ttl time.Duration) (context.CancelFunc, error) {
+	group, err := newTargetGroup(ins)
+	if err != nil {
+		return nil, err
+	}
+	if ttl <= 0 {
+		ttl = DefaultRegisterTTL
+	}
+	reg := &HTTPRegistration{
+		TargetGroup: group,
+		Key:         ins.GetKey(),
+		Value:       ins.GetValue(),
+		TTLSeconds:  int64(ttl.Seconds()),
+	}
+	if err := h.put(ctx, reg); err != nil {
+		h.failure(ins, err, false)
+		return nil, err
+	}
+	h.success(ins)
+	cctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				err := h.put(cctx, reg)
+				if cctx.Err() != nil {
+					continue
+				}
+				if err != nil {
+					log.Printf("[E]opentelemetry: http registry heartbeat error:%v, will retry after %v", err, ttl/3)
+					// the registration expires on the server if no heartbeat succeeds within the ttl
+					h.update(ins, func(s *Status) {
+						s.Registered = h.now().Sub(s.LastSuccess) < ttl
+						s.LastError = err.Error()
+						s.LastErrorTime = h.now()
+					})
+					continue
+				}
+				h.success(ins)
+			case <-cctx.Done():
+				uctx, ucancel := context.WithTimeout(context.Background(), DefaultDialTimeout)
+				defer ucancel()
+				if err := h.delete(uctx, reg.Key); err != nil {
+					log.Printf("[E]opentelemetry: http registry unregister error:%v", err)
+				}
+				h.remove(ins)
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+		})
+	}, nil
+}
+
+func (h *httpRegistry) put(ctx context.Context, reg *HTTPRegistration) error {
+	body, err := json.Marshal(reg)
+	if err != nil {
+		return err
+	}
+	return h.do(ctx, http.MethodPut, h.url, bytes.NewReader(body))
+}
+
+func (h *httpRegistry) delete(ctx context.Context, key string) error {
+	u, err := url.Parse(h.url)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	q.Set("key", key)
+	u.RawQuery = q.Encode()
+	return h.do(ctx, http.MethodDelete, u.String(), nil)
+}
+
+func (h *httpRegistry) do(ctx context.Context, method, url string, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for k, v := range h.headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("http registry %s %s: %s: %s", method, url, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// httpSDHandler server side of the http registry, it keeps the registrations in memory.
+type httpSDHandler struct {
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]httpSDEntry
+}
+
+type httpSDEntry struct {
+	tenantID string
+	group    TargetGroup
+	expireAt time.Time
+}
+
+// NewHTTPSDHandler returns the server side of the http registry, which accepts registrations by PUT,
+// unregistrations by DELETE ?key=$KEY, and serves the unexpired target groups by GET in the Prometheus
+// http_sd format. Use GET ?tenant_id=$TENANT to discover the instances of a tenant only.
+func NewHTTPSDHandler() http.Handler {
+	return &httpSDHandler{
+		now:     time.Now,
+		entries: make(map[string]httpSDEntry),
+	}
+}
+
+// ServeHTTP implements http.Handler
+func (s *httpSDHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.targetGroups(r.URL.Query().Get("tenant_id")))
+	case http.MethodPut:
+		var reg HTTPRegistration
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1<<20)).Decode(&reg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if reg.Key == "" || len(reg.Targets) == 0 {
+			http.Error(w, "key and targets are required", http.StatusBadRequest)
+			return
+		}
+		ttl := time.Duration(reg.TTLSeconds) * time.Second
+		if ttl <= 0 {
+			ttl = DefaultRegisterTTL
+		}
+		s.mu.Lock()
+		s.entries[reg.Key] = httpSDEntry{
+			tenantID: reg.Labels["tenant_id"],
+			group:    reg.TargetGroup,
+			expireAt: s.now().Add(ttl),
+		}
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.entries, r.URL.Query().Get("key"))
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// targetGroups removes the expired entries and returns the target groups of tenantID, or all if it is empty.
+func (s *httpSDHandler) targetGroups(tenantID string) []TargetGroup {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	groups := make(map[string]TargetGroup, len(s.entries))
+	for key, e := range s.entries {
+		if now.After(e.expireAt) {
+			delete(s.entries, key)
+			continue
+		}
+		if tenantID == "" || e.tenantID == tenantID {
+			groups[key] = e.group
+		}
+	}
+	return sortedTargetGroups(groups)
+}
@@ -0,0 +1,101 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getTargetGroups(t *testing.T, url string) []TargetGroup {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var groups []TargetGroup
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&groups))
+	return groups
+}
+
+func TestHTTPRegistry(t *testing.T) {
+	handler := NewHTTPSDHandler()
+	var puts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			atomic.AddInt32(&puts, 1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	r := NewHTTPRegistry(srv.URL, WithHTTPHeaders(map[string]string{"Authorization": "Bearer token"}))
+	cancel, err := r.Register(context.Background(), &testInstance{
+		key:   "/opentelemetry/metrics/services/t1/127.0.0.1:1",
+		value: `{"addr":"127.0.0.1:1","tenant_id":"t1","metadata":{"app":"app1"}}`,
+	}, 30*time.Millisecond)
+	require.NoError(t, err)
+
+	want := []TargetGroup{{Targets: []string{"127.0.0.1:1"}, Labels: map[string]string{"tenant_id": "t1",
+		"app": "app1"}}}
+	assert.Equal(t, want, getTargetGroups(t, srv.URL))
+	assert.Equal(t, want, getTargetGroups(t, srv.URL+"?tenant_id=t1"))
+	assert.Empty(t, getTargetGroups(t, srv.URL+"?tenant_id=t2"))
+
+	// heartbeats keep the registration beyond the ttl
+	time.Sleep(60 * time.Millisecond)
+	assert.GreaterOrEqual(t, atomic.LoadInt32(&puts), int32(3))
+	assert.Equal(t, want, getTargetGroups(t, srv.URL))
+
+	cancel()
+	cancel()
+	assert.Empty(t, getTargetGroups(t, srv.URL))
+
+	_, err = NewHTTPRegistry(srv.URL).Register(context.Background(), &testInstance{
+		key:   "/a",
+		value: `{"addr":"127.0.0.1:2"}`,
+	}, time.Second)
+	assert.Error(t, err)
+}
+
+func TestHTTPSDHandler_Expire(t *testing.T) {
+	h := NewHTTPSDHandler().(*httpSDHandler)
+	now := time.Unix(100, 0)
+	h.now = func() time.Time { return now }
+	h.entries["/a"] = httpSDEntry{group: TargetGroup{Targets: []string{"a"}}, expireAt: now.Add(time.Second)}
+	assert.Len(t, h.targetGroups(""), 1)
+	now = now.Add(2 * time.Second)
+	assert.Empty(t, h.targetGroups(""))
+	assert.Empty(t, h.entries)
+
+	for method, status := range map[string]int{
+		http.MethodPut:  http.StatusBadRequest,
+		http.MethodPost: http.StatusMethodNotAllowed,
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+		assert.Equal(t, status, rec.Code, method)
+	}
+}
@@ -16,11 +16,13 @@ package registry
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
-	"math/rand"
+	"sync"
 	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -35,12 +37,21 @@ var (
 
 // etcdRegistry register or unregister instance to etcd
 type etcdRegistry struct {
+	statusTracker
 	cli         *clientv3.Client
 	tenantID    string
 	registerTTL time.Duration
 	err         error
+	// minRetryInterval and maxRetryInterval bound the exponential backoff of re-registration
+	minRetryInterval time.Duration
+	maxRetryInterval time.Duration
 }
 
+var (
+	errKeepAliveClosed = errors.New("keepAlive ch closed")
+	errKeyDeleted      = errors.New("key deleted")
+)
+
 // EtcdOption etcd config option
 type EtcdOption func(*clientv3.Config)
 
@@ -54,8 +65,11 @@ func WithTLS(tlsConfig *tls.Config) EtcdOption {
 // NewEtcdRegistry new etcd registry
 func NewEtcdRegistry(etcdEndpoints []string, tenantID string, opts ...EtcdOption) Registry {
 	r := &etcdRegistry{
-		registerTTL: DefaultRegisterTTL,
-		tenantID:    tenantID,
+		statusTracker:    newStatusTracker("etcd"),
+		registerTTL:      DefaultRegisterTTL,
+		tenantID:         tenantID,
+		minRetryInterval: time.Second,
+		maxRetryInterval: DefaultRegisterTTL / 3,
 	}
 	cfg := clientv3.Config{
 		Endpoints: etcdEndpoints,
@@ -72,7 +86,9 @@ func NewEtcdRegistry(etcdEndpoints []string, tenantID string, opts ...EtcdOption
 	return r
 }
 
-// Register register instance to etcd
+// Register register instance to etcd, and keeps the lease alive until the returned context.CancelFunc
+// is called, which deletes the key. The instance is re-registered with exponential backoff when
+// the lease is lost or the key is deleted by others.
 func (e *etcdRegistry) Register(ctx context.Context, ins Instance, ttl time.Duration) (context.CancelFunc, error) {
 	if err := e.err; err != nil {
 		return nil, err
@@ -81,35 +97,79 @@ func (e *etcdRegistry) Register(ctx context.Context, ins Instance, ttl time.Dura
 	leaseID, err := e.register(cctx, ins, ttl)
 	if err != nil {
 		cancel()
+		e.failure(ins, err, false)
 		return nil, err
 	}
-	ch := make(chan struct{}, 1)
+	e.registered(ins, leaseID, false)
+	ch := make(chan struct{})
+	var once sync.Once
 	cancelFunc := func() {
-		cancel()
-		<-ch
+		once.Do(func() {
+			cancel()
+			<-ch
+		})
 	}
 	go func() {
+		defer close(ch)
 		leaseID := leaseID
 		for {
-			err := e.keepAlive(cctx, leaseID)
-			select {
-			case <-cctx.Done():
+			err := e.keepAlive(cctx, ins, leaseID)
+			if cctx.Err() != nil {
 				_ = e.unregister(context.Background(), ins)
-				ch <- struct{}{}
+				e.remove(ins)
 				return
-			default:
 			}
-			if err != nil {
-				retryWait := e.registerTTL/3 + time.Duration(rand.Int63n(int64(e.registerTTL/3)))
-				log.Printf("[E]opetelemetry: keepAlive error:%v, will retry after %v", err, retryWait)
-				time.Sleep(retryWait)
-				leaseID, _ = e.register(cctx, ins, ttl)
+			e.failure(ins, err, false)
+			log.Printf("[E]opetelemetry: keepAlive error:%v, will re-register", err)
+			go e.revoke(leaseID)
+			if leaseID, err = e.reregister(cctx, ins, ttl); err != nil {
+				_ = e.unregister(context.Background(), ins)
+				e.remove(ins)
+				return
 			}
+			e.registered(ins, leaseID, true)
 		}
 	}()
 	return cancelFunc, nil
 }
 
+// registered records a successful registration with the lease.
+func (e *etcdRegistry) registered(ins Instance, leaseID clientv3.LeaseID, reregistration bool) {
+	e.update(ins, func(s *Status) {
+		s.Registered = true
+		s.LeaseID = int64(leaseID)
+		s.LastSuccess = e.now()
+		if reregistration {
+			s.Reregistrations++
+		}
+	})
+}
+
+// reregister registers the instance with exponential backoff until it succeeds or ctx is done.
+func (e *etcdRegistry) reregister(ctx context.Context, ins Instance, ttl time.Duration) (clientv3.LeaseID, error) {
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = e.minRetryInterval
+	b.MaxInterval = e.maxRetryInterval
+	b.MaxElapsedTime = 0 // retry until ctx is done
+	b.Reset()
+	for {
+		leaseID, err := e.register(ctx, ins, ttl)
+		if err == nil {
+			return leaseID, nil
+		}
+		e.failure(ins, err, false)
+		retryWait := b.NextBackOff()
+		log.Printf("[E]opetelemetry: register error:%v, will retry after %v", err, retryWait)
+		timer := time.NewTimer(retryWait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return 0, ctx.Err()
+		}
+	}
+}
+
 func (e *etcdRegistry) register(ctx context.Context, ins Instance, ttl time.Duration) (clientv3.LeaseID, error) {
 	if err := e.err; err != nil {
 		return 0, err
@@ -125,17 +185,50 @@ func (e *etcdRegistry) register(ctx context.Context, ins Instance, ttl time.Dura
 	return ttlResp.ID, nil
 }
 
-// keepAlive Blocking until the context is canceled or the underlying connection is disconnected for a long time
-// exceeding TTL, ch will be closed, and the caller needs to re-initiate the lease.
-func (e *etcdRegistry) keepAlive(
-	ctx context.Context, id clientv3.LeaseID) error {
-	ch, err := e.cli.KeepAlive(ctx, id)
+// keepAlive Blocking until the context is canceled, the key is deleted by others, or the underlying connection
+// is disconnected for a long time exceeding TTL, ch will be closed, and the caller needs to re-initiate the lease.
+func (e *etcdRegistry) keepAlive(ctx context.Context, ins Instance, id clientv3.LeaseID) error {
+	kctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch, err := e.cli.KeepAlive(kctx, id)
 	if err != nil {
 		return fmt.Errorf("keepAlive err:%w", err)
 	}
-	for range ch {
-	} // noop
-	return fmt.Errorf("keepAlive ch closed")
+	watch := e.cli.Watch(kctx, ins.GetKey())
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return errKeepAliveClosed
+			}
+			e.success(ins)
+		case resp, ok := <-watch:
+			if !ok {
+				// the watch is closed when ctx is done, or canceled by the server, watch again
+				if kctx.Err() != nil {
+					return kctx.Err()
+				}
+				watch = e.cli.Watch(kctx, ins.GetKey())
+				continue
+			}
+			for _, ev := range resp.Events {
+				if ev.Type == clientv3.EventTypeDelete {
+					return errKeyDeleted
+				}
+			}
+		}
+	}
+}
+
+// revoke revokes the old lease after re-registration is started, so that it does not keep alive
+// on the server until the TTL when only the key is deleted.
+func (e *etcdRegistry) revoke(id clientv3.LeaseID) {
+	if id == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultDialTimeout)
+	defer cancel()
+	_, _ = e.cli.Revoke(ctx, id)
 }
 
 func (e *etcdRegistry) unregister(ctx context.Context, ins Instance) error {
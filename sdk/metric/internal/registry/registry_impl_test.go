@@ -208,3 +208,45 @@ func simpleTCPProxy(listenAddr string, proxyAddr string) (net.Listener, func(),
 	}
 	return ln, forceCloseAllFunc, nil
 }
+
+// Test_etcdRegistry_keyDeleted test the key deleted by others is re-registered, and the status is tracked.
+func Test_etcdRegistry_keyDeleted(t *testing.T) {
+	s, etcdAddrs, stop := startSingleNodeETCD(t, "etcd2", 12480, 12490)
+	defer stop()
+	_ = s
+	ctx := context.Background()
+	client, err := clientv3.New(clientv3.Config{Endpoints: etcdAddrs})
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ins := &testInstance{key: "/deleted", value: "{}"}
+	r := NewEtcdRegistry(etcdAddrs, "default").(*etcdRegistry)
+	r.minRetryInterval, r.maxRetryInterval = 10*time.Millisecond, 10*time.Millisecond
+	cancel, err := r.Register(ctx, ins, 5*time.Second)
+	assert.NoError(t, err)
+	statuses := r.Status()
+	assert.Len(t, statuses, 1)
+	assert.True(t, statuses[0].Registered)
+	assert.Equal(t, "etcd", statuses[0].Registry)
+	firstLease := statuses[0].LeaseID
+	assert.NotZero(t, firstLease)
+
+	_, err = client.Delete(ctx, ins.key)
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		rsp, err := client.Get(ctx, ins.key)
+		return err == nil && len(rsp.Kvs) == 1 && rsp.Kvs[0].Lease != firstLease
+	}, 5*time.Second, 10*time.Millisecond, "assert re-register after key deleted")
+	assert.Eventually(t, func() bool {
+		statuses := r.Status()
+		return len(statuses) == 1 && statuses[0].Reregistrations == 1 && statuses[0].Registered &&
+			statuses[0].LastError == errKeyDeleted.Error()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	cancel()
+	rsp, err := client.Get(ctx, ins.key)
+	assert.NoError(t, err)
+	assert.Empty(t, rsp.Kvs)
+	assert.Empty(t, r.Status())
+}
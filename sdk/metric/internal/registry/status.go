@@ -0,0 +1,111 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status registration status of an instance
+type Status struct {
+	// Registry etcd, file_sd or http_sd
+	Registry string `json:"registry"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	// Registered whether the instance is registered currently
+	Registered bool `json:"registered"`
+	// LeaseID etcd lease id, 0 for other registries
+	LeaseID int64 `json:"lease_id,omitempty"`
+	// LastSuccess last time of successful registration, keepalive or heartbeat
+	LastSuccess time.Time `json:"last_success"`
+	// LastError last error of registration, keepalive or heartbeat
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorTime time of LastError
+	LastErrorTime time.Time `json:"last_error_time,omitempty"`
+	// Reregistrations number of re-registrations after lease loss or key deletion
+	Reregistrations uint64 `json:"reregistrations"`
+}
+
+// StatusReporter is implemented by registries reporting the status of their registrations.
+type StatusReporter interface {
+	// Status returns the status of registered instances, sorted by key.
+	Status() []Status
+}
+
+// statusTracker tracks the status of registrations, it is embedded in registries.
+type statusTracker struct {
+	registry string
+	now      func() time.Time
+
+	mu       sync.Mutex
+	statuses map[string]*Status
+}
+
+func newStatusTracker(registry string) statusTracker {
+	return statusTracker{
+		registry: registry,
+		now:      time.Now,
+		statuses: make(map[string]*Status),
+	}
+}
+
+// update calls f with the status of ins, which is created if not exists.
+func (t *statusTracker) update(ins Instance, f func(s *Status)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.statuses[ins.GetKey()]
+	if !ok {
+		s = &Status{Registry: t.registry, Key: ins.GetKey(), Value: ins.GetValue()}
+		t.statuses[ins.GetKey()] = s
+	}
+	f(s)
+}
+
+// success records a successful registration, keepalive or heartbeat.
+func (t *statusTracker) success(ins Instance) {
+	t.update(ins, func(s *Status) {
+		s.Registered = true
+		s.LastSuccess = t.now()
+	})
+}
+
+// failure records an error, registered is false if the registration is lost.
+func (t *statusTracker) failure(ins Instance, err error, registered bool) {
+	t.update(ins, func(s *Status) {
+		s.Registered = registered
+		s.LastError = err.Error()
+		s.LastErrorTime = t.now()
+	})
+}
+
+// remove removes the status of an unregistered instance.
+func (t *statusTracker) remove(ins Instance) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.statuses, ins.GetKey())
+}
+
+// Status implements StatusReporter
+func (t *statusTracker) Status() []Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	result := make([]Status, 0, len(t.statuses))
+	for _, s := range t.statuses {
+		result = append(result, *s)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
@@ -0,0 +1,84 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// TargetGroup is a target group of Prometheus file_sd and http_sd,
+// see https://prometheus.io/docs/prometheus/latest/http_sd/
+type TargetGroup struct {
+	Targets []string          `json:"targets"`
+	Labels  map[string]string `json:"labels,omitempty"`
+}
+
+// instanceValue fields of Instance.GetValue used by the target group
+type instanceValue struct {
+	Addr     string            `json:"addr"`
+	TenantID string            `json:"tenant_id"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+// newTargetGroup returns the target group of the instance from its value,
+// the target is the addr and the labels are the metadata and tenant_id.
+func newTargetGroup(ins Instance) (TargetGroup, error) {
+	var v instanceValue
+	if err := json.Unmarshal([]byte(ins.GetValue()), &v); err != nil {
+		return TargetGroup{}, fmt.Errorf("invalid instance value of %s: %w", ins.GetKey(), err)
+	}
+	if v.Addr == "" {
+		return TargetGroup{}, fmt.Errorf("instance addr of %s is empty", ins.GetKey())
+	}
+	labels := make(map[string]string, len(v.Metadata)+1)
+	for name, value := range v.Metadata {
+		if name = SanitizeLabelName(name); name != "" && value != "" {
+			labels[name] = value
+		}
+	}
+	if v.TenantID != "" {
+		labels["tenant_id"] = v.TenantID
+	}
+	return TargetGroup{Targets: []string{v.Addr}, Labels: labels}, nil
+}
+
+// SanitizeLabelName replaces characters not matching [a-zA-Z0-9_] by _, and prefixes _ to a leading digit.
+func SanitizeLabelName(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= '0' && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 0 && b[0] >= '0' && b[0] <= '9' {
+		return "_" + string(b)
+	}
+	return string(b)
+}
+
+// sortedTargetGroups returns the target groups sorted by key.
+func sortedTargetGroups(groups map[string]TargetGroup) []TargetGroup {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]TargetGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, groups[key])
+	}
+	return result
+}
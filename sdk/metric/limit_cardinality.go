@@ -71,12 +71,8 @@ func SetProcessor(p Processor) {
 
 // LimitMetricsHandler handler for limited metrics avoiding Prometheus server OOM
 func LimitMetricsHandler() http.Handler {
-	gather := &LimitCardinalityGatherer{
-		prometheus.DefaultGatherer,
-		PerMetricCardinalityLimit,
-		TotalMetricCardinalityLimit}
 	return promhttp.InstrumentMetricHandler(
-		prometheus.DefaultRegisterer, promhttp.HandlerFor(gather, promhttp.HandlerOpts{
+		prometheus.DefaultRegisterer, promhttp.HandlerFor(limitGatherer(), promhttp.HandlerOpts{
 			// OpenMetrics is the only way to transmit exemplars. However, the move to OpenMetrics
 			// is not completely transparent. Most notably, the values of "quantile"
 			// labels of Summaries and "le" labels of Histograms are formatted with
@@ -88,6 +84,15 @@ func LimitMetricsHandler() http.Handler {
 	)
 }
 
+// limitGatherer gatherer of the default registry with the cardinality limits,
+// it is shared by the scrape handler and the remote-write sender.
+func limitGatherer() prometheus.Gatherer {
+	return &LimitCardinalityGatherer{
+		prometheus.DefaultGatherer,
+		PerMetricCardinalityLimit,
+		TotalMetricCardinalityLimit}
+}
+
 // LimitCardinalityGatherer struct for limit cardinality gatherer
 type LimitCardinalityGatherer struct {
 	prometheus.Gatherer
@@ -112,6 +117,7 @@ func (l *LimitCardinalityGatherer) Gather() ([]*dto.MetricFamily, error) {
 			highCardinalityMetrics.WithLabelValues(v.GetName()).Set(float64(len(v.GetMetric())))
 			// get topN
 			v.Metric = v.Metric[:l.PerMetirclimit]
+			// in-flight gauges are not reset, otherwise the running RPCs would make them negative.
 			if strings.HasPrefix(v.GetName(), "rpc_client") {
 				resetClientM.Do(func() {
 					log.Printf("opentelemetry: reset rpc_client metric when high cardinality(>%d)",
@@ -119,6 +125,9 @@ func (l *LimitCardinalityGatherer) Gather() ([]*dto.MetricFamily, error) {
 					clientStartedCounter.Reset()
 					clientHandledCounter.Reset()
 					clientHandledHistogram.Reset()
+					if p := loadPayloadSizeHistograms(&clientPayloadSize); p != nil {
+						p.Reset()
+					}
 				})
 			}
 			if strings.HasPrefix(v.GetName(), "rpc_server") {
@@ -128,6 +137,9 @@ func (l *LimitCardinalityGatherer) Gather() ([]*dto.MetricFamily, error) {
 					serverStartedCounter.Reset()
 					serverHandledCounter.Reset()
 					serverHandledHistogram.Reset()
+					if p := loadPayloadSizeHistograms(&serverPayloadSize); p != nil {
+						p.Reset()
+					}
 				})
 			}
 		} else if m, _ := highCardinalityMetrics.GetMetricWithLabelValues(v.GetName()); m != nil {
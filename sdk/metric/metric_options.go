@@ -49,3 +49,16 @@ func WithHistogramConstLabels(labels prom.Labels) HistogramOption {
 		o.ConstLabels = labels
 	}
 }
+
+// WithHistogramNative enables prometheus native histogram if cfg.Enabled.
+func WithHistogramNative(cfg NativeHistogramConfig) HistogramOption {
+	return func(o *prom.HistogramOpts) {
+		if !cfg.Enabled {
+			return
+		}
+		o.NativeHistogramBucketFactor = nativeHistogramBucketFactor(cfg.Schema)
+		o.NativeHistogramZeroThreshold = cfg.ZeroThreshold
+		o.NativeHistogramMaxBucketNumber = cfg.MaxBucketNumber
+		o.NativeHistogramMinResetDuration = cfg.MinResetDuration
+	}
+}
@@ -29,4 +29,10 @@ var (
 
 var (
 	NewEtcdRegistry = registry.NewEtcdRegistry
+	// NewFileRegistry registry writing Prometheus file_sd target groups to a file
+	NewFileRegistry = registry.NewFileRegistry
+	// NewHTTPRegistry registry registering to an http_sd registration endpoint with heartbeats
+	NewHTTPRegistry = registry.NewHTTPRegistry
+	// NewHTTPSDHandler server side of NewHTTPRegistry, serving the target groups in the Prometheus http_sd format
+	NewHTTPSDHandler = registry.NewHTTPSDHandler
 )
@@ -0,0 +1,401 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// peerOther is the callee_service and peer label value of peers beyond PeerMetricsConfig.MaxPeers
+	peerOther = "other"
+	// peerWindowSlots number of slots of the sliding window
+	peerWindowSlots = 6
+	// minPeerWindow min sliding window, each slot is at least 1ms
+	minPeerWindow = peerWindowSlots * time.Millisecond
+	// minFleetPeers min number of judged peers of a callee service to detect outliers
+	minFleetPeers = 3
+
+	// PeerOutlierErrorRate peer error rate is higher than the fleet
+	PeerOutlierErrorRate = "error_rate"
+	// PeerOutlierLatency peer p99 latency is higher than the fleet
+	PeerOutlierLatency = "latency"
+)
+
+// peerLatencyBounds upper bounds of latency buckets used to estimate percentiles, 1ms * 1.5^i, up to about 190s
+var peerLatencyBounds = func() []float64 {
+	bounds := make([]float64, 31)
+	for i := range bounds {
+		bounds[i] = 0.001 * math.Pow(1.5, float64(i))
+	}
+	return bounds
+}()
+
+var (
+	peerHandledDesc = prometheus.NewDesc("rpc_client_peer_handled_total",
+		"Total number of RPCs completed by the client, by callee service and peer address.",
+		[]string{"callee_service", "peer", "code_type"}, nil)
+	peerErrorRateDesc = prometheus.NewDesc("rpc_client_peer_error_rate",
+		"Error rate of RPCs by callee service and peer address over the sliding window.",
+		[]string{"callee_service", "peer"}, nil)
+	peerLatencyDesc = prometheus.NewDesc("rpc_client_peer_latency_seconds",
+		"Latency percentiles (seconds) of RPCs by callee service and peer address over the sliding window.",
+		[]string{"callee_service", "peer", "quantile"}, nil)
+	peerOutlierDesc = prometheus.NewDesc("rpc_client_peer_outlier",
+		"Whether the peer deviates from other peers of the same callee service, 1 means outlier.",
+		[]string{"callee_service", "peer", "reason"}, nil)
+
+	// peerOverflowKey aggregates the peers beyond PeerMetricsConfig.MaxPeers of all callee services
+	peerOverflowKey = peerKey{service: peerOther, peer: peerOther}
+
+	// defaultPeerTracker stores *peerTracker, nil means per-peer metrics are disabled.
+	defaultPeerTracker atomic.Value
+)
+
+// PeerStat statistic of a peer over the sliding window
+type PeerStat struct {
+	CalleeService string   `json:"callee_service"`
+	Peer          string   `json:"peer"`
+	Requests      uint64   `json:"requests"`
+	Errors        uint64   `json:"errors"`
+	ErrorRate     float64  `json:"error_rate"`
+	P50           float64  `json:"latency_p50_seconds"`
+	P90           float64  `json:"latency_p90_seconds"`
+	P99           float64  `json:"latency_p99_seconds"`
+	Outlier       bool     `json:"outlier"`
+	Reasons       []string `json:"reasons,omitempty"`
+}
+
+type peerKey struct {
+	service string
+	peer    string
+}
+
+type peerSlot struct {
+	epoch   int64
+	count   uint64
+	errors  uint64
+	latency []uint64
+}
+
+type peerStats struct {
+	mu       sync.Mutex
+	handled  map[string]uint64 // code_type => cumulative count
+	slots    [peerWindowSlots]peerSlot
+	lastSeen time.Time
+}
+
+// peerTracker tracks per-peer client metrics with a cardinality cap.
+type peerTracker struct {
+	cfg     PeerMetricsConfig
+	slotDur time.Duration
+	now     func() time.Time
+
+	mu    sync.RWMutex
+	peers map[peerKey]*peerStats
+}
+
+func newPeerTracker(cfg PeerMetricsConfig) *peerTracker {
+	cfg = cfg.withDefaults()
+	return &peerTracker{
+		cfg:     cfg,
+		slotDur: cfg.Window / peerWindowSlots,
+		now:     time.Now,
+		peers:   make(map[peerKey]*peerStats),
+	}
+}
+
+// observe records a finished rpc of the peer.
+func (t *peerTracker) observe(service, peer, codeType string, cost time.Duration) {
+	now := t.now()
+	s := t.stats(peerKey{service: service, peer: peer}, now)
+	epoch := now.UnixNano() / int64(t.slotDur)
+	latencyIdx := sort.SearchFloat64s(peerLatencyBounds, cost.Seconds())
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastSeen = now
+	s.handled[codeType]++
+	slot := &s.slots[epoch%peerWindowSlots]
+	if slot.epoch != epoch {
+		slot.epoch = epoch
+		slot.count, slot.errors = 0, 0
+		for i := range slot.latency {
+			slot.latency[i] = 0
+		}
+	}
+	slot.count++
+	if codeType != CodeTypeSuccess.String() {
+		slot.errors++
+	}
+	slot.latency[latencyIdx]++
+}
+
+// stats returns the stats of key, the key is replaced with peerOverflowKey when exceeding MaxPeers.
+func (t *peerTracker) stats(key peerKey, now time.Time) *peerStats {
+	t.mu.RLock()
+	s, ok := t.peers[key]
+	t.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if s, ok = t.peers[key]; ok {
+		return s
+	}
+	if !t.hasRoom() {
+		t.evictIdle(now)
+	}
+	if !t.hasRoom() {
+		key = peerOverflowKey
+		if s, ok = t.peers[key]; ok {
+			return s
+		}
+	}
+	s = &peerStats{handled: make(map[string]uint64)}
+	for i := range s.slots {
+		s.slots[i].latency = make([]uint64, len(peerLatencyBounds)+1)
+	}
+	t.peers[key] = s
+	return s
+}
+
+// hasRoom returns if a new peer can be tracked, one of MaxPeers is reserved for peerOverflowKey.
+// It must be called with t.mu locked.
+func (t *peerTracker) hasRoom() bool {
+	n := len(t.peers)
+	if _, ok := t.peers[peerOverflowKey]; ok {
+		n--
+	}
+	return n < t.cfg.MaxPeers-1
+}
+
+// evictIdle removes peers without requests in the last two windows, must be called with t.mu locked.
+func (t *peerTracker) evictIdle(now time.Time) {
+	for k, s := range t.peers {
+		s.mu.Lock()
+		idle := now.Sub(s.lastSeen) > 2*t.cfg.Window
+		s.mu.Unlock()
+		if idle && k != peerOverflowKey {
+			delete(t.peers, k)
+		}
+	}
+}
+
+// snapshot returns the stats of all peers over the sliding window, and marks outliers.
+func (t *peerTracker) snapshot() []PeerStat {
+	now := t.now()
+	minEpoch := now.UnixNano()/int64(t.slotDur) - peerWindowSlots + 1
+
+	t.mu.RLock()
+	result := make([]PeerStat, 0, len(t.peers))
+	for k, s := range t.peers {
+		stat := PeerStat{CalleeService: k.service, Peer: k.peer}
+		latency := make([]uint64, len(peerLatencyBounds)+1)
+		s.mu.Lock()
+		for _, slot := range s.slots {
+			if slot.epoch < minEpoch {
+				continue
+			}
+			stat.Requests += slot.count
+			stat.Errors += slot.errors
+			for i, c := range slot.latency {
+				latency[i] += c
+			}
+		}
+		s.mu.Unlock()
+		if stat.Requests > 0 {
+			stat.ErrorRate = float64(stat.Errors) / float64(stat.Requests)
+			stat.P50 = latencyQuantile(latency, stat.Requests, 0.5)
+			stat.P90 = latencyQuantile(latency, stat.Requests, 0.9)
+			stat.P99 = latencyQuantile(latency, stat.Requests, 0.99)
+		}
+		result = append(result, stat)
+	}
+	t.mu.RUnlock()
+
+	t.markOutliers(result)
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CalleeService != result[j].CalleeService {
+			return result[i].CalleeService < result[j].CalleeService
+		}
+		return result[i].Peer < result[j].Peer
+	})
+	return result
+}
+
+// markOutliers compares each peer with the median of peers of the same callee service.
+func (t *peerTracker) markOutliers(stats []PeerStat) {
+	fleets := make(map[string][]int)
+	for i, s := range stats {
+		if s.Peer != peerOther && s.Requests >= uint64(t.cfg.MinRequests) {
+			fleets[s.CalleeService] = append(fleets[s.CalleeService], i)
+		}
+	}
+	for _, fleet := range fleets {
+		if len(fleet) < minFleetPeers {
+			continue
+		}
+		errorRates := make([]float64, 0, len(fleet))
+		p99s := make([]float64, 0, len(fleet))
+		for _, i := range fleet {
+			errorRates = append(errorRates, stats[i].ErrorRate)
+			p99s = append(p99s, stats[i].P99)
+		}
+		medianErrorRate, medianP99 := median(errorRates), median(p99s)
+		for _, i := range fleet {
+			s := &stats[i]
+			if s.ErrorRate-medianErrorRate >= t.cfg.ErrorRateThreshold {
+				s.Reasons = append(s.Reasons, PeerOutlierErrorRate)
+			}
+			if medianP99 > 0 && s.P99 >= medianP99*t.cfg.LatencyRatio {
+				s.Reasons = append(s.Reasons, PeerOutlierLatency)
+			}
+			s.Outlier = len(s.Reasons) > 0
+		}
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (t *peerTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- peerHandledDesc
+	ch <- peerErrorRateDesc
+	ch <- peerLatencyDesc
+	ch <- peerOutlierDesc
+}
+
+// Collect implements prometheus.Collector.
+func (t *peerTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.RLock()
+	for k, s := range t.peers {
+		s.mu.Lock()
+		for codeType, v := range s.handled {
+			ch <- prometheus.MustNewConstMetric(peerHandledDesc, prometheus.CounterValue, float64(v),
+				k.service, k.peer, codeType)
+		}
+		s.mu.Unlock()
+	}
+	t.mu.RUnlock()
+
+	for _, s := range t.snapshot() {
+		ch <- prometheus.MustNewConstMetric(peerErrorRateDesc, prometheus.GaugeValue, s.ErrorRate,
+			s.CalleeService, s.Peer)
+		for q, v := range map[float64]float64{0.5: s.P50, 0.9: s.P90, 0.99: s.P99} {
+			ch <- prometheus.MustNewConstMetric(peerLatencyDesc, prometheus.GaugeValue, v,
+				s.CalleeService, s.Peer, strconv.FormatFloat(q, 'f', -1, 64))
+		}
+		for _, reason := range []string{PeerOutlierErrorRate, PeerOutlierLatency} {
+			var v float64
+			for _, r := range s.Reasons {
+				if r == reason {
+					v = 1
+				}
+			}
+			ch <- prometheus.MustNewConstMetric(peerOutlierDesc, prometheus.GaugeValue, v,
+				s.CalleeService, s.Peer, reason)
+		}
+	}
+}
+
+// latencyQuantile estimates the quantile by linear interpolation in the bucket.
+func latencyQuantile(buckets []uint64, total uint64, q float64) float64 {
+	rank := q * float64(total)
+	var cumulative uint64
+	for i, c := range buckets {
+		if c == 0 || float64(cumulative+c) < rank {
+			cumulative += c
+			continue
+		}
+		lower := 0.0
+		if i > 0 {
+			lower = peerLatencyBounds[i-1]
+		}
+		if i == len(peerLatencyBounds) {
+			// +Inf bucket, use the largest bound
+			return lower
+		}
+		upper := peerLatencyBounds[i]
+		return lower + (upper-lower)*(rank-float64(cumulative))/float64(c)
+	}
+	return 0
+}
+
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func loadPeerTracker() *peerTracker {
+	t, _ := defaultPeerTracker.Load().(*peerTracker)
+	return t
+}
+
+func registerPeerMetrics(cfg PeerMetricsConfig) {
+	t := newPeerTracker(cfg)
+	prometheus.MustRegister(t)
+	defaultPeerTracker.Store(t)
+}
+
+// PeerStats returns statistics of peers over the sliding window,
+// it returns nil if per-peer metrics are not enabled.
+func PeerStats() []PeerStat {
+	if t := loadPeerTracker(); t != nil {
+		return t.snapshot()
+	}
+	return nil
+}
+
+// PeerStatsHandler http handler of peer statistics in JSON, use ?outlier=true to list outliers only
+func PeerStatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := PeerStats()
+		if outlierOnly, _ := strconv.ParseBool(r.URL.Query().Get("outlier")); outlierOnly {
+			outliers := make([]PeerStat, 0, len(stats))
+			for _, s := range stats {
+				if s.Outlier {
+					outliers = append(outliers, s)
+				}
+			}
+			stats = outliers
+		}
+		if stats == nil {
+			stats = []PeerStat{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"enabled": loadPeerTracker() != nil,
+			"peers":   stats,
+		})
+	})
+}
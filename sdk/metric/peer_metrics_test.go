@@ -0,0 +1,159 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPeerTracker(cfg PeerMetricsConfig, now *time.Time) *peerTracker {
+	t := newPeerTracker(cfg)
+	t.now = func() time.Time { return *now }
+	return t
+}
+
+func findPeerStat(stats []PeerStat, service, peer string) PeerStat {
+	for _, s := range stats {
+		if s.CalleeService == service && s.Peer == peer {
+			return s
+		}
+	}
+	return PeerStat{}
+}
+
+func TestPeerTracker_Outliers(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := newTestPeerTracker(PeerMetricsConfig{MinRequests: 10}, &now)
+	for i := 0; i < 100; i++ {
+		tracker.observe("svc", "10.0.0.1:80", CodeTypeSuccess.String(), 10*time.Millisecond)
+		tracker.observe("svc", "10.0.0.2:80", CodeTypeSuccess.String(), 12*time.Millisecond)
+		tracker.observe("svc", "10.0.0.3:80", CodeTypeSuccess.String(), 100*time.Millisecond)
+		code := CodeTypeSuccess.String()
+		if i%2 == 0 {
+			code = CodeTypeException.String()
+		}
+		tracker.observe("svc", "10.0.0.4:80", code, 11*time.Millisecond)
+		tracker.observe("svc", "10.0.0.5:80", CodeTypeSuccess.String(), 11*time.Millisecond)
+	}
+	// too few requests, not judged
+	tracker.observe("svc", "10.0.0.6:80", CodeTypeException.String(), time.Second)
+
+	stats := tracker.snapshot()
+	require.Len(t, stats, 6)
+	normal := findPeerStat(stats, "svc", "10.0.0.1:80")
+	assert.False(t, normal.Outlier)
+	assert.EqualValues(t, 100, normal.Requests)
+	assert.InDelta(t, 0.01, normal.P50, 0.003)
+
+	slow := findPeerStat(stats, "svc", "10.0.0.3:80")
+	assert.True(t, slow.Outlier)
+	assert.Equal(t, []string{PeerOutlierLatency}, slow.Reasons)
+
+	failing := findPeerStat(stats, "svc", "10.0.0.4:80")
+	assert.True(t, failing.Outlier)
+	assert.Equal(t, []string{PeerOutlierErrorRate}, failing.Reasons)
+	assert.Equal(t, 0.5, failing.ErrorRate)
+
+	assert.False(t, findPeerStat(stats, "svc", "10.0.0.6:80").Outlier)
+
+	// requests slide out of the window
+	now = now.Add(2 * time.Minute)
+	stats = tracker.snapshot()
+	assert.EqualValues(t, 0, findPeerStat(stats, "svc", "10.0.0.1:80").Requests)
+	assert.False(t, findPeerStat(stats, "svc", "10.0.0.3:80").Outlier)
+}
+
+func TestPeerTracker_MaxPeers(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := newTestPeerTracker(PeerMetricsConfig{MaxPeers: 3}, &now)
+	for i := 0; i < 10; i++ {
+		for _, service := range []string{"svc", fmt.Sprintf("svc%d", i)} {
+			tracker.observe(service, fmt.Sprintf("10.0.0.%d:80", i), CodeTypeSuccess.String(), time.Millisecond)
+			assert.LessOrEqual(t, len(tracker.peers), 3)
+		}
+	}
+	stats := tracker.snapshot()
+	assert.Len(t, stats, 3)
+	assert.EqualValues(t, 1, findPeerStat(stats, "svc", "10.0.0.0:80").Requests)
+	assert.EqualValues(t, 1, findPeerStat(stats, "svc0", "10.0.0.0:80").Requests)
+	// peers beyond MaxPeers of all callee services share one series
+	assert.EqualValues(t, 18, findPeerStat(stats, peerOther, peerOther).Requests)
+
+	// idle peers are evicted for new peers
+	now = now.Add(3 * time.Minute)
+	tracker.observe("svc", "10.0.1.1:80", CodeTypeSuccess.String(), time.Millisecond)
+	stats = tracker.snapshot()
+	assert.EqualValues(t, 1, findPeerStat(stats, "svc", "10.0.1.1:80").Requests)
+	assert.LessOrEqual(t, len(tracker.peers), 3)
+}
+
+func TestPeerTracker_MinWindow(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tracker := newTestPeerTracker(PeerMetricsConfig{Window: time.Nanosecond}, &now)
+	assert.Equal(t, minPeerWindow, tracker.cfg.Window)
+	assert.Equal(t, time.Millisecond, tracker.slotDur)
+	tracker.observe("svc", "10.0.0.1:80", CodeTypeSuccess.String(), time.Millisecond)
+	assert.EqualValues(t, 1, findPeerStat(tracker.snapshot(), "svc", "10.0.0.1:80").Requests)
+}
+
+func TestPeerMetrics_ReporterAndHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	prometheus.DefaultGatherer = reg
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+		defaultPeerTracker.Store((*peerTracker)(nil))
+	}()
+	registerPeerMetrics(PeerMetricsConfig{Enabled: true})
+
+	r := NewClientReporter("trpc", "caller", "/caller/M", "callee", "/callee/M")
+	r.SetPeer("127.0.0.1:8000")
+	r.Handled(context.Background(), "0")
+
+	mfs := gatherFamilies(t, reg)
+	require.Contains(t, mfs, "rpc_client_peer_handled_total")
+	m := mfs["rpc_client_peer_handled_total"].GetMetric()[0]
+	assert.Equal(t, "127.0.0.1:8000", labelValue(m, "peer"))
+	assert.Equal(t, 1.0, m.GetCounter().GetValue())
+	assert.Contains(t, mfs, "rpc_client_peer_error_rate")
+	assert.Len(t, mfs["rpc_client_peer_latency_seconds"].GetMetric(), 3)
+	assert.Len(t, mfs["rpc_client_peer_outlier"].GetMetric(), 2)
+
+	rec := httptest.NewRecorder()
+	PeerStatsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/peers", nil))
+	var rsp struct {
+		Enabled bool       `json:"enabled"`
+		Peers   []PeerStat `json:"peers"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.True(t, rsp.Enabled)
+	require.Len(t, rsp.Peers, 1)
+	assert.Equal(t, "callee", rsp.Peers[0].CalleeService)
+
+	rec = httptest.NewRecorder()
+	PeerStatsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/peers?outlier=true", nil))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+	assert.Len(t, rsp.Peers, 0)
+}
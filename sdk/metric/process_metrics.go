@@ -14,6 +14,8 @@
 package metric
 
 import (
+	"runtime"
+
 	"github.com/prometheus/client_golang/prometheus"
 
 	pkgruntime "trpc.group/trpc-go/trpc-opentelemetry/pkg/runtime"
@@ -43,6 +45,22 @@ var (
 			usageMemory, _ := pkgruntime.MemoryUsage()
 			return float64(usageMemory)
 		})
+	gomaxprocs = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Subsystem: "process",
+			Name:      "gomaxprocs",
+			Help:      "GOMAXPROCS of the process, which may be set from the cpu quota by auto tune",
+		}, func() float64 {
+			return float64(runtime.GOMAXPROCS(0))
+		})
+	memoryLimit = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Subsystem: "process",
+			Name:      "memory_limit",
+			Help:      "Go soft memory limit of the process, which may be set from the memory quota by auto tune",
+		}, func() float64 {
+			return float64(pkgruntime.MemoryLimit())
+		})
 )
 
 func init() {
@@ -55,4 +73,6 @@ func init() {
 
 	prometheus.MustRegister(memoryUsage)
 	memoryQuota.Set(float64(totalMemory))
+
+	prometheus.MustRegister(gomaxprocs, memoryLimit)
 }
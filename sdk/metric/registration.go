@@ -0,0 +1,152 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/internal/registry"
+)
+
+// RegistrationStatus registration status of the metrics endpoint
+type RegistrationStatus = registry.Status
+
+var (
+	registrationStatusDesc = prometheus.NewDesc("opentelemetry_registration_status",
+		"Whether the metrics endpoint is registered to the registry, 1 means registered.",
+		[]string{"registry"}, nil)
+	registrationLastSuccessDesc = prometheus.NewDesc("opentelemetry_registration_last_success_timestamp_seconds",
+		"Unix time of the last successful registration, keepalive or heartbeat of the metrics endpoint.",
+		[]string{"registry"}, nil)
+	registrationReregistrationsDesc = prometheus.NewDesc("opentelemetry_registration_reregistrations_total",
+		"Total number of re-registrations of the metrics endpoint after lease loss or key deletion.",
+		[]string{"registry"}, nil)
+)
+
+var (
+	registrationMu sync.Mutex
+	// registryCancelFunc deregisters the instance registered by Setup
+	registryCancelFunc context.CancelFunc
+	// activeRegistry registry of the instance registered by Setup
+	activeRegistry Registry
+	registerOnce   sync.Once
+)
+
+// setRegistration records the registration of Setup, exports its status and deregisters it on
+// opentelemetry.Shutdown. The previous registration, if any, is canceled.
+func setRegistration(reg Registry, cancel context.CancelFunc) {
+	registrationMu.Lock()
+	if registryCancelFunc != nil {
+		registryCancelFunc()
+	}
+	activeRegistry, registryCancelFunc = reg, cancel
+	registrationMu.Unlock()
+	registerOnce.Do(func() {
+		prometheus.MustRegister(registrationCollector{})
+		telemetrystatus.Register(telemetrystatus.SectionRegistration, func() interface{} { return Registrations() })
+	})
+	// the hooks are removed by Shutdown, so it is registered by each registration, Deregister is idempotent
+	opentelemetry.RegisterShutdownHook(func(ctx context.Context) error {
+		return callWithContext(ctx, Deregister)
+	})
+}
+
+// callWithContext calls f and waits for it until ctx is done
+func callWithContext(ctx context.Context, f func()) error {
+	done := make(chan struct{})
+	go func() {
+		f()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Deregister removes the instance registered by Setup from the registry and stops the keepalive,
+// it is a noop if the instance is not registered. It is called by opentelemetry.Shutdown.
+func Deregister() {
+	registrationMu.Lock()
+	cancel := registryCancelFunc
+	registryCancelFunc = nil
+	registrationMu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// Registrations returns the registration status of the metrics endpoint registered by Setup,
+// it returns nil if the endpoint is not registered.
+func Registrations() []RegistrationStatus {
+	registrationMu.Lock()
+	reg := activeRegistry
+	registrationMu.Unlock()
+	if r, ok := reg.(registry.StatusReporter); ok {
+		return r.Status()
+	}
+	return nil
+}
+
+// RegistrationHandler http handler of the registration status in JSON
+func RegistrationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := Registrations()
+		if statuses == nil {
+			statuses = []RegistrationStatus{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(statuses)
+	})
+}
+
+// registrationCollector exports the registration status
+type registrationCollector struct{}
+
+// Describe implements prometheus.Collector
+func (registrationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- registrationStatusDesc
+	ch <- registrationLastSuccessDesc
+	ch <- registrationReregistrationsDesc
+}
+
+// Collect implements prometheus.Collector
+func (registrationCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, s := range Registrations() {
+		registered := 0.0
+		if s.Registered {
+			registered = 1
+		}
+		ch <- prometheus.MustNewConstMetric(registrationStatusDesc, prometheus.GaugeValue, registered, s.Registry)
+		var lastSuccess float64
+		if !s.LastSuccess.IsZero() {
+			lastSuccess = float64(s.LastSuccess.UnixNano()) / 1e9
+		}
+		ch <- prometheus.MustNewConstMetric(registrationLastSuccessDesc, prometheus.GaugeValue,
+			lastSuccess, s.Registry)
+		ch <- prometheus.MustNewConstMetric(registrationReregistrationsDesc, prometheus.CounterValue,
+			float64(s.Reregistrations), s.Registry)
+	}
+}
@@ -0,0 +1,322 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
+	"trpc.group/trpc-go/trpc-opentelemetry/exporter/retry"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/internal/prompb"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/internal/registry"
+)
+
+const (
+	remoteWriteVersion = "0.1.0"
+	// remoteWriteMaxErrMsgLen max length of the response body kept in the error
+	remoteWriteMaxErrMsgLen = 256
+)
+
+// remoteWriter gathers metrics and sends them to a prometheus remote-write endpoint.
+type remoteWriter struct {
+	cfg            RemoteWriteConfig
+	gatherer       prometheus.Gatherer
+	client         *http.Client
+	retry          retry.Config
+	externalLabels []prompb.Label
+	now            func() time.Time
+}
+
+func newRemoteWriter(cfg RemoteWriteConfig, ins Instance, gatherer prometheus.Gatherer) *remoteWriter {
+	cfg = cfg.withDefaults()
+	return &remoteWriter{
+		cfg:      cfg,
+		gatherer: gatherer,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		retry: retry.Config{
+			Enabled:         cfg.MaxRetryElapsedTime > 0,
+			InitialInterval: retry.DefaultConfig.InitialInterval,
+			MaxInterval:     retry.DefaultConfig.MaxInterval,
+			MaxElapsedTime:  cfg.MaxRetryElapsedTime,
+		},
+		externalLabels: remoteWriteExternalLabels(ins.Metadata, cfg.ExternalLabels),
+		now:            time.Now,
+	}
+}
+
+// remoteWriteExternalLabels merges metadata and labels, sorted by name, invalid characters of names are replaced by _.
+func remoteWriteExternalLabels(metadata, labels map[string]string) []prompb.Label {
+	merged := make(map[string]string, len(metadata)+len(labels))
+	for _, m := range []map[string]string{metadata, labels} {
+		for name, value := range m {
+			if name = registry.SanitizeLabelName(name); name != "" && value != "" {
+				merged[name] = value
+			}
+		}
+	}
+	result := make([]prompb.Label, 0, len(merged))
+	for name, value := range merged {
+		result = append(result, prompb.Label{Name: name, Value: value})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// write gathers and sends the metrics once.
+func (w *remoteWriter) write(ctx context.Context) error {
+	mfs, err := w.gatherer.Gather()
+	if err != nil && len(mfs) == 0 {
+		return err
+	}
+	series := familiesToTimeSeries(mfs, w.externalLabels, w.now().UnixNano()/int64(time.Millisecond))
+	if len(series) == 0 {
+		return nil
+	}
+	data, err := (&prompb.WriteRequest{Timeseries: series}).Marshal()
+	if err != nil {
+		return err
+	}
+	return w.send(ctx, snappy.Encode(nil, data))
+}
+
+// send posts the body, retrying on network errors, 5xx and 429 with backoff.
+func (w *remoteWriter) send(ctx context.Context, body []byte) error {
+	// a new RequestFunc per request, the backoff state is kept in it
+	requestFunc := w.retry.RequestFunc(evaluateRemoteWriteErr)
+	return requestFunc(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.cfg.URL, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Encoding", "snappy")
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("User-Agent", "trpc-opentelemetry-remote-write")
+		req.Header.Set("X-Prometheus-Remote-Write-Version", remoteWriteVersion)
+		for k, v := range w.cfg.HTTPHeaders {
+			req.Header.Set(k, v)
+		}
+		if w.cfg.BearerToken != "" {
+			req.Header.Set("Authorization", "Bearer "+w.cfg.BearerToken)
+		} else if w.cfg.Username != "" {
+			req.SetBasicAuth(w.cfg.Username, w.cfg.Password)
+		}
+		resp, err := w.client.Do(req)
+		if err != nil {
+			return &remoteWriteError{err: err, retryable: true}
+		}
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, remoteWriteMaxErrMsgLen))
+		_, _ = io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode/100 == 2 {
+			return nil
+		}
+		rwErr := &remoteWriteError{
+			err: fmt.Errorf("remote write %s: %s: %s", w.cfg.URL, resp.Status, bytes.TrimSpace(msg)),
+		}
+		if resp.StatusCode/100 == 5 || resp.StatusCode == http.StatusTooManyRequests {
+			rwErr.retryable = true
+			if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+				rwErr.throttle = time.Duration(seconds) * time.Second
+			}
+		}
+		return rwErr
+	})
+}
+
+// remoteWriteError error of a remote-write request
+type remoteWriteError struct {
+	err       error
+	retryable bool
+	throttle  time.Duration
+}
+
+func (e *remoteWriteError) Error() string {
+	return e.err.Error()
+}
+
+func (e *remoteWriteError) Unwrap() error {
+	return e.err
+}
+
+func evaluateRemoteWriteErr(err error) (bool, time.Duration) {
+	if e, ok := err.(*remoteWriteError); ok {
+		return e.retryable, e.throttle
+	}
+	return false, 0
+}
+
+// start writes the metrics every interval until the returned stop is called,
+// stop sends the metrics for the last time.
+func (w *remoteWriter) start() (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(w.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := w.write(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("opentelemetry: prometheus remote write err: %v", err)
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			<-done
+			ctx, cancel := context.WithTimeout(context.Background(), w.cfg.Timeout)
+			defer cancel()
+			if err := w.write(ctx); err != nil {
+				log.Printf("opentelemetry: prometheus remote write on stop err: %v", err)
+			}
+		})
+	}
+}
+
+var (
+	remoteWriteMu       sync.Mutex
+	remoteWriteStopFunc func()
+)
+
+// startRemoteWrite starts w after stopping the sender of the previous Setup,
+// the sender is stopped by opentelemetry.Shutdown.
+func startRemoteWrite(w *remoteWriter) {
+	StopRemoteWrite()
+	stop := w.start()
+	remoteWriteMu.Lock()
+	remoteWriteStopFunc = stop
+	remoteWriteMu.Unlock()
+	// the hooks are removed by Shutdown, so it is registered by each start, the previous one has been stopped
+	opentelemetry.RegisterShutdownHook(func(ctx context.Context) error {
+		return callWithContext(ctx, StopRemoteWrite)
+	})
+}
+
+// StopRemoteWrite stops the prometheus remote-write sender after sending the metrics for the last time,
+// it is called by opentelemetry.Shutdown.
+func StopRemoteWrite() {
+	remoteWriteMu.Lock()
+	stop := remoteWriteStopFunc
+	remoteWriteStopFunc = nil
+	remoteWriteMu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// familiesToTimeSeries converts metric families to remote-write series as the text exposition format does:
+// histograms are split into _bucket, _sum and _count series, and summaries into quantiles, _sum and _count.
+// Labels of the metric take precedence over the external labels.
+func familiesToTimeSeries(mfs []*dto.MetricFamily, external []prompb.Label, nowMs int64) []prompb.TimeSeries {
+	var series []prompb.TimeSeries
+	for _, mf := range mfs {
+		name := mf.GetName()
+		for _, m := range mf.GetMetric() {
+			ts := nowMs
+			if m.TimestampMs != nil {
+				ts = m.GetTimestampMs()
+			}
+			add := func(name string, value float64, extra ...string) {
+				series = append(series, prompb.TimeSeries{
+					Labels:  seriesLabels(name, m.GetLabel(), external, extra...),
+					Samples: []prompb.Sample{{Value: value, Timestamp: ts}},
+				})
+			}
+			switch mf.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, m.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, m.GetGauge().GetValue())
+			case dto.MetricType_UNTYPED:
+				add(name, m.GetUntyped().GetValue())
+			case dto.MetricType_SUMMARY:
+				s := m.GetSummary()
+				for _, q := range s.GetQuantile() {
+					add(name, q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				add(name+"_sum", s.GetSampleSum())
+				add(name+"_count", float64(s.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				h := m.GetHistogram()
+				hasInf := false
+				for _, b := range h.GetBucket() {
+					if math.IsInf(b.GetUpperBound(), 1) {
+						hasInf = true
+					}
+					add(name+"_bucket", float64(b.GetCumulativeCount()), "le", formatFloat(b.GetUpperBound()))
+				}
+				if !hasInf {
+					add(name+"_bucket", float64(h.GetSampleCount()), "le", "+Inf")
+				}
+				add(name+"_sum", h.GetSampleSum())
+				add(name+"_count", float64(h.GetSampleCount()))
+			}
+		}
+	}
+	return series
+}
+
+// seriesLabels returns the sorted labels of a series, extra are name/value pairs.
+func seriesLabels(name string, pairs []*dto.LabelPair, external []prompb.Label, extra ...string) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(pairs)+len(external)+len(extra)/2+1)
+	labels = append(labels, prompb.Label{Name: "__name__", Value: name})
+	seen := make(map[string]bool, len(pairs)+len(extra)/2)
+	for _, p := range pairs {
+		labels = append(labels, prompb.Label{Name: p.GetName(), Value: p.GetValue()})
+		seen[p.GetName()] = true
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		labels = append(labels, prompb.Label{Name: extra[i], Value: extra[i+1]})
+		seen[extra[i]] = true
+	}
+	for _, l := range external {
+		if !seen[l.Name] {
+			labels = append(labels, l)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].Name < labels[j].Name })
+	return labels
+}
+
+// formatFloat formats le and quantile label values as the text exposition format does
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
@@ -0,0 +1,245 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
+	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/internal/prompb"
+)
+
+// remoteWriteStandIn a local remote-write endpoint recording the decoded requests
+type remoteWriteStandIn struct {
+	mu       sync.Mutex
+	requests []*prompb.WriteRequest
+	headers  []http.Header
+	// failures number of requests answered with status before succeeding
+	failures int32
+	status   int
+}
+
+func (s *remoteWriteStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if atomic.AddInt32(&s.failures, -1) >= 0 {
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte("stand-in error"))
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	data, err := snappy.Decode(nil, body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	req := &prompb.WriteRequest{}
+	if err := req.Unmarshal(data); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	s.headers = append(s.headers, r.Header.Clone())
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func seriesByName(req *prompb.WriteRequest) map[string][]prompb.TimeSeries {
+	result := make(map[string][]prompb.TimeSeries)
+	for _, ts := range req.Timeseries {
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				result[l.Value] = append(result[l.Value], ts)
+			}
+		}
+	}
+	return result
+}
+
+func labelMap(ts prompb.TimeSeries) map[string]string {
+	m := make(map[string]string, len(ts.Labels))
+	for _, l := range ts.Labels {
+		m[l.Name] = l.Value
+	}
+	return m
+}
+
+func newTestRemoteWriteRegistry(t *testing.T) *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test_requests_total", Help: "test"},
+		[]string{"app"})
+	counter.WithLabelValues("local").Add(3)
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name: "test_latency_seconds", Help: "test", Buckets: []float64{0.1, 1}})
+	histogram.Observe(0.5)
+	summary := prometheus.NewSummary(prometheus.SummaryOpts{
+		Name: "test_size_bytes", Help: "test", Objectives: map[float64]float64{0.5: 0.05}})
+	summary.Observe(10)
+	require.NoError(t, reg.Register(counter))
+	require.NoError(t, reg.Register(histogram))
+	require.NoError(t, reg.Register(summary))
+	return reg
+}
+
+func TestRemoteWriter_Write(t *testing.T) {
+	standIn := &remoteWriteStandIn{}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	w := newRemoteWriter(RemoteWriteConfig{
+		Enabled:        true,
+		URL:            srv.URL,
+		BearerToken:    "token",
+		ExternalLabels: map[string]string{"cluster": "c1", "env-name": "test"},
+		HTTPHeaders:    map[string]string{"X-Scope-OrgID": "tenant"},
+	}, Instance{Addr: "127.0.0.1:8080", Metadata: map[string]string{"app": "app1", "server": "server1"}},
+		newTestRemoteWriteRegistry(t))
+	w.now = func() time.Time { return time.Unix(100, 0) }
+	require.NoError(t, w.write(context.Background()))
+
+	require.Len(t, standIn.requests, 1)
+	h := standIn.headers[0]
+	assert.Equal(t, "snappy", h.Get("Content-Encoding"))
+	assert.Equal(t, "application/x-protobuf", h.Get("Content-Type"))
+	assert.Equal(t, remoteWriteVersion, h.Get("X-Prometheus-Remote-Write-Version"))
+	assert.Equal(t, "Bearer token", h.Get("Authorization"))
+	assert.Equal(t, "tenant", h.Get("X-Scope-OrgID"))
+
+	series := seriesByName(standIn.requests[0])
+	require.Len(t, series["test_requests_total"], 1)
+	counter := series["test_requests_total"][0]
+	// the label of the metric takes precedence over the external label
+	assert.Equal(t, map[string]string{"__name__": "test_requests_total", "app": "local", "server": "server1",
+		"cluster": "c1", "env_name": "test"}, labelMap(counter))
+	assert.Equal(t, []prompb.Sample{{Value: 3, Timestamp: 100000}}, counter.Samples)
+	for i := 1; i < len(counter.Labels); i++ {
+		assert.Less(t, counter.Labels[i-1].Name, counter.Labels[i].Name)
+	}
+
+	buckets := make(map[string]float64)
+	for _, ts := range series["test_latency_seconds_bucket"] {
+		buckets[labelMap(ts)["le"]] = ts.Samples[0].Value
+	}
+	assert.Equal(t, map[string]float64{"0.1": 0, "1": 1, "+Inf": 1}, buckets)
+	assert.Equal(t, 0.5, series["test_latency_seconds_sum"][0].Samples[0].Value)
+	assert.Equal(t, 1.0, series["test_latency_seconds_count"][0].Samples[0].Value)
+
+	require.Len(t, series["test_size_bytes"], 1)
+	assert.Equal(t, "0.5", labelMap(series["test_size_bytes"][0])["quantile"])
+	assert.Equal(t, 1.0, series["test_size_bytes_count"][0].Samples[0].Value)
+}
+
+func TestRemoteWriter_Retry(t *testing.T) {
+	standIn := &remoteWriteStandIn{failures: 2, status: http.StatusServiceUnavailable}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	w := newRemoteWriter(RemoteWriteConfig{URL: srv.URL, Username: "user", Password: "pass"},
+		Instance{}, newTestRemoteWriteRegistry(t))
+	w.retry.InitialInterval = time.Millisecond
+	w.retry.MaxInterval = time.Millisecond
+	require.NoError(t, w.write(context.Background()))
+	require.Len(t, standIn.requests, 1)
+	user, pass, ok := (&http.Request{Header: standIn.headers[0]}).BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "user", user)
+	assert.Equal(t, "pass", pass)
+
+	// 4xx is not retried
+	standIn.failures, standIn.status = 1, http.StatusBadRequest
+	err := w.write(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stand-in error")
+	assert.Len(t, standIn.requests, 1)
+
+	// retry disabled
+	standIn.failures, standIn.status = 1, http.StatusInternalServerError
+	w = newRemoteWriter(RemoteWriteConfig{URL: srv.URL, MaxRetryElapsedTime: -1}, Instance{},
+		newTestRemoteWriteRegistry(t))
+	assert.Error(t, w.write(context.Background()))
+}
+
+func TestRemoteWriter_Start(t *testing.T) {
+	standIn := &remoteWriteStandIn{}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+
+	w := newRemoteWriter(RemoteWriteConfig{URL: srv.URL, Interval: 10 * time.Millisecond}, Instance{},
+		newTestRemoteWriteRegistry(t))
+	stop := w.start()
+	assert.Eventually(t, func() bool {
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		return len(standIn.requests) >= 2
+	}, time.Second, 5*time.Millisecond)
+	stop()
+	standIn.mu.Lock()
+	n := len(standIn.requests)
+	standIn.mu.Unlock()
+	stop()
+	time.Sleep(30 * time.Millisecond)
+	assert.Len(t, standIn.requests, n)
+}
+
+func TestSetupByConfig_RemoteWriteWithRegister(t *testing.T) {
+	standIn := &remoteWriteStandIn{}
+	srv := httptest.NewServer(standIn)
+	defer srv.Close()
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer, prometheus.DefaultGatherer = reg, reg
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+	}()
+
+	err := SetupByConfig(Config{
+		Enabled:         true,
+		EnabledRegister: true,
+		Registry:        RegistryFileSD,
+		FileSD:          FileSDConfig{Path: t.TempDir() + "/targets.json"},
+		Instance:        Instance{TenantID: "default", Addr: "127.0.0.1:9999"},
+		RemoteWrite:     RemoteWriteConfig{Enabled: true, URL: srv.URL, Interval: 10 * time.Millisecond},
+	})
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		standIn.mu.Lock()
+		defer standIn.mu.Unlock()
+		return len(standIn.requests) >= 1
+	}, time.Second, 5*time.Millisecond)
+
+	// stopped by opentelemetry.Shutdown
+	require.NoError(t, opentelemetry.Shutdown(context.Background()))
+	standIn.mu.Lock()
+	n := len(standIn.requests)
+	standIn.mu.Unlock()
+	time.Sleep(30 * time.Millisecond)
+	standIn.mu.Lock()
+	defer standIn.mu.Unlock()
+	assert.Len(t, standIn.requests, n)
+}
@@ -49,7 +49,7 @@ var (
 			"code", "code_type", "code_desc",
 		},
 	)
-	clientHandledHistogram = prometheus.NewHistogramVec(
+	clientHandledHistogram = newHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: "rpc",
 			Name:      "client_handled_seconds",
@@ -60,6 +60,7 @@ var (
 			"system_name", "caller_service", "caller_method", "callee_service", "callee_method",
 			"code", "code_type", "code_desc",
 		},
+		nil,
 	)
 )
 
@@ -15,6 +15,7 @@ package metric
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,6 +42,12 @@ type ClientReporter struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+
+	inFlight    prometheus.Gauge
+	handled     uint32
+	payloadSize payloadSize
+	peer        string
+	errorType   string
 }
 
 // ClientOption Client 调用参数工具函数。
@@ -104,6 +111,7 @@ func NewClientReporter(systemName, callerService, callerMethod, calleeService, c
 		calleeService: cleanServiceName(calleeService),
 		calleeMethod:  CleanRPCMethod(calleeMethod),
 		startTime:     time.Now(),
+		payloadSize:   unknownPayloadSize,
 	}
 	for _, opt := range options {
 		opt(r)
@@ -111,6 +119,8 @@ func NewClientReporter(systemName, callerService, callerMethod, calleeService, c
 	labelValues := []string{r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod}
 	labelValues = append(labelValues, r.extraLabels...)
 	clientStartedCounter.WithLabelValues(labelValues...).Inc()
+	r.inFlight = clientInFlightGauge.WithLabelValues(labelValues...)
+	r.inFlight.Inc()
 	return r
 }
 
@@ -119,7 +129,10 @@ func NewClientReporter(systemName, callerService, callerMethod, calleeService, c
 // codeType.Type, codeType.Description are reserved fields.
 // Add labels as extended fields. Note that using extended fields requires redefining the initialization function where sdk/metric/rpc_client_metrics.go:40 is located.
 func (r *ClientReporter) Handled(ctx context.Context, code string) {
-	codeType := codes.CodeMapping(code, r.calleeService, r.calleeMethod)
+	if atomic.CompareAndSwapUint32(&r.handled, 0, 1) {
+		r.inFlight.Dec()
+	}
+	codeType := codes.CodeMappingWithErrorType(code, r.errorType, r.calleeService, r.calleeMethod)
 	counterLabelValues := []string{
 		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
 		code, codeType.Type, codeType.Description,
@@ -131,7 +144,10 @@ func (r *ClientReporter) Handled(ctx context.Context, code string) {
 		code, codeType.Type, codeType.Description,
 	}
 	histogramLabelValues = append(histogramLabelValues, r.extraLabels...)
-	h := clientHandledHistogram.WithLabelValues(histogramLabelValues...)
+	h := clientHandledHistogram.vec(r.calleeService, r.calleeMethod).WithLabelValues(histogramLabelValues...)
+	if p := loadPayloadSizeHistograms(&clientPayloadSize); p != nil {
+		p.observe(histogramLabelValues, r.payloadSize)
+	}
 
 	if r.endTime.IsZero() {
 		r.endTime = time.Now()
@@ -155,6 +171,9 @@ func (r *ClientReporter) Handled(ctx context.Context, code string) {
 	} else {
 		h.Observe(costSecs)
 	}
+	if t := loadPeerTracker(); t != nil && r.peer != "" {
+		t.observe(r.calleeService, r.peer, codeType.Type, r.endTime.Sub(r.startTime))
+	}
 }
 
 // counterNeedUseExemplar Check whether counter needs to be reported exemplar
@@ -221,6 +240,45 @@ func (r *ClientReporter) SentMessage() {
 	r.metrics.clientStreamMsgSent.WithLabelValues(r.streamLabels()...).Inc()
 }
 
+// SetPeer sets the address of the callee instance, used by per-peer metrics.
+func (r *ClientReporter) SetPeer(addr string) {
+	r.peer = addr
+}
+
+// SetErrorType sets the error type (business/framework/callee_framework) of the rpc,
+// which is used to match the error_type of code rules.
+func (r *ClientReporter) SetErrorType(errorType string) {
+	r.errorType = errorType
+}
+
+// SetRequestSize sets request body and metadata size (bytes),
+// which are reported by Handled if payload size histograms are enabled.
+func (r *ClientReporter) SetRequestSize(body, metadata int) {
+	r.payloadSize.requestBody = body
+	r.payloadSize.requestMetadata = metadata
+}
+
+// SetResponseSize sets response body and metadata size (bytes),
+// which are reported by Handled if payload size histograms are enabled.
+func (r *ClientReporter) SetResponseSize(body, metadata int) {
+	r.payloadSize.responseBody = body
+	r.payloadSize.responseMetadata = metadata
+}
+
+// ReceivedMessageSize reports the size (bytes) of a message received by stream rpc.
+func (r *ClientReporter) ReceivedMessageSize(size int) {
+	if p := loadPayloadSizeHistograms(&clientPayloadSize); p != nil {
+		p.streamMsgReceived.WithLabelValues(r.streamLabels()...).Observe(float64(size))
+	}
+}
+
+// SentMessageSize reports the size (bytes) of a message sent by stream rpc.
+func (r *ClientReporter) SentMessageSize(size int) {
+	if p := loadPayloadSizeHistograms(&clientPayloadSize); p != nil {
+		p.streamMsgSent.WithLabelValues(r.streamLabels()...).Observe(float64(size))
+	}
+}
+
 func (r *ClientReporter) streamLabels() []string {
 	return []string{
 		r.systemName, string(r.rpcType), r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
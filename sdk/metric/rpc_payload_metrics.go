@@ -0,0 +1,182 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// ServerInFlightGauge the number of RPCs in flight on the server.
+	ServerInFlightGauge = "ServerInFlightGauge"
+	// ClientInFlightGauge the number of RPCs in flight on the client.
+	ClientInFlightGauge = "ClientInFlightGauge"
+)
+
+// payloadSizeBuckets buckets of payload size histograms, from 64B to 4MB
+var payloadSizeBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+
+var (
+	serverInFlightGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "rpc",
+			Name:      "server_in_flight_requests",
+			Help:      "Number of RPCs currently in flight on the server.",
+		},
+		[]string{"system_name", "caller_service", "caller_method", "callee_service", "callee_method"},
+	)
+	clientInFlightGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "rpc",
+			Name:      "client_in_flight_requests",
+			Help:      "Number of RPCs currently in flight on the client.",
+		},
+		[]string{"system_name", "caller_service", "caller_method", "callee_service", "callee_method"},
+	)
+
+	// serverPayloadSize, clientPayloadSize store *payloadSizeHistograms, nil means payload size is not reported.
+	serverPayloadSize atomic.Value
+	clientPayloadSize atomic.Value
+)
+
+// payloadSizeHistograms request/response body and metadata size histograms of one side.
+type payloadSizeHistograms struct {
+	requestBody      *prometheus.HistogramVec
+	requestMetadata  *prometheus.HistogramVec
+	responseBody     *prometheus.HistogramVec
+	responseMetadata *prometheus.HistogramVec
+	// streamMsgReceived, streamMsgSent per message size of stream rpc
+	streamMsgReceived *prometheus.HistogramVec
+	streamMsgSent     *prometheus.HistogramVec
+}
+
+func newPayloadSizeHistograms(side string, labelNames []string, opts ...HistogramOption) *payloadSizeHistograms {
+	newVec := func(name, help string, labelNames []string) *prometheus.HistogramVec {
+		o := prometheus.HistogramOpts{
+			Subsystem: "rpc",
+			Name:      side + "_" + name,
+			Help:      help,
+			Buckets:   payloadSizeBuckets,
+		}
+		for _, opt := range opts {
+			opt(&o)
+		}
+		return prometheus.NewHistogramVec(o, labelNames)
+	}
+	return &payloadSizeHistograms{
+		requestBody: newVec("request_body_bytes",
+			"Histogram of request body size (bytes) of RPC on the "+side+".", labelNames),
+		requestMetadata: newVec("request_metadata_bytes",
+			"Histogram of request metadata size (bytes) of RPC on the "+side+".", labelNames),
+		responseBody: newVec("response_body_bytes",
+			"Histogram of response body size (bytes) of RPC on the "+side+".", labelNames),
+		responseMetadata: newVec("response_metadata_bytes",
+			"Histogram of response metadata size (bytes) of RPC on the "+side+".", labelNames),
+		streamMsgReceived: newVec("stream_msg_received_bytes",
+			"Histogram of message size (bytes) received on the "+side+" streaming interface.", defaultStreamLabels),
+		streamMsgSent: newVec("stream_msg_sent_bytes",
+			"Histogram of message size (bytes) sent on the "+side+" streaming interface.", defaultStreamLabels),
+	}
+}
+
+func (p *payloadSizeHistograms) register(desc string) {
+	for name, vec := range map[string]*prometheus.HistogramVec{
+		"RequestBody":       p.requestBody,
+		"RequestMetadata":   p.requestMetadata,
+		"ResponseBody":      p.responseBody,
+		"ResponseMetadata":  p.responseMetadata,
+		"StreamMsgReceived": p.streamMsgReceived,
+		"StreamMsgSent":     p.streamMsgSent,
+	} {
+		prometheus.MustRegister(&LimitCardinalityCollector{vec, desc + name, rpcMetricsCardinalityLimit})
+	}
+}
+
+// Reset resets the metrics.
+func (p *payloadSizeHistograms) Reset() {
+	p.requestBody.Reset()
+	p.requestMetadata.Reset()
+	p.responseBody.Reset()
+	p.responseMetadata.Reset()
+	p.streamMsgReceived.Reset()
+	p.streamMsgSent.Reset()
+}
+
+// observe observes the payload sizes, negative size is ignored.
+func (p *payloadSizeHistograms) observe(labelValues []string, size payloadSize) {
+	if size.requestBody >= 0 {
+		p.requestBody.WithLabelValues(labelValues...).Observe(float64(size.requestBody))
+	}
+	if size.requestMetadata >= 0 {
+		p.requestMetadata.WithLabelValues(labelValues...).Observe(float64(size.requestMetadata))
+	}
+	if size.responseBody >= 0 {
+		p.responseBody.WithLabelValues(labelValues...).Observe(float64(size.responseBody))
+	}
+	if size.responseMetadata >= 0 {
+		p.responseMetadata.WithLabelValues(labelValues...).Observe(float64(size.responseMetadata))
+	}
+}
+
+// payloadSize payload sizes set by reporter, -1 means unknown.
+type payloadSize struct {
+	requestBody      int
+	requestMetadata  int
+	responseBody     int
+	responseMetadata int
+}
+
+var unknownPayloadSize = payloadSize{-1, -1, -1, -1}
+
+func loadPayloadSizeHistograms(v *atomic.Value) *payloadSizeHistograms {
+	p, _ := v.Load().(*payloadSizeHistograms)
+	return p
+}
+
+func registerRPCInFlightGauges() {
+	serverInFlightGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "rpc",
+			Name:      "server_in_flight_requests",
+			Help:      "Number of RPCs currently in flight on the server.",
+		},
+		serverLabelsOption(ServerStartedCounter),
+	)
+	clientInFlightGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: "rpc",
+			Name:      "client_in_flight_requests",
+			Help:      "Number of RPCs currently in flight on the client.",
+		},
+		clientLabelsOption(ClientStartedCounter),
+	)
+	prometheus.MustRegister(
+		&LimitCardinalityCollector{serverInFlightGauge, "serverInFlightGauge", rpcMetricsCardinalityLimit})
+	prometheus.MustRegister(
+		&LimitCardinalityCollector{clientInFlightGauge, "clientInFlightGauge", rpcMetricsCardinalityLimit})
+}
+
+// registerRPCPayloadSizeHistograms register server and client payload size histograms,
+// they are labelled like the handled histograms.
+func registerRPCPayloadSizeHistograms(cfg Config) {
+	native := WithHistogramNative(cfg.NativeHistogram)
+	server := newPayloadSizeHistograms("server", serverLabelsOption(ServerHandledHistogram), native)
+	client := newPayloadSizeHistograms("client", clientLabelsOption(ClientHandledHistogram), native)
+	server.register("serverPayloadSize")
+	client.register("clientPayloadSize")
+	serverPayloadSize.Store(server)
+	clientPayloadSize.Store(client)
+}
@@ -0,0 +1,104 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func gatherFamilies(t *testing.T, g prometheus.Gatherer) map[string]*dto.MetricFamily {
+	mfs, err := g.Gather()
+	require.NoError(t, err)
+	result := make(map[string]*dto.MetricFamily, len(mfs))
+	for _, mf := range mfs {
+		result[mf.GetName()] = mf
+	}
+	return result
+}
+
+func TestReporter_InFlightAndPayloadSize(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	prometheus.DefaultRegisterer = reg
+	prometheus.DefaultGatherer = reg
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+		serverPayloadSize.Store((*payloadSizeHistograms)(nil))
+		clientPayloadSize.Store((*payloadSizeHistograms)(nil))
+	}()
+	registerRPCServerCounter()
+	registerRPCClientCounter()
+	registerRPCHandledHistograms(*DefaultConfig())
+	registerRPCInFlightGauges()
+	registerRPCPayloadSizeHistograms(*DefaultConfig())
+
+	sr := NewServerReporter("trpc", "caller", "/caller/M", "callee", "/callee/M")
+	cr := NewClientReporter("trpc", "callee", "/callee/M", "down", "/down/M")
+	mfs := gatherFamilies(t, reg)
+	assert.Equal(t, 1.0, mfs["rpc_server_in_flight_requests"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, 1.0, mfs["rpc_client_in_flight_requests"].GetMetric()[0].GetGauge().GetValue())
+
+	sr.SetRequestSize(100, 10)
+	sr.SetResponseSize(2000, 20)
+	sr.Handled(context.Background(), "0")
+	cr.SetRequestSize(300, -1)
+	cr.Handled(context.Background(), "0")
+
+	mfs = gatherFamilies(t, reg)
+	assert.Equal(t, 0.0, mfs["rpc_server_in_flight_requests"].GetMetric()[0].GetGauge().GetValue())
+	assert.Equal(t, 0.0, mfs["rpc_client_in_flight_requests"].GetMetric()[0].GetGauge().GetValue())
+	for name, want := range map[string]float64{
+		"rpc_server_request_body_bytes":      100,
+		"rpc_server_request_metadata_bytes":  10,
+		"rpc_server_response_body_bytes":     2000,
+		"rpc_server_response_metadata_bytes": 20,
+		"rpc_client_request_body_bytes":      300,
+	} {
+		require.Contains(t, mfs, name)
+		m := mfs[name].GetMetric()[0]
+		assert.Equal(t, want, m.GetHistogram().GetSampleSum(), name)
+		assert.Equal(t, "0", labelValue(m, "code"), name) // labelled like the handled metrics
+	}
+	// unknown sizes are not reported
+	assert.NotContains(t, mfs, "rpc_client_request_metadata_bytes")
+	assert.NotContains(t, mfs, "rpc_client_response_body_bytes")
+
+	sr.Handled(context.Background(), "0") // in-flight is decreased only once
+	mfs = gatherFamilies(t, reg)
+	assert.Equal(t, 0.0, mfs["rpc_server_in_flight_requests"].GetMetric()[0].GetGauge().GetValue())
+
+	sr = NewServerReporter("trpc", "caller", "/caller/M", "callee", "/callee/Stream",
+		WithServerRPCType(BidiStream))
+	sr.ReceivedMessageSize(5)
+	sr.SentMessageSize(7)
+	mfs = gatherFamilies(t, reg)
+	assert.Equal(t, 5.0, mfs["rpc_server_stream_msg_received_bytes"].GetMetric()[0].GetHistogram().GetSampleSum())
+	assert.Equal(t, 7.0, mfs["rpc_server_stream_msg_sent_bytes"].GetMetric()[0].GetHistogram().GetSampleSum())
+}
+
+func TestReporter_PayloadSizeDisabled(t *testing.T) {
+	serverPayloadSize.Store((*payloadSizeHistograms)(nil))
+	r := NewServerReporter("trpc", "caller", "/caller/M", "callee", "/callee/M")
+	r.SetRequestSize(1, 1)
+	r.ReceivedMessageSize(1)
+	r.SentMessageSize(1)
+	r.Handled(context.Background(), "0")
+	assert.Nil(t, loadPayloadSizeHistograms(&serverPayloadSize))
+}
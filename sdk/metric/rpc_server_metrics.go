@@ -62,7 +62,7 @@ var (
 			"code", "code_type", "code_desc",
 		},
 	)
-	serverHandledHistogram = prometheus.NewHistogramVec(
+	serverHandledHistogram = newHistogramVec(
 		prometheus.HistogramOpts{
 			Subsystem: "rpc",
 			Name:      "server_handled_seconds",
@@ -73,6 +73,7 @@ var (
 			"system_name", "caller_service", "caller_method", "callee_service", "callee_method",
 			"code", "code_type", "code_desc",
 		},
+		nil,
 	)
 )
 
@@ -125,25 +126,26 @@ func initServerCollectors() {
 }
 
 // registerRPCHandledHistograms register server and client rpc metrics with histogram
-func registerRPCHandledHistograms() {
-	serverHandledHistogram = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Subsystem: "rpc",
-			Name:      "server_handled_seconds",
-			Help:      "Histogram of response latency (seconds) of RPC that had been application-level handled by the server.",
-			Buckets:   serverHandledHistogramBuckets,
-		},
-		serverLabelsOption(ServerHandledHistogram),
-	)
-	clientHandledHistogram = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Subsystem: "rpc",
-			Name:      "client_handled_seconds",
-			Help:      "Histogram of response latency (seconds) of the RPC until it is finished by the application.",
-			Buckets:   clientHandledHistogramBuckets,
-		},
-		clientLabelsOption(ClientHandledHistogram),
-	)
+func registerRPCHandledHistograms(cfg Config) {
+	native := WithHistogramNative(cfg.NativeHistogram)
+	serverOpts := prometheus.HistogramOpts{
+		Subsystem: "rpc",
+		Name:      "server_handled_seconds",
+		Help:      "Histogram of response latency (seconds) of RPC that had been application-level handled by the server.",
+		Buckets:   serverHandledHistogramBuckets,
+	}
+	native(&serverOpts)
+	serverHandledHistogram = newHistogramVec(serverOpts, serverLabelsOption(ServerHandledHistogram),
+		cfg.ServerHistogramBucketsOverrides)
+	clientOpts := prometheus.HistogramOpts{
+		Subsystem: "rpc",
+		Name:      "client_handled_seconds",
+		Help:      "Histogram of response latency (seconds) of the RPC until it is finished by the application.",
+		Buckets:   clientHandledHistogramBuckets,
+	}
+	native(&clientOpts)
+	clientHandledHistogram = newHistogramVec(clientOpts, clientLabelsOption(ClientHandledHistogram),
+		cfg.ClientHistogramBucketsOverrides)
 
 	prometheus.MustRegister(
 		&LimitCardinalityCollector{serverHandledHistogram, "serverHandledHistogram", rpcMetricsCardinalityLimit})
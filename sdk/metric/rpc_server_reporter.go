@@ -15,6 +15,7 @@ package metric
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -41,6 +42,11 @@ type ServerReporter struct {
 	enableDeferredSample       bool
 	deferredSampleError        bool
 	deferredSampleSlowDuration time.Duration
+
+	inFlight    prometheus.Gauge
+	handled     uint32
+	payloadSize payloadSize
+	errorType   string
 }
 
 // ServerOption Server option
@@ -105,6 +111,7 @@ func NewServerReporter(systemName, callerService, callerMethod, calleeService, c
 		calleeService: cleanServiceName(calleeService),
 		calleeMethod:  CleanRPCMethod(calleeMethod),
 		startTime:     time.Now(),
+		payloadSize:   unknownPayloadSize,
 	}
 	for _, opt := range options {
 		opt(r)
@@ -112,6 +119,8 @@ func NewServerReporter(systemName, callerService, callerMethod, calleeService, c
 	labelValues := []string{r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod}
 	labelValues = append(labelValues, r.extraLabels...)
 	serverStartedCounter.WithLabelValues(labelValues...).Inc()
+	r.inFlight = serverInFlightGauge.WithLabelValues(labelValues...)
+	r.inFlight.Inc()
 	return r
 }
 
@@ -121,7 +130,10 @@ func NewServerReporter(systemName, callerService, callerMethod, calleeService, c
 // Add labels as extended fields. Note that using extended fields requires redefining the initialization function
 // in sdk/metric/rpc_server_metrics.go.
 func (r *ServerReporter) Handled(ctx context.Context, code string) {
-	codeType := codes.CodeMapping(code, r.calleeService, r.calleeMethod)
+	if atomic.CompareAndSwapUint32(&r.handled, 0, 1) {
+		r.inFlight.Dec()
+	}
+	codeType := codes.CodeMappingWithErrorType(code, r.errorType, r.calleeService, r.calleeMethod)
 	counterLabelValues := []string{
 		r.systemName, r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
 		code, codeType.Type, codeType.Description,
@@ -133,7 +145,10 @@ func (r *ServerReporter) Handled(ctx context.Context, code string) {
 		code, codeType.Type, codeType.Description,
 	}
 	histogramLabelValues = append(histogramLabelValues, r.extraLabels...)
-	h := serverHandledHistogram.WithLabelValues(histogramLabelValues...)
+	h := serverHandledHistogram.vec(r.calleeService, r.calleeMethod).WithLabelValues(histogramLabelValues...)
+	if p := loadPayloadSizeHistograms(&serverPayloadSize); p != nil {
+		p.observe(histogramLabelValues, r.payloadSize)
+	}
 
 	if r.endTime.IsZero() {
 		r.endTime = time.Now()
@@ -189,6 +204,40 @@ func (r *ServerReporter) SentMessage() {
 	r.metrics.serverStreamMsgSent.WithLabelValues(r.streamLabels()...).Inc()
 }
 
+// SetErrorType sets the error type (business/framework/callee_framework) of the rpc,
+// which is used to match the error_type of code rules.
+func (r *ServerReporter) SetErrorType(errorType string) {
+	r.errorType = errorType
+}
+
+// SetRequestSize sets request body and metadata size (bytes),
+// which are reported by Handled if payload size histograms are enabled.
+func (r *ServerReporter) SetRequestSize(body, metadata int) {
+	r.payloadSize.requestBody = body
+	r.payloadSize.requestMetadata = metadata
+}
+
+// SetResponseSize sets response body and metadata size (bytes),
+// which are reported by Handled if payload size histograms are enabled.
+func (r *ServerReporter) SetResponseSize(body, metadata int) {
+	r.payloadSize.responseBody = body
+	r.payloadSize.responseMetadata = metadata
+}
+
+// ReceivedMessageSize reports the size (bytes) of a message received by stream rpc.
+func (r *ServerReporter) ReceivedMessageSize(size int) {
+	if p := loadPayloadSizeHistograms(&serverPayloadSize); p != nil {
+		p.streamMsgReceived.WithLabelValues(r.streamLabels()...).Observe(float64(size))
+	}
+}
+
+// SentMessageSize reports the size (bytes) of a message sent by stream rpc.
+func (r *ServerReporter) SentMessageSize(size int) {
+	if p := loadPayloadSizeHistograms(&serverPayloadSize); p != nil {
+		p.streamMsgSent.WithLabelValues(r.streamLabels()...).Observe(float64(size))
+	}
+}
+
 func (r *ServerReporter) streamLabels() []string {
 	return []string{
 		r.systemName, string(r.rpcType), r.callerService, r.callerMethod, r.calleeService, r.calleeMethod,
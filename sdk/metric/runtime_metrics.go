@@ -0,0 +1,352 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"log"
+	"math"
+	"runtime/metrics"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelmetric "go.opentelemetry.io/otel/metric"
+)
+
+// runtimeMetricKind how a runtime metric is exported
+type runtimeMetricKind int
+
+const (
+	runtimeGauge runtimeMetricKind = iota
+	runtimeCounter
+	runtimeHistogram
+)
+
+// runtimeMetric maps Go runtime/metrics to an exported metric.
+type runtimeMetric struct {
+	// name exported name, it is the same for prometheus and OTLP
+	name string
+	help string
+	kind runtimeMetricKind
+	// sources runtime/metrics names in order of preference, the first supported one is read,
+	// as some metrics are renamed between go versions.
+	sources []string
+	// class value of the "class" label, only used by runtime_cpu_seconds_total
+	class string
+}
+
+// runtimeCPUClassMetric returns the runtime_cpu_seconds_total metric of a cpu class
+func runtimeCPUClassMetric(class, source string) runtimeMetric {
+	return runtimeMetric{
+		name:    "runtime_cpu_seconds_total",
+		help:    "Estimated total CPU time (seconds) spent by the Go runtime, by class.",
+		kind:    runtimeCounter,
+		sources: []string{source},
+		class:   class,
+	}
+}
+
+// runtimeHistogramQuantiles quantiles of histograms exported to OTLP,
+// as OTLP has no asynchronous histogram instrument
+var runtimeHistogramQuantiles = []float64{0.5, 0.9, 0.99, 1}
+
+// DefaultRuntimeHistogramBuckets default buckets of runtime latency histograms, 1us * 4^i, up to about 4s
+var DefaultRuntimeHistogramBuckets = prometheus.ExponentialBuckets(1e-6, 4, 12)
+
+// RuntimeMetricsConfig Go runtime/metrics collector config
+type RuntimeMetricsConfig struct {
+	// Enabled open or close
+	Enabled bool `yaml:"enabled"`
+	// Metrics exported metric names to keep, e.g. runtime_gc_pause_seconds, empty means all
+	Metrics []string `yaml:"metrics"`
+	// HistogramBuckets buckets (seconds) of latency histograms, default DefaultRuntimeHistogramBuckets
+	HistogramBuckets []float64 `yaml:"histogram_buckets"`
+}
+
+func (c RuntimeMetricsConfig) withDefaults() RuntimeMetricsConfig {
+	if len(c.HistogramBuckets) == 0 {
+		c.HistogramBuckets = DefaultRuntimeHistogramBuckets
+	}
+	return c
+}
+
+// availableRuntimeMetrics returns the metrics of runtimeMetrics supported by the running go version
+// and kept by cfg, and the runtime/metrics names they read from.
+func availableRuntimeMetrics(cfg RuntimeMetricsConfig) ([]runtimeMetric, []string) {
+	supported := make(map[string]bool)
+	for _, d := range metrics.All() {
+		supported[d.Name] = true
+	}
+	keep := make(map[string]bool)
+	for _, name := range cfg.Metrics {
+		keep[name] = true
+	}
+	var (
+		available []runtimeMetric
+		sources   []string
+	)
+	for _, m := range runtimeMetrics {
+		if len(keep) > 0 && !keep[m.name] {
+			continue
+		}
+		for _, source := range m.sources {
+			if supported[source] {
+				m.sources = []string{source}
+				available = append(available, m)
+				sources = append(sources, source)
+				break
+			}
+		}
+	}
+	return available, sources
+}
+
+// runtimeCollector reads Go runtime/metrics on collecting,
+// it implements prometheus.Collector and observes the OTLP instruments.
+type runtimeCollector struct {
+	metrics []runtimeMetric
+	buckets []float64
+	descs   map[string]*prometheus.Desc
+
+	mu      sync.Mutex
+	samples []metrics.Sample
+	// lastCounts histogram counts of the last OTLP observation, quantiles are computed over the interval
+	lastCounts map[string][]uint64
+}
+
+func newRuntimeCollector(cfg RuntimeMetricsConfig) *runtimeCollector {
+	cfg = cfg.withDefaults()
+	ms, sources := availableRuntimeMetrics(cfg)
+	c := &runtimeCollector{
+		metrics:    ms,
+		buckets:    cfg.HistogramBuckets,
+		descs:      make(map[string]*prometheus.Desc),
+		samples:    make([]metrics.Sample, len(sources)),
+		lastCounts: make(map[string][]uint64),
+	}
+	for i, source := range sources {
+		c.samples[i].Name = source
+	}
+	for _, m := range ms {
+		if _, ok := c.descs[m.name]; ok {
+			continue
+		}
+		var labels []string
+		if m.class != "" {
+			labels = []string{"class"}
+		}
+		c.descs[m.name] = prometheus.NewDesc(m.name, m.help, labels, nil)
+	}
+	return c
+}
+
+// read reads the samples and calls f with each metric and its value.
+func (c *runtimeCollector) read(f func(m runtimeMetric, v metrics.Value)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	metrics.Read(c.samples)
+	for i, m := range c.metrics {
+		if c.samples[i].Value.Kind() == metrics.KindBad {
+			continue
+		}
+		f(m, c.samples[i].Value)
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *runtimeCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range c.descs {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *runtimeCollector) Collect(ch chan<- prometheus.Metric) {
+	c.read(func(m runtimeMetric, v metrics.Value) {
+		desc := c.descs[m.name]
+		var labels []string
+		if m.class != "" {
+			labels = []string{m.class}
+		}
+		switch m.kind {
+		case runtimeHistogram:
+			count, sum, buckets := rebucketRuntimeHistogram(v.Float64Histogram(), c.buckets)
+			ch <- prometheus.MustNewConstHistogram(desc, count, sum, buckets, labels...)
+		case runtimeCounter:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.CounterValue, runtimeValue(v), labels...)
+		default:
+			ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, runtimeValue(v), labels...)
+		}
+	})
+}
+
+// registerOTLP registers the instruments to meter, and observes them in one callback.
+func (c *runtimeCollector) registerOTLP(meter otelmetric.Meter) error {
+	instruments := make(map[string]otelmetric.Float64Observable)
+	observables := make([]otelmetric.Observable, 0, len(c.descs))
+	for _, m := range c.metrics {
+		if _, ok := instruments[m.name]; ok {
+			continue
+		}
+		var (
+			inst otelmetric.Float64Observable
+			err  error
+		)
+		switch m.kind {
+		case runtimeCounter:
+			inst, err = meter.Float64ObservableCounter(m.name, otelmetric.WithDescription(m.help))
+		case runtimeHistogram:
+			inst, err = meter.Float64ObservableGauge(m.name, otelmetric.WithDescription(m.help+
+				" Quantiles over the collection interval."))
+		default:
+			inst, err = meter.Float64ObservableGauge(m.name, otelmetric.WithDescription(m.help))
+		}
+		if err != nil {
+			return err
+		}
+		instruments[m.name] = inst
+		observables = append(observables, inst)
+	}
+	_, err := meter.RegisterCallback(func(_ context.Context, o otelmetric.Observer) error {
+		c.read(func(m runtimeMetric, v metrics.Value) {
+			inst := instruments[m.name]
+			if m.kind != runtimeHistogram {
+				var opts []otelmetric.ObserveOption
+				if m.class != "" {
+					opts = append(opts, otelmetric.WithAttributes(attribute.String("class", m.class)))
+				}
+				o.ObserveFloat64(inst, runtimeValue(v), opts...)
+				return
+			}
+			h := v.Float64Histogram()
+			counts := deltaCounts(h.Counts, c.lastCounts[m.name])
+			c.lastCounts[m.name] = append(c.lastCounts[m.name][:0], h.Counts...)
+			for _, q := range runtimeHistogramQuantiles {
+				if value, ok := runtimeHistogramQuantile(q, counts, h.Buckets); ok {
+					o.ObserveFloat64(inst, value, otelmetric.WithAttributes(
+						attribute.String("quantile", strconv.FormatFloat(q, 'f', -1, 64))))
+				}
+			}
+		})
+		return nil
+	}, observables...)
+	return err
+}
+
+// runtimeValue returns the value of a uint64 or float64 metric as float64
+func runtimeValue(v metrics.Value) float64 {
+	if v.Kind() == metrics.KindUint64 {
+		return float64(v.Uint64())
+	}
+	return v.Float64()
+}
+
+// rebucketRuntimeHistogram converts a runtime histogram to a prometheus histogram with the given upper bounds.
+// Runtime buckets are much finer, the count of a runtime bucket goes to the first bucket not less than
+// its upper bound, and the sum is estimated by the midpoints of runtime buckets.
+func rebucketRuntimeHistogram(h *metrics.Float64Histogram, bounds []float64) (uint64, float64, map[float64]uint64) {
+	var (
+		count uint64
+		sum   float64
+	)
+	counts := make([]uint64, len(bounds))
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		lower, upper := h.Buckets[i], h.Buckets[i+1]
+		count += n
+		sum += float64(n) * runtimeBucketValue(lower, upper)
+		if idx := sort.SearchFloat64s(bounds, upper); idx < len(bounds) {
+			counts[idx] += n
+		}
+	}
+	buckets := make(map[float64]uint64, len(bounds))
+	var cumulative uint64
+	for i, bound := range bounds {
+		cumulative += counts[i]
+		buckets[bound] = cumulative
+	}
+	return count, sum, buckets
+}
+
+// runtimeBucketValue representative value of a runtime bucket, the midpoint or the finite bound
+func runtimeBucketValue(lower, upper float64) float64 {
+	switch {
+	case math.IsInf(lower, -1):
+		return upper
+	case math.IsInf(upper, 1):
+		return lower
+	default:
+		return (lower + upper) / 2
+	}
+}
+
+// runtimeHistogramQuantile estimates the q quantile with the upper bound of the bucket it falls in,
+// it returns false if there are no observations.
+func runtimeHistogramQuantile(q float64, counts []uint64, bounds []float64) (float64, bool) {
+	var total uint64
+	for _, n := range counts {
+		total += n
+	}
+	if total == 0 {
+		return 0, false
+	}
+	rank := uint64(math.Ceil(q * float64(total)))
+	if rank == 0 {
+		rank = 1
+	}
+	var cumulative uint64
+	for i, n := range counts {
+		cumulative += n
+		if cumulative >= rank {
+			if math.IsInf(bounds[i+1], 1) {
+				return bounds[i], true
+			}
+			return bounds[i+1], true
+		}
+	}
+	return bounds[len(bounds)-1], true
+}
+
+// deltaCounts returns counts minus last, last is ignored if its length differs
+func deltaCounts(counts, last []uint64) []uint64 {
+	if len(last) != len(counts) {
+		return counts
+	}
+	delta := make([]uint64, len(counts))
+	for i := range counts {
+		if counts[i] >= last[i] {
+			delta[i] = counts[i] - last[i]
+		}
+	}
+	return delta
+}
+
+// registerRuntimeMetrics registers the runtime/metrics collector to the prometheus default registry
+// and the global OTLP meter provider.
+func registerRuntimeMetrics(cfg RuntimeMetricsConfig) {
+	c := newRuntimeCollector(cfg)
+	prometheus.MustRegister(c)
+	if err := c.registerOTLP(otel.GetMeterProvider().Meter(runtimeMeterName)); err != nil {
+		log.Printf("opentelemetry: register runtime metrics to meter provider err: %v", err)
+	}
+}
+
+// runtimeMeterName instrumentation name of the runtime metrics meter
+const runtimeMeterName = "trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/runtime"
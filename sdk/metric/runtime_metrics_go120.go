@@ -0,0 +1,62 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build go1.20
+// +build go1.20
+
+package metric
+
+// runtimeMetrics metrics read from runtime/metrics, names not supported by the running go version are skipped.
+var runtimeMetrics = []runtimeMetric{
+	{
+		name:    "runtime_gc_pause_seconds",
+		help:    "Distribution of stop-the-world pause latencies (seconds) of the GC.",
+		kind:    runtimeHistogram,
+		sources: []string{"/sched/pauses/total/gc:seconds", "/gc/pauses:seconds"},
+	},
+	{
+		name:    "runtime_sched_latency_seconds",
+		help:    "Distribution of the time (seconds) goroutines have spent in the scheduler in a runnable state.",
+		kind:    runtimeHistogram,
+		sources: []string{"/sched/latencies:seconds"},
+	},
+	{
+		name:    "runtime_heap_goal_bytes",
+		help:    "Heap size target for the end of the GC cycle.",
+		sources: []string{"/gc/heap/goal:bytes"},
+	},
+	{
+		name:    "runtime_heap_live_bytes",
+		help:    "Heap memory occupied by live objects that were marked by the previous GC.",
+		sources: []string{"/gc/heap/live:bytes"},
+	},
+	{
+		name:    "runtime_goroutines",
+		help:    "Count of live goroutines.",
+		sources: []string{"/sched/goroutines:goroutines"},
+	},
+	{
+		name:    "runtime_mutex_wait_seconds_total",
+		help:    "Approximate cumulative time (seconds) goroutines have spent blocked on a sync.Mutex or sync.RWMutex.",
+		kind:    runtimeCounter,
+		sources: []string{"/sync/mutex/wait/total:seconds"},
+	},
+	runtimeCPUClassMetric("gc_mark_assist", "/cpu/classes/gc/mark/assist:cpu-seconds"),
+	runtimeCPUClassMetric("gc_mark_dedicated", "/cpu/classes/gc/mark/dedicated:cpu-seconds"),
+	runtimeCPUClassMetric("gc_mark_idle", "/cpu/classes/gc/mark/idle:cpu-seconds"),
+	runtimeCPUClassMetric("gc_pause", "/cpu/classes/gc/pause:cpu-seconds"),
+	runtimeCPUClassMetric("scavenge_assist", "/cpu/classes/scavenge/assist:cpu-seconds"),
+	runtimeCPUClassMetric("scavenge_background", "/cpu/classes/scavenge/background:cpu-seconds"),
+	runtimeCPUClassMetric("idle", "/cpu/classes/idle:cpu-seconds"),
+	runtimeCPUClassMetric("user", "/cpu/classes/user:cpu-seconds"),
+}
@@ -0,0 +1,44 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+//go:build !go1.20
+// +build !go1.20
+
+package metric
+
+// runtimeMetrics metrics read from runtime/metrics before go1.20,
+// which has no cpu classes, mutex wait time and live heap.
+var runtimeMetrics = []runtimeMetric{
+	{
+		name:    "runtime_gc_pause_seconds",
+		help:    "Distribution of stop-the-world pause latencies (seconds) of the GC.",
+		kind:    runtimeHistogram,
+		sources: []string{"/gc/pauses:seconds"},
+	},
+	{
+		name:    "runtime_sched_latency_seconds",
+		help:    "Distribution of the time (seconds) goroutines have spent in the scheduler in a runnable state.",
+		kind:    runtimeHistogram,
+		sources: []string{"/sched/latencies:seconds"},
+	},
+	{
+		name:    "runtime_heap_goal_bytes",
+		help:    "Heap size target for the end of the GC cycle.",
+		sources: []string{"/gc/heap/goal:bytes"},
+	},
+	{
+		name:    "runtime_goroutines",
+		help:    "Count of live goroutines.",
+		sources: []string{"/sched/goroutines:goroutines"},
+	},
+}
@@ -0,0 +1,133 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package metric
+
+import (
+	"context"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRuntimeCollector_Prometheus(t *testing.T) {
+	runtime.GC()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(newRuntimeCollector(RuntimeMetricsConfig{Enabled: true})))
+	mfs := gatherFamilies(t, reg)
+
+	goroutines := mfs["runtime_goroutines"]
+	require.NotNil(t, goroutines)
+	assert.Greater(t, goroutines.GetMetric()[0].GetGauge().GetValue(), 0.0)
+	require.NotNil(t, mfs["runtime_heap_goal_bytes"])
+
+	pause := mfs["runtime_gc_pause_seconds"]
+	require.NotNil(t, pause)
+	h := pause.GetMetric()[0].GetHistogram()
+	assert.Greater(t, h.GetSampleCount(), uint64(0))
+	assert.Len(t, h.GetBucket(), len(DefaultRuntimeHistogramBuckets))
+	require.NotNil(t, mfs["runtime_sched_latency_seconds"])
+
+	if cpu := mfs["runtime_cpu_seconds_total"]; cpu != nil {
+		classes := make(map[string]bool)
+		for _, m := range cpu.GetMetric() {
+			require.Len(t, m.GetLabel(), 1)
+			classes[labelValue(m, "class")] = true
+		}
+		assert.True(t, classes["user"])
+		assert.True(t, classes["gc_pause"])
+	}
+	for name := range mfs {
+		assert.Contains(t, []string{"runtime_gc_pause_seconds", "runtime_sched_latency_seconds",
+			"runtime_heap_goal_bytes", "runtime_heap_live_bytes", "runtime_goroutines",
+			"runtime_mutex_wait_seconds_total", "runtime_cpu_seconds_total"}, name)
+	}
+}
+
+func TestRuntimeCollector_Filter(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	require.NoError(t, reg.Register(newRuntimeCollector(RuntimeMetricsConfig{
+		Enabled: true,
+		Metrics: []string{"runtime_goroutines", "nonexistent"},
+	})))
+	mfs := gatherFamilies(t, reg)
+	assert.Len(t, mfs, 1)
+	assert.NotNil(t, mfs["runtime_goroutines"])
+}
+
+func TestRuntimeCollector_OTLP(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	c := newRuntimeCollector(RuntimeMetricsConfig{Enabled: true})
+	require.NoError(t, c.registerOTLP(provider.Meter(runtimeMeterName)))
+
+	runtime.GC()
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	data := make(map[string]metricdata.Aggregation)
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		data[m.Name] = m.Data
+	}
+	goroutines, ok := data["runtime_goroutines"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	assert.Greater(t, goroutines.DataPoints[0].Value, 0.0)
+
+	pause, ok := data["runtime_gc_pause_seconds"].(metricdata.Gauge[float64])
+	require.True(t, ok)
+	quantiles := make(map[string]bool)
+	for _, dp := range pause.DataPoints {
+		v, _ := dp.Attributes.Value(attribute.Key("quantile"))
+		quantiles[v.AsString()] = true
+	}
+	assert.Equal(t, map[string]bool{"0.5": true, "0.9": true, "0.99": true, "1": true}, quantiles)
+
+	if cpu, ok := data["runtime_cpu_seconds_total"].(metricdata.Sum[float64]); ok {
+		assert.True(t, cpu.IsMonotonic)
+		assert.Greater(t, len(cpu.DataPoints), 1)
+	}
+}
+
+func TestRebucketRuntimeHistogram(t *testing.T) {
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{1, 2, 3, 4},
+		Buckets: []float64{math.Inf(-1), 0.001, 0.002, 0.02, math.Inf(1)},
+	}
+	count, sum, buckets := rebucketRuntimeHistogram(h, []float64{0.001, 0.01, 0.1})
+	assert.Equal(t, uint64(10), count)
+	assert.InDelta(t, 1*0.001+2*0.0015+3*0.011+4*0.02, sum, 1e-9)
+	assert.Equal(t, map[float64]uint64{0.001: 1, 0.01: 3, 0.1: 6}, buckets)
+}
+
+func TestRuntimeHistogramQuantile(t *testing.T) {
+	bounds := []float64{math.Inf(-1), 0.001, 0.002, 0.02, math.Inf(1)}
+	_, ok := runtimeHistogramQuantile(0.5, []uint64{0, 0, 0, 0}, bounds)
+	assert.False(t, ok)
+
+	counts := []uint64{1, 2, 3, 4}
+	for q, want := range map[float64]float64{0.1: 0.001, 0.3: 0.002, 0.5: 0.02, 1: 0.02} {
+		v, ok := runtimeHistogramQuantile(q, counts, bounds)
+		assert.True(t, ok)
+		assert.Equal(t, want, v, q)
+	}
+	assert.Equal(t, []uint64{1, 0, 1, 4}, deltaCounts(counts, []uint64{0, 2, 2, 0}))
+	assert.Equal(t, counts, deltaCounts(counts, nil))
+}
@@ -17,13 +17,17 @@ package metric
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
 
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/admin/auth"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/metric/internal/registry"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/remote"
@@ -55,21 +59,37 @@ func SetupByConfig(cfg Config) error {
 	}
 	registerRPCServerCounter()
 	registerRPCClientCounter()
-	registerRPCHandledHistograms()
-	enableClientStreamHistograms()
+	registerRPCHandledHistograms(cfg)
+	registerRPCInFlightGauges()
+	if cfg.EnabledPayloadSizeHistogram {
+		registerRPCPayloadSizeHistograms(cfg)
+	}
+	if cfg.PeerMetrics.Enabled {
+		registerPeerMetrics(cfg.PeerMetrics)
+	}
+	if cfg.RuntimeMetrics.Enabled {
+		registerRuntimeMetrics(cfg.RuntimeMetrics)
+	}
+	enableClientStreamHistograms(WithHistogramNative(cfg.NativeHistogram))
 	if cfg.ServerOwner != "" {
 		serverMetadata.WithLabelValues(cfg.ServerOwner, cfg.CmdbID).Set(1)
 	}
 	if cfg.Configurator != nil {
 		cfg.Configurator.RegisterConfigApplyFunc(genConfigApplyFunc(cfg))
+		registerRemoteStatus(cfg.Configurator)
 	}
-	// Etcd registration
-	if cfg.EnabledRegister {
-		if len(cfg.RegistryEndpoints) == 0 {
-			return errors.New("metric: registry endpoints nil")
+	// prometheus remote write, independent of the registration
+	if cfg.RemoteWrite.Enabled {
+		if cfg.RemoteWrite.URL == "" {
+			return errors.New("metric: remote write url nil")
 		}
-		if cfg.Instance.TenantID == "" {
-			return errors.New("metric: tenant id nil")
+		startRemoteWrite(newRemoteWriter(cfg.RemoteWrite, cfg.Instance, limitGatherer()))
+	}
+	// Etcd, file_sd or http_sd registration
+	if cfg.EnabledRegister {
+		reg, err := newRegistry(cfg)
+		if err != nil {
+			return err
 		}
 		if cfg.Instance.Addr == "" {
 			return errors.New("metric: exporter addr nil")
@@ -77,12 +97,14 @@ func SetupByConfig(cfg Config) error {
 		if cfg.TTL == 0 {
 			cfg.TTL = DefaultRegisterTTL
 		}
-		opts := []registry.EtcdOption{
-			registry.WithTLS(newTLSConfig(cfg.TLSCert)),
+		// remove the registration of the previous Setup first, it has the same key as this one
+		Deregister()
+		cancel, err := reg.Register(context.Background(), &cfg.Instance, cfg.TTL)
+		if err != nil {
+			return err
 		}
-		reg := NewEtcdRegistry(cfg.RegistryEndpoints, cfg.Instance.TenantID, opts...)
-		_, err := reg.Register(context.Background(), &cfg.Instance, cfg.TTL)
-		return err
+		setRegistration(reg, cancel)
+		return nil
 	}
 	// prometheus push
 	if cfg.PrometheusPush.Enabled {
@@ -120,6 +142,35 @@ func SetupByConfig(cfg Config) error {
 	return nil
 }
 
+// newRegistry returns the registry of cfg.Registry
+func newRegistry(cfg Config) (Registry, error) {
+	switch cfg.Registry {
+	case "", RegistryEtcd:
+		if len(cfg.RegistryEndpoints) == 0 {
+			return nil, errors.New("metric: registry endpoints nil")
+		}
+		if cfg.Instance.TenantID == "" {
+			return nil, errors.New("metric: tenant id nil")
+		}
+		opts := []registry.EtcdOption{
+			registry.WithTLS(newTLSConfig(cfg.TLSCert)),
+		}
+		return NewEtcdRegistry(cfg.RegistryEndpoints, cfg.Instance.TenantID, opts...), nil
+	case RegistryFileSD:
+		if cfg.FileSD.Path == "" {
+			return nil, errors.New("metric: file_sd path nil")
+		}
+		return NewFileRegistry(cfg.FileSD.Path), nil
+	case RegistryHTTPSD:
+		if cfg.HTTPSD.URL == "" {
+			return nil, errors.New("metric: http_sd url nil")
+		}
+		return NewHTTPRegistry(cfg.HTTPSD.URL, registry.WithHTTPHeaders(cfg.HTTPSD.HTTPHeaders)), nil
+	default:
+		return nil, fmt.Errorf("metric: unknown registry %q", cfg.Registry)
+	}
+}
+
 // DeletePrometheusPush send delete request to prometheus push gateway
 func DeletePrometheusPush() error {
 	if defaultPusher == nil {
@@ -185,6 +236,27 @@ func WithTTL(ttl time.Duration) SetupOption {
 	}
 }
 
+// WithRegistry set registry of EnabledRegister: etcd (default), file_sd or http_sd
+func WithRegistry(registry string) SetupOption {
+	return func(config *Config) {
+		config.Registry = registry
+	}
+}
+
+// WithFileSD set file_sd registry config
+func WithFileSD(cfg FileSDConfig) SetupOption {
+	return func(config *Config) {
+		config.FileSD = cfg
+	}
+}
+
+// WithHTTPSD set http_sd registry config
+func WithHTTPSD(cfg HTTPSDConfig) SetupOption {
+	return func(config *Config) {
+		config.HTTPSD = cfg
+	}
+}
+
 // WithRegistryEndpoints registry endpoints addr
 func WithRegistryEndpoints(endpoints []string) SetupOption {
 	return func(config *Config) {
@@ -213,6 +285,76 @@ func WithServerHistogramBuckets(buckets []float64) SetupOption {
 	}
 }
 
+// WithClientHistogramBucketsOverrides set client histogram buckets by callee service/method
+func WithClientHistogramBucketsOverrides(overrides []HistogramBucketsOverride) SetupOption {
+	return func(config *Config) {
+		config.ClientHistogramBucketsOverrides = overrides
+	}
+}
+
+// WithServerHistogramBucketsOverrides set server histogram buckets by callee service/method
+func WithServerHistogramBucketsOverrides(overrides []HistogramBucketsOverride) SetupOption {
+	return func(config *Config) {
+		config.ServerHistogramBucketsOverrides = overrides
+	}
+}
+
+// WithEnabledPayloadSizeHistogram enable payload size histograms
+func WithEnabledPayloadSizeHistogram(enabled bool) SetupOption {
+	return func(config *Config) {
+		config.EnabledPayloadSizeHistogram = enabled
+	}
+}
+
+// WithPeerMetrics set per-peer client metrics config
+func WithPeerMetrics(cfg PeerMetricsConfig) SetupOption {
+	return func(config *Config) {
+		config.PeerMetrics = cfg
+	}
+}
+
+// WithRuntimeMetrics set Go runtime/metrics collector config
+func WithRuntimeMetrics(cfg RuntimeMetricsConfig) SetupOption {
+	return func(config *Config) {
+		config.RuntimeMetrics = cfg
+	}
+}
+
+// WithRemoteWrite set prometheus remote-write config
+func WithRemoteWrite(cfg RemoteWriteConfig) SetupOption {
+	return func(config *Config) {
+		config.RemoteWrite = cfg
+	}
+}
+
+// WithAdminAuth set TLS, authentication, path allowlist and POST-only mutations of the admin endpoints
+func WithAdminAuth(cfg auth.Config) SetupOption {
+	return func(config *Config) {
+		config.AdminAuth = cfg
+	}
+}
+
+// WithSinks set sinks of the trpc metrics API: prometheus (default) and otlp
+func WithSinks(sinks []string) SetupOption {
+	return func(config *Config) {
+		config.Sinks = sinks
+	}
+}
+
+// WithSinkHistogramBuckets set histogram buckets of the trpc metrics API by metric name
+func WithSinkHistogramBuckets(buckets map[string][]float64) SetupOption {
+	return func(config *Config) {
+		config.SinkHistogramBuckets = buckets
+	}
+}
+
+// WithNativeHistogram set prometheus native histogram config
+func WithNativeHistogram(cfg NativeHistogramConfig) SetupOption {
+	return func(config *Config) {
+		config.NativeHistogram = cfg
+	}
+}
+
 // WithTSLCert set TSLCert
 func WithTSLCert(cert TLSCert) SetupOption {
 	return func(config *Config) {
@@ -227,6 +369,29 @@ func WithConfigurator(configurator remote.Configurator) SetupOption {
 	}
 }
 
+var (
+	remoteStatusMu sync.Mutex
+	// remoteStatusCollector exports the apply status of the configurator of the last Setup
+	remoteStatusCollector prometheus.Collector
+)
+
+// registerRemoteStatus exports the apply status of the configurator, replacing the previous one.
+func registerRemoteStatus(c remote.Configurator) {
+	r, ok := c.(remote.StatusReporter)
+	if !ok {
+		return
+	}
+	remoteStatusMu.Lock()
+	defer remoteStatusMu.Unlock()
+	if remoteStatusCollector != nil {
+		prometheus.Unregister(remoteStatusCollector)
+	}
+	remoteStatusCollector = remote.NewStatusCollector(r)
+	if err := prometheus.Register(remoteStatusCollector); err != nil {
+		log.Printf("opentelemetry: register remote status metrics err:%v", err)
+	}
+}
+
 func genConfigApplyFunc(cfg Config) remote.ConfigApplyFunc {
 	return func(config *operation.Operation) error {
 		// update sever owners
@@ -32,6 +32,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 
+	opentelemetry "trpc.group/trpc-go/trpc-opentelemetry"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
 	"trpc.group/trpc-go/trpc-opentelemetry/sdk/remote"
 )
@@ -436,3 +437,86 @@ func TestPrometheusPush(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRegistry(t *testing.T) {
+	for _, cfg := range []Config{
+		{Registry: RegistryEtcd},
+		{Registry: RegistryEtcd, RegistryEndpoints: []string{"127.0.0.1:2379"}},
+		{Registry: RegistryFileSD},
+		{Registry: RegistryHTTPSD},
+		{Registry: "unknown"},
+	} {
+		_, err := newRegistry(cfg)
+		assert.Error(t, err, cfg.Registry)
+	}
+
+	path := t.TempDir() + "/targets.json"
+	err := SetupByConfig(Config{
+		Enabled:         true,
+		EnabledRegister: true,
+		Registry:        RegistryFileSD,
+		FileSD:          FileSDConfig{Path: path},
+		Instance:        Instance{TenantID: "default", Addr: "127.0.0.1:9999"},
+	})
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+	}()
+	assert.NoError(t, err)
+	_, err = os.Stat(path)
+	assert.NoError(t, err)
+
+	statuses := Registrations()
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, RegistryFileSD, statuses[0].Registry)
+	rec := httptest.NewRecorder()
+	RegistrationHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/registry", nil))
+	assert.Contains(t, rec.Body.String(), `"registered": true`)
+	reg := prometheus.NewRegistry()
+	assert.NoError(t, reg.Register(registrationCollector{}))
+	mfs, err := reg.Gather()
+	assert.NoError(t, err)
+	assert.Len(t, mfs, 3)
+
+	// deregistered by opentelemetry.Shutdown
+	assert.NoError(t, opentelemetry.Shutdown(context.Background()))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, Registrations())
+}
+
+func TestSetupByConfig_RegisterTwice(t *testing.T) {
+	defer func() {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		prometheus.DefaultGatherer = prometheus.NewRegistry()
+	}()
+	dir := t.TempDir()
+	setup := func(path string) {
+		prometheus.DefaultRegisterer = prometheus.NewRegistry()
+		assert.NoError(t, SetupByConfig(Config{
+			Enabled:         true,
+			EnabledRegister: true,
+			Registry:        RegistryFileSD,
+			FileSD:          FileSDConfig{Path: path},
+			Instance:        Instance{TenantID: "default", Addr: "127.0.0.1:9999"},
+		}))
+	}
+	first, second := dir+"/first.json", dir+"/second.json"
+	setup(first)
+	setup(second)
+	// the registration of the first Setup is removed
+	_, err := os.Stat(first)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(second)
+	assert.NoError(t, err)
+
+	// the same registration again is kept
+	setup(second)
+	_, err = os.Stat(second)
+	assert.NoError(t, err)
+	assert.Len(t, Registrations(), 1)
+
+	assert.NoError(t, opentelemetry.Shutdown(context.Background()))
+	_, err = os.Stat(second)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -19,7 +19,6 @@ import (
 	"log"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	"google.golang.org/grpc"
@@ -32,44 +31,57 @@ import (
 // ConfigApplyFunc ...
 type ConfigApplyFunc func(config *operation.Operation) error
 
-// Configurator called when config changed
+// Configurator called when config changed, the configurators of this package also implement Validator
+// and StatusReporter.
 type Configurator interface {
 	RegisterConfigApplyFunc(fn ConfigApplyFunc)
 }
 
+// isDebugEnabled returns if debug is enabled by env OTEL_TRACE=remote
+func isDebugEnabled() bool {
+	if otelTraceEnv := os.Getenv("OTEL_TRACE"); strings.Contains(otelTraceEnv, "remote") {
+		log.Printf("opentelemetry: env OTEL_TRACE:%s", otelTraceEnv)
+		return true
+	}
+	return false
+}
+
 type remoteConfigurator struct {
+	registry
+
 	remoteServiceAddr string
 	syncInterval      time.Duration
 	tenantID          string
 	app               string
 	server            string
-	debug             bool
+	reportStatus      bool
+	instance          string
 
-	client              operation.OperationServiceClient
-	lastConfig          *operation.Operation
-	configApplyFuncList []ConfigApplyFunc
-	// mu Protect lastConfig/configApplyFuncList.
-	mu sync.Mutex
+	client operation.OperationServiceClient
 }
 
 // NewRemoteConfigurator create a new remoteConfigurator
 func NewRemoteConfigurator(remoteServiceAddr string, syncInterval time.Duration,
-	tenantID, app, server string) Configurator {
+	tenantID, app, server string, opts ...Option) Configurator {
 	if syncInterval == 0 {
 		syncInterval = time.Minute
 	}
+	o := newOptions(opts)
 	rc := &remoteConfigurator{
 		remoteServiceAddr: remoteServiceAddr,
 		syncInterval:      syncInterval,
 		tenantID:          tenantID,
 		app:               app,
 		server:            server,
+		reportStatus:      o.reportStatus,
+		instance:          o.instance,
 	}
-	// export OTEL_TRACE=remote
-	if otelTraceEnv := os.Getenv("OTEL_TRACE"); strings.Contains(otelTraceEnv, "remote") {
-		log.Printf("opentelemetry: env OTEL_TRACE:%s", otelTraceEnv)
-		rc.debug = true
+	rc.historySize = o.historySize
+	if rc.instance == "" {
+		rc.instance, _ = os.Hostname()
 	}
+	// export OTEL_TRACE=remote
+	rc.debug = isDebugEnabled()
 	if rc.remoteServiceAddr != "" {
 		go rc.syncDaemon()
 	}
@@ -114,27 +126,30 @@ func (rc *remoteConfigurator) sync() {
 	if rc.debug {
 		log.Printf("opentelemetry: remote GetOperation result:%+v", rsp)
 	}
-	rc.mu.Lock()
-	defer rc.mu.Unlock()
-	rc.lastConfig = rsp.GetOperation()
-	for _, v := range rc.configApplyFuncList {
-		if err = v(rsp.GetOperation()); err != nil {
-			if rc.debug {
-				log.Printf("opentelemetry: remote apply err:%v", err)
-			}
-		}
+	if rec, ok := rc.apply(rsp.GetOperation()); ok && rc.reportStatus {
+		rc.report(rec)
 	}
 }
 
-// RegisterConfigApplyFunc register config change handler
-func (rc *remoteConfigurator) RegisterConfigApplyFunc(fn ConfigApplyFunc) {
-	rc.mu.Lock()
-	defer rc.mu.Unlock()
-	rc.configApplyFuncList = append(rc.configApplyFuncList, fn)
-	// Apply on register for async setup.
-	if rc.lastConfig != nil {
-		if err := fn(rc.lastConfig); err != nil && rc.debug {
-			log.Printf("opentelemetry: remote apply err:%v", err)
-		}
+// report reports the apply status by OperationService.ReportApplyStatus.
+func (rc *remoteConfigurator) report(rec Record) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(map[string]string{
+		"x-tps-tenantid": rc.tenantID,
+	}))
+	req := &operation.ReportApplyStatusRequest{
+		Tenant:         rc.tenantID,
+		App:            rc.app,
+		Server:         rc.server,
+		Instance:       rc.instance,
+		Version:        rec.Version,
+		Success:        rec.Result == ResultApplied,
+		Error:          rec.Error,
+		AppliedVersion: rc.Status().Version,
+		Timestamp:      rec.Time.Unix(),
+	}
+	if _, err := rc.client.ReportApplyStatus(ctx, req); err != nil && rc.debug {
+		log.Printf("opentelemetry: remote ReportApplyStatus err:%v", err)
 	}
 }
@@ -0,0 +1,106 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// recorder records the applied operations.
+func recorder(c Configurator) chan *operation.Operation {
+	ch := make(chan *operation.Operation, 16)
+	c.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		ch <- op
+		return nil
+	})
+	return ch
+}
+
+func receive(t *testing.T, ch chan *operation.Operation) *operation.Operation {
+	select {
+	case op := <-ch:
+		return op
+	case <-time.After(5 * time.Second):
+		t.Fatal("operation is not applied")
+		return nil
+	}
+}
+
+func TestFileConfigurator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "operation.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("sampler:\n  fraction: 0.5\n"), 0644))
+	c := NewFileConfigurator(path, 10*time.Millisecond)
+	// applied on setup, and to the funcs registered later
+	ch := recorder(c)
+	assert.Equal(t, 0.5, receive(t, ch).GetSampler().GetFraction())
+
+	// invalid content is not applied
+	require.NoError(t, os.WriteFile(path, []byte("sampler: [\n"), 0644))
+	require.NoError(t, os.WriteFile(path+".json", []byte(`{"sampler":{"fraction":0.1}}`), 0644))
+	require.NoError(t, os.Rename(path+".json", path))
+	assert.Equal(t, 0.1, receive(t, ch).GetSampler().GetFraction())
+
+	// unchanged content is applied only once
+	time.Sleep(50 * time.Millisecond)
+	assert.Empty(t, ch)
+}
+
+func TestHTTPConfigurator(t *testing.T) {
+	var requests, notModified int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		assert.Equal(t, "/api/operation/tenant/tenant/app/app/server/server", r.URL.Path)
+		assert.Equal(t, "tenant", r.Header.Get("x-tps-tenantid"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"operation":{"sampler":{"fraction":0.5}},"unknown":1}`))
+	}))
+	defer srv.Close()
+
+	c := NewHTTPConfigurator(srv.URL, 10*time.Millisecond, "tenant", "app", "server")
+	ch := recorder(c)
+	assert.Equal(t, 0.5, receive(t, ch).GetSampler().GetFraction())
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&notModified) >= 2 }, 5*time.Second,
+		10*time.Millisecond)
+	assert.Empty(t, ch)
+	assert.Equal(t, atomic.LoadInt32(&requests)-1, atomic.LoadInt32(&notModified))
+}
+
+func TestParseOperation(t *testing.T) {
+	op, err := parseOperation([]byte("resource:\n  app: app\nsampler:\n  fraction: 0.5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "app", op.GetResource().GetApp())
+	op, err = parseOperation([]byte(` {"resource":{"app":"app"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "app", op.GetResource().GetApp())
+	_, err = parseOperation([]byte("sampler: [\n"))
+	assert.Error(t, err)
+	_, err = parseOperation([]byte("sampler:\n  fraction: x\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,124 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"gopkg.in/yaml.v3"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+const (
+	// TypeGRPC configurator of the grpc OperationService
+	TypeGRPC = "grpc"
+	// TypeHTTP configurator of the grpc-gateway HTTP endpoint of OperationService
+	TypeHTTP = "http"
+	// TypeFile configurator of a local Operation file
+	TypeFile = "file"
+	// TypeNone configurator which never applies
+	TypeNone = "none"
+)
+
+const defaultFileSyncInterval = 10 * time.Second
+
+type fileConfigurator struct {
+	registry
+
+	path         string
+	syncInterval time.Duration
+	// sum checksum of the last read content
+	sum [sha256.Size]byte
+}
+
+// NewFileConfigurator create a Configurator which applies the Operation in the YAML or protobuf JSON file,
+// the file is checked every syncInterval (default 10s) and applied when its content changes,
+// so that it works with the files replaced by rename or symlink swap, e.g. the kubernetes ConfigMap.
+func NewFileConfigurator(path string, syncInterval time.Duration, opts ...Option) Configurator {
+	if syncInterval == 0 {
+		syncInterval = defaultFileSyncInterval
+	}
+	fc := &fileConfigurator{
+		path:         path,
+		syncInterval: syncInterval,
+	}
+	fc.historySize = newOptions(opts).historySize
+	// export OTEL_TRACE=remote
+	fc.debug = isDebugEnabled()
+	if fc.path != "" {
+		// The first sync is synchronous so that the file is applied on setup.
+		fc.sync()
+		go fc.syncDaemon()
+	}
+	return fc
+}
+
+func (fc *fileConfigurator) syncDaemon() {
+	for {
+		time.Sleep(fc.syncInterval)
+		fc.sync()
+	}
+}
+
+func (fc *fileConfigurator) sync() {
+	data, err := os.ReadFile(filepath.Clean(fc.path))
+	if err != nil {
+		if fc.debug {
+			log.Printf("opentelemetry: remote read file err:%v", err)
+		}
+		return
+	}
+	sum := sha256.Sum256(data)
+	if sum == fc.sum {
+		return
+	}
+	fc.sum = sum
+	op, err := parseOperation(data)
+	if err != nil {
+		fc.reject(fmt.Errorf("parse file %s: %w", fc.path, err))
+		return
+	}
+	if fc.debug {
+		log.Printf("opentelemetry: remote file %s result:%+v", fc.path, op)
+	}
+	fc.apply(op)
+}
+
+// parseOperation parses an Operation in the protobuf JSON format or YAML.
+func parseOperation(data []byte) (*operation.Operation, error) {
+	if !json.Valid(bytes.TrimSpace(data)) {
+		var v interface{}
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("unmarshal yaml err: %w", err)
+		}
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, fmt.Errorf("convert yaml to json err: %w", err)
+		}
+	}
+	op := &operation.Operation{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
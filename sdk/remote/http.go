@@ -0,0 +1,114 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+type httpConfigurator struct {
+	registry
+
+	url          string
+	syncInterval time.Duration
+	tenantID     string
+	client       *http.Client
+	// etag ETag of the last applied response
+	etag string
+}
+
+// NewHTTPConfigurator create a Configurator which polls the grpc-gateway HTTP endpoint of OperationService,
+// e.g. http://127.0.0.1:12521, every syncInterval (default 1m). The response is applied only if it is modified,
+// by sending the ETag of the last response as If-None-Match.
+func NewHTTPConfigurator(endpoint string, syncInterval time.Duration,
+	tenantID, app, server string, opts ...Option) Configurator {
+	if syncInterval == 0 {
+		syncInterval = time.Minute
+	}
+	hc := &httpConfigurator{
+		syncInterval: syncInterval,
+		tenantID:     tenantID,
+		client:       &http.Client{Timeout: 5 * time.Second},
+	}
+	hc.historySize = newOptions(opts).historySize
+	// export OTEL_TRACE=remote
+	hc.debug = isDebugEnabled()
+	if endpoint != "" {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		hc.url = fmt.Sprintf("%s/api/operation/tenant/%s/app/%s/server/%s", strings.TrimSuffix(endpoint, "/"),
+			url.PathEscape(tenantID), url.PathEscape(app), url.PathEscape(server))
+		go hc.syncDaemon()
+	}
+	return hc
+}
+
+func (hc *httpConfigurator) syncDaemon() {
+	for {
+		if err := hc.sync(); err != nil && hc.debug {
+			log.Printf("opentelemetry: remote http GetOperation err:%v", err)
+		}
+		time.Sleep(hc.syncInterval)
+	}
+}
+
+func (hc *httpConfigurator) sync() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, hc.url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("x-tps-tenantid", hc.tenantID)
+	if hc.etag != "" {
+		req.Header.Set("If-None-Match", hc.etag)
+	}
+	rsp, err := hc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer rsp.Body.Close()
+	if rsp.StatusCode == http.StatusNotModified {
+		return nil
+	}
+	data, err := io.ReadAll(rsp.Body)
+	if err != nil {
+		return err
+	}
+	if rsp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", rsp.Status, data)
+	}
+	out := &operation.GetOperationResponse{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, out); err != nil {
+		return err
+	}
+	if hc.debug {
+		log.Printf("opentelemetry: remote http GetOperation result:%+v", out)
+	}
+	hc.etag = rsp.Header.Get("ETag")
+	hc.apply(out.GetOperation())
+	return nil
+}
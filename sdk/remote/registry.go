@@ -0,0 +1,275 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+// DefaultHistorySize default number of the configs kept in the history
+const DefaultHistorySize = 10
+
+const (
+	// ResultApplied the config is applied by all ConfigApplyFuncs
+	ResultApplied = "applied"
+	// ResultInvalid the config fails the validation and is not applied
+	ResultInvalid = "invalid"
+	// ResultRolledBack a ConfigApplyFunc fails and the last good config is applied again
+	ResultRolledBack = "rolled_back"
+	// ResultSkipped the config is unchanged, it is only counted and not kept in the history
+	ResultSkipped = "skipped"
+)
+
+// ConfigValidateFunc validates the config before it is applied, the config is rejected if it returns an error.
+type ConfigValidateFunc func(config *operation.Operation) error
+
+// Validator is implemented by the configurators of this package.
+type Validator interface {
+	RegisterConfigValidateFunc(fn ConfigValidateFunc)
+}
+
+// Option configurator option
+type Option func(*options)
+
+type options struct {
+	historySize  int
+	reportStatus bool
+	instance     string
+}
+
+// WithHistorySize sets the number of the configs kept in the history, default DefaultHistorySize.
+func WithHistorySize(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.historySize = n
+		}
+	}
+}
+
+// WithReportStatus reports the apply status by OperationService.ReportApplyStatus, grpc configurator only.
+func WithReportStatus(enabled bool) Option {
+	return func(o *options) {
+		o.reportStatus = enabled
+	}
+}
+
+// WithInstance sets the instance of the reported apply status, default hostname.
+func WithInstance(instance string) Option {
+	return func(o *options) {
+		o.instance = instance
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{historySize: DefaultHistorySize}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Record a config in the history
+type Record struct {
+	// Version of the config
+	Version string `json:"version"`
+	// Time of the apply
+	Time time.Time `json:"time"`
+	// Result ResultApplied, ResultInvalid or ResultRolledBack
+	Result string `json:"result"`
+	// Error of the validation or the apply
+	Error string `json:"error,omitempty"`
+	// Config is nil if it can not be parsed
+	Config *operation.Operation `json:"-"`
+}
+
+// registry is the ConfigApplyFunc registry shared by the configurators.
+// A config is validated and applied to all funcs, if any func fails, the last good config is applied again.
+// Configs of the same version as the last one, or equal to it if the version is empty, are skipped.
+type registry struct {
+	debug       bool
+	historySize int
+
+	// lastConfig the last good config, applied to the funcs registered later.
+	lastConfig *operation.Operation
+	// lastAttempt the last config applied or rejected.
+	lastAttempt            *operation.Operation
+	attempted              bool
+	configApplyFuncList    []ConfigApplyFunc
+	configValidateFuncList []ConfigValidateFunc
+	status                 Status
+	// mu Protect all above.
+	mu sync.Mutex
+}
+
+// RegisterConfigApplyFunc register config change handler
+func (r *registry) RegisterConfigApplyFunc(fn ConfigApplyFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configApplyFuncList = append(r.configApplyFuncList, fn)
+	// Apply on register for async setup.
+	if r.lastConfig != nil {
+		if err := fn(r.lastConfig); err != nil {
+			log.Printf("opentelemetry: remote apply version %q err:%v", r.lastConfig.GetVersion(), err)
+		}
+	}
+}
+
+// RegisterConfigValidateFunc register config validator, Validate is always called.
+func (r *registry) RegisterConfigValidateFunc(fn ConfigValidateFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.configValidateFuncList = append(r.configValidateFuncList, fn)
+}
+
+// Status returns the apply status.
+func (r *registry) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.status
+	s.History = append([]Record(nil), r.status.History...)
+	return s
+}
+
+// unchanged returns if config is the same as the last attempt.
+func (r *registry) unchanged(config *operation.Operation) bool {
+	if !r.attempted {
+		return false
+	}
+	if config.GetVersion() != "" {
+		return config.GetVersion() == r.lastAttempt.GetVersion()
+	}
+	return proto.Equal(config, r.lastAttempt)
+}
+
+// apply validates and applies config to all registered funcs, it returns false if config is unchanged.
+func (r *registry) apply(config *operation.Operation) (Record, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unchanged(config) {
+		r.status.Skips++
+		return Record{}, false
+	}
+	r.attempted, r.lastAttempt = true, config
+	rec := Record{Version: config.GetVersion(), Time: time.Now(), Config: config}
+	if err := r.validate(config); err != nil {
+		rec.Result, rec.Error = ResultInvalid, err.Error()
+	} else if err := r.applyAll(config); err != nil {
+		rec.Result, rec.Error = ResultRolledBack, err.Error()
+	} else {
+		rec.Result = ResultApplied
+		r.lastConfig = config
+	}
+	r.record(rec)
+	return rec, true
+}
+
+// reject records a config which can not be parsed.
+func (r *registry) reject(err error) Record {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// The next valid config is always applied.
+	r.attempted, r.lastAttempt = false, nil
+	rec := Record{Time: time.Now(), Result: ResultInvalid, Error: err.Error()}
+	r.record(rec)
+	return rec
+}
+
+// record updates the status with rec, it must be called with mu held.
+func (r *registry) record(rec Record) {
+	if rec.Result == ResultApplied {
+		r.status.Applies++
+		r.status.Version, r.status.AppliedAt = rec.Version, rec.Time
+		if r.debug {
+			log.Printf("opentelemetry: remote applied version %q", rec.Version)
+		}
+	} else {
+		if rec.Result == ResultInvalid {
+			r.status.Invalids++
+		} else {
+			r.status.RollBacks++
+		}
+		r.status.LastError, r.status.LastErrorAt = rec.Error, rec.Time
+		log.Printf("opentelemetry: remote %s version %q err:%s", rec.Result, rec.Version, rec.Error)
+	}
+	historySize := r.historySize
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	r.status.History = append(r.status.History, rec)
+	if n := len(r.status.History); n > historySize {
+		r.status.History = append([]Record(nil), r.status.History[n-historySize:]...)
+	}
+}
+
+// validate validates config by Validate and the registered funcs, it must be called with mu held.
+func (r *registry) validate(config *operation.Operation) error {
+	if err := Validate(config); err != nil {
+		return err
+	}
+	for _, fn := range r.configValidateFuncList {
+		if err := fn(config); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyAll applies config to all funcs, if any func fails, the last good config is applied to the funcs
+// applied so far, including the failed one. It must be called with mu held.
+func (r *registry) applyAll(config *operation.Operation) error {
+	for i, fn := range r.configApplyFuncList {
+		if err := fn(config); err != nil {
+			for _, applied := range r.configApplyFuncList[:i+1] {
+				if rbErr := applied(r.lastConfig); rbErr != nil {
+					log.Printf("opentelemetry: remote rollback to version %q err:%v", r.lastConfig.GetVersion(), rbErr)
+				}
+			}
+			return fmt.Errorf("apply func %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates the built-in fields of config: the sampler fraction, the code ranges and regexes.
+func Validate(config *operation.Operation) error {
+	if f := config.GetSampler().GetFraction(); f < 0 || f > 1 {
+		return fmt.Errorf("sampler fraction %g is out of [0, 1]", f)
+	}
+	var errs []string
+	for i, c := range config.GetMetric().GetCodes() {
+		if c.GetCodeMin() > c.GetCodeMax() {
+			errs = append(errs, fmt.Sprintf("metric codes[%d]: code_min %d > code_max %d", i,
+				c.GetCodeMin(), c.GetCodeMax()))
+		}
+		for _, re := range []string{c.GetServiceRegex(), c.GetMethodRegex()} {
+			if _, err := regexp.Compile(re); err != nil {
+				errs = append(errs, fmt.Sprintf("metric codes[%d]: %v", i, err))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/protocol/opentelemetry-ext/proto/operation"
+)
+
+func version(v string, fraction float64) *operation.Operation {
+	return &operation.Operation{Version: v, Sampler: &operation.Sampler{Fraction: fraction}}
+}
+
+func TestRegistry_SkipUnchanged(t *testing.T) {
+	r := &registry{}
+	var applied []string
+	r.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		applied = append(applied, op.GetVersion())
+		return nil
+	})
+	_, ok := r.apply(version("v1", 0.1))
+	assert.True(t, ok)
+	_, ok = r.apply(version("v1", 0.2))
+	assert.False(t, ok, "same version is skipped")
+	_, ok = r.apply(version("v2", 0.2))
+	assert.True(t, ok)
+	_, ok = r.apply(version("", 0.3))
+	assert.True(t, ok)
+	_, ok = r.apply(version("", 0.3))
+	assert.False(t, ok, "equal config without version is skipped")
+	assert.Equal(t, []string{"v1", "v2", ""}, applied)
+
+	s := r.Status()
+	assert.Equal(t, int64(3), s.Applies)
+	assert.Equal(t, int64(2), s.Skips)
+	assert.Zero(t, s.Invalids)
+	assert.Zero(t, s.RollBacks)
+}
+
+func TestRegistry_ValidateAndRollback(t *testing.T) {
+	r := &registry{}
+	var first, second []float64
+	r.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		first = append(first, op.GetSampler().GetFraction())
+		return nil
+	})
+	r.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		if op.GetVersion() == "bad" {
+			return errors.New("apply failed")
+		}
+		second = append(second, op.GetSampler().GetFraction())
+		return nil
+	})
+	r.RegisterConfigValidateFunc(func(op *operation.Operation) error {
+		if op.GetVersion() == "rejected" {
+			return errors.New("rejected by validator")
+		}
+		return nil
+	})
+
+	rec, ok := r.apply(version("v1", 0.1))
+	require.True(t, ok)
+	assert.Equal(t, ResultApplied, rec.Result)
+
+	rec, _ = r.apply(version("invalid", 2))
+	assert.Equal(t, ResultInvalid, rec.Result)
+	assert.Contains(t, rec.Error, "out of [0, 1]")
+	rec, _ = r.apply(version("rejected", 0.2))
+	assert.Equal(t, ResultInvalid, rec.Result)
+	assert.Equal(t, "rejected by validator", rec.Error)
+
+	rec, _ = r.apply(version("bad", 0.3))
+	assert.Equal(t, ResultRolledBack, rec.Result)
+	assert.Equal(t, "apply func 1: apply failed", rec.Error)
+	// the first func is rolled back to v1, the second func is never applied with bad
+	assert.Equal(t, []float64{0.1, 0.3, 0.1}, first)
+	assert.Equal(t, []float64{0.1, 0.1}, second)
+
+	// failed version is not retried
+	_, ok = r.apply(version("bad", 0.3))
+	assert.False(t, ok)
+
+	// the last good config is applied to the funcs registered later
+	var late []string
+	r.RegisterConfigApplyFunc(func(op *operation.Operation) error {
+		late = append(late, op.GetVersion())
+		return nil
+	})
+	assert.Equal(t, []string{"v1"}, late)
+
+	s := r.Status()
+	assert.Equal(t, "v1", s.Version)
+	assert.Equal(t, int64(1), s.Applies)
+	assert.Equal(t, int64(2), s.Invalids)
+	assert.Equal(t, int64(1), s.RollBacks)
+	assert.Equal(t, "apply func 1: apply failed", s.LastError)
+	var results []string
+	for _, h := range s.History {
+		results = append(results, h.Version+":"+h.Result)
+	}
+	assert.Equal(t, []string{"v1:applied", "invalid:invalid", "rejected:invalid", "bad:rolled_back"}, results)
+}
+
+func TestRegistry_History(t *testing.T) {
+	r := &registry{historySize: 2}
+	for _, v := range []string{"v1", "v2", "v3"} {
+		r.apply(version(v, 0.1))
+	}
+	r.reject(errors.New("parse err"))
+	s := r.Status()
+	require.Len(t, s.History, 2)
+	assert.Equal(t, "v3", s.History[0].Version)
+	assert.Equal(t, ResultInvalid, s.History[1].Result)
+	assert.Equal(t, "v3", s.Version)
+	// the next config is applied after a rejected one
+	_, ok := r.apply(version("v3", 0.1))
+	assert.True(t, ok)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(nil))
+	assert.NoError(t, Validate(&operation.Operation{Metric: &operation.Metric{Codes: []*operation.Code{
+		{CodeMin: 1, CodeMax: 2, ServiceRegex: "^a.*"},
+	}}}))
+	err := Validate(&operation.Operation{Metric: &operation.Metric{Codes: []*operation.Code{
+		{CodeMin: 3, CodeMax: 2},
+		{MethodRegex: "("},
+	}}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "metric codes[0]: code_min 3 > code_max 2")
+	assert.Contains(t, err.Error(), "metric codes[1]: error parsing regexp")
+}
+
+func TestStatusHandler(t *testing.T) {
+	r := &registry{}
+	r.apply(version("v1", 0.1))
+	r.apply(version("v2", 2))
+
+	w := httptest.NewRecorder()
+	StatusHandler(r).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/remote", nil))
+	var s struct {
+		Version   string `json:"version"`
+		LastError string `json:"last_error"`
+		History   []struct {
+			Version string                 `json:"version"`
+			Result  string                 `json:"result"`
+			Config  map[string]interface{} `json:"config"`
+		} `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+	assert.Equal(t, "v1", s.Version)
+	assert.Contains(t, s.LastError, "sampler fraction 2")
+	require.Len(t, s.History, 2)
+	assert.Equal(t, "v2", s.History[1].Config["version"])
+	assert.Equal(t, ResultInvalid, s.History[1].Result)
+
+	w = httptest.NewRecorder()
+	StatusHandler(nil).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/remote", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestStatusCollector(t *testing.T) {
+	r := &registry{}
+	r.apply(version("v1", 0.1))
+	r.apply(version("v1", 0.1))
+	r.apply(version("v2", 2))
+	require.NoError(t, testutil.CollectAndCompare(NewStatusCollector(r), strings.NewReader(`
+# HELP opentelemetry_remote_config_applies_total Total number of remote config applies by result, applied, invalid, rolled_back or skipped.
+# TYPE opentelemetry_remote_config_applies_total counter
+opentelemetry_remote_config_applies_total{result="applied"} 1
+opentelemetry_remote_config_applies_total{result="invalid"} 1
+opentelemetry_remote_config_applies_total{result="rolled_back"} 0
+opentelemetry_remote_config_applies_total{result="skipped"} 1
+# HELP opentelemetry_remote_config_info Version of the applied remote config, the value is always 1.
+# TYPE opentelemetry_remote_config_info gauge
+opentelemetry_remote_config_info{version="v1"} 1
+# HELP opentelemetry_remote_config_last_apply_success Whether the last remote config apply succeeded, 1 means succeeded.
+# TYPE opentelemetry_remote_config_last_apply_success gauge
+opentelemetry_remote_config_last_apply_success 0
+`), "opentelemetry_remote_config_applies_total", "opentelemetry_remote_config_info",
+		"opentelemetry_remote_config_last_apply_success"))
+}
@@ -0,0 +1,141 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package remote
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Status apply status of a configurator
+type Status struct {
+	// Version of the applied config
+	Version string `json:"version"`
+	// AppliedAt time of the last successful apply
+	AppliedAt time.Time `json:"applied_at"`
+	// LastError error of the last failed apply
+	LastError string `json:"last_error,omitempty"`
+	// LastErrorAt time of the last failed apply
+	LastErrorAt time.Time `json:"last_error_at"`
+	// Applies number of the configs of ResultApplied
+	Applies int64 `json:"applies"`
+	// Invalids number of the configs of ResultInvalid
+	Invalids int64 `json:"invalids"`
+	// RollBacks number of the configs of ResultRolledBack
+	RollBacks int64 `json:"roll_backs"`
+	// Skips number of the configs of ResultSkipped
+	Skips int64 `json:"skips"`
+	// History the last configs, oldest first
+	History []Record `json:"history"`
+}
+
+// StatusReporter is implemented by the configurators of this package.
+type StatusReporter interface {
+	Status() Status
+}
+
+// MarshalJSON marshals the config in the protobuf JSON format.
+func (r Record) MarshalJSON() ([]byte, error) {
+	type record Record
+	var config json.RawMessage
+	if r.Config != nil {
+		var err error
+		if config, err = protojson.Marshal(r.Config); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(struct {
+		record
+		Config json.RawMessage `json:"config,omitempty"`
+	}{record: record(r), Config: config})
+}
+
+// StatusHandler http handler of the apply status of c in JSON, it responds 404 if c is not a StatusReporter.
+func StatusHandler(c Configurator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		reporter, ok := c.(StatusReporter)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "configurator does not report status"})
+			return
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(reporter.Status())
+	})
+}
+
+var (
+	configInfoDesc = prometheus.NewDesc("opentelemetry_remote_config_info",
+		"Version of the applied remote config, the value is always 1.",
+		[]string{"version"}, nil)
+	configAppliesDesc = prometheus.NewDesc("opentelemetry_remote_config_applies_total",
+		"Total number of remote config applies by result, applied, invalid, rolled_back or skipped.",
+		[]string{"result"}, nil)
+	configLastSuccessDesc = prometheus.NewDesc("opentelemetry_remote_config_last_apply_success",
+		"Whether the last remote config apply succeeded, 1 means succeeded.",
+		nil, nil)
+	configLastErrorDesc = prometheus.NewDesc("opentelemetry_remote_config_last_error_timestamp_seconds",
+		"Unix time of the last failed remote config apply.",
+		nil, nil)
+)
+
+// statusCollector exports the apply status
+type statusCollector struct {
+	reporter StatusReporter
+}
+
+// NewStatusCollector creates a prometheus.Collector of the apply status of r.
+func NewStatusCollector(r StatusReporter) prometheus.Collector {
+	return statusCollector{reporter: r}
+}
+
+// Describe implements prometheus.Collector
+func (statusCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- configInfoDesc
+	ch <- configAppliesDesc
+	ch <- configLastSuccessDesc
+	ch <- configLastErrorDesc
+}
+
+// Collect implements prometheus.Collector
+func (c statusCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.reporter.Status()
+	if !s.AppliedAt.IsZero() {
+		ch <- prometheus.MustNewConstMetric(configInfoDesc, prometheus.GaugeValue, 1, s.Version)
+	}
+	for result, n := range map[string]int64{
+		ResultApplied:    s.Applies,
+		ResultInvalid:    s.Invalids,
+		ResultRolledBack: s.RollBacks,
+		ResultSkipped:    s.Skips,
+	} {
+		ch <- prometheus.MustNewConstMetric(configAppliesDesc, prometheus.CounterValue, float64(n), result)
+	}
+	success := 1.0
+	if len(s.History) > 0 && s.History[len(s.History)-1].Result != ResultApplied {
+		success = 0
+	}
+	ch <- prometheus.MustNewConstMetric(configLastSuccessDesc, prometheus.GaugeValue, success)
+	var lastError float64
+	if !s.LastErrorAt.IsZero() {
+		lastError = float64(s.LastErrorAt.UnixNano()) / 1e9
+	}
+	ch <- prometheus.MustNewConstMetric(configLastErrorDesc, prometheus.GaugeValue, lastError)
+}
@@ -26,6 +26,7 @@ import (
 
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/debug"
 	"trpc.group/trpc-go/trpc-opentelemetry/pkg/metrics"
+	"trpc.group/trpc-go/trpc-opentelemetry/pkg/telemetrystatus"
 )
 
 // Defaults for BatchSpanProcessorOptions.
@@ -184,6 +185,11 @@ func (bsp *batchSpanProcessor) Shutdown(ctx context.Context) error {
 	return err
 }
 
+// QueueStatus returns the depth of the queue and the totals of the traces.
+func (bsp *batchSpanProcessor) QueueStatus() telemetrystatus.QueueStatus {
+	return telemetrystatus.NewQueueStatus(len(bsp.queue), cap(bsp.queue), "traces")
+}
+
 type forceFlushSpan struct {
 	sdktrace.ReadOnlySpan
 	flushed chan struct{}
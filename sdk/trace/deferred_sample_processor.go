@@ -43,12 +43,18 @@ func NewDeferredSampler(cfg DeferredSampleConfig) DeferredSampler {
 	errorCounter := metrics.DeferredProcessCounter.WithLabelValues("deferred_error", "traces")
 	slowCounter := metrics.DeferredProcessCounter.WithLabelValues("deferred_slow", "traces")
 	unsampledCounter := metrics.DeferredProcessCounter.WithLabelValues("unsampled", "traces")
+	boostCounter := metrics.DeferredProcessCounter.WithLabelValues("deferred_boost", "traces")
 	return func(s sdktrace.ReadOnlySpan) bool {
 		// already sampled
 		if s.SpanContext().IsSampled() {
 			sampledCounter.Inc()
 			return true
 		}
+		if boostDeferredSample(s, s.Status().Code != codes.Ok) {
+			// deferred thresholds of the sampling boost
+			boostCounter.Inc()
+			return true
+		}
 		if cfg.Enabled && cfg.SampleError && s.Status().Code != codes.Ok {
 			// error
 			errorCounter.Inc()
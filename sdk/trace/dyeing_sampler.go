@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"sync/atomic"
 	"time"
 
@@ -216,6 +217,45 @@ func (ws *Sampler) Description() string {
 	return ws.description
 }
 
+// SamplerStatus status of Sampler
+type SamplerStatus struct {
+	Description        string                     `json:"description"`
+	Fraction           float64                    `json:"fraction"`
+	SpecialFractions   map[string]SpecialFraction `json:"special_fractions,omitempty"`
+	SamplerServiceAddr string                     `json:"sampler_service_addr,omitempty"`
+	SyncInterval       string                     `json:"sync_interval,omitempty"`
+	// DyeingRules sampled values by key, synced from the sampler service
+	DyeingRules map[string][]string `json:"dyeing_rules,omitempty"`
+	// Boost the active SamplingBoost
+	Boost *SamplingBoost `json:"boost,omitempty"`
+}
+
+// Status returns the config and the current dyeing rules of Sampler
+func (ws *Sampler) Status() SamplerStatus {
+	s := SamplerStatus{
+		Description:        ws.description,
+		Fraction:           ws.samplerConfig.Fraction,
+		SpecialFractions:   ws.samplerConfig.SpecialFractions,
+		SamplerServiceAddr: ws.samplerConfig.SamplerServiceAddr,
+	}
+	if ws.samplerConfig.SyncInterval > 0 {
+		s.SyncInterval = ws.samplerConfig.SyncInterval.String()
+	}
+	if sampledKvs, ok := ws.sampledKvs.Load().(map[string]map[string]bool); ok && len(sampledKvs) > 0 {
+		s.DyeingRules = make(map[string][]string, len(sampledKvs))
+		for key, values := range sampledKvs {
+			for value := range values {
+				s.DyeingRules[key] = append(s.DyeingRules[key], value)
+			}
+			sort.Strings(s.DyeingRules[key])
+		}
+	}
+	if b, ok := ActiveSamplingBoost(); ok {
+		s.Boost = &b
+	}
+	return s
+}
+
 func (ws *Sampler) shouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
 	x := binary.BigEndian.Uint64(p.TraceID[0:8]) >> 1
 
@@ -223,6 +263,9 @@ func (ws *Sampler) shouldSample(p sdktrace.SamplingParameters) sdktrace.Sampling
 	if x < traceIDUpperBound {
 		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample}
 	}
+	if result, ok := boostSample(p, x); ok {
+		return result
+	}
 	return sdktrace.SamplingResult{Decision: ws.opt.DefaultSamplingDecision}
 }
 
@@ -115,3 +115,19 @@ func TestSampler_shouldSample(t *testing.T) {
 		})
 	}
 }
+
+func TestSampler_Status(t *testing.T) {
+	ws := NewSampler("tenant", SamplerConfig{Fraction: 0.5}).(*Sampler)
+	ws.sampledKvs.Store(map[string]map[string]bool{"uid": {"2": true, "1": true}})
+	got := ws.Status()
+	want := SamplerStatus{
+		Description:      "TpsSampler{fraction=0.5,tenantID=tenant}",
+		Fraction:         0.5,
+		SpecialFractions: ws.samplerConfig.SpecialFractions,
+		SyncInterval:     ws.samplerConfig.SyncInterval.String(),
+		DyeingRules:      map[string][]string{"uid": {"1", "2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+}
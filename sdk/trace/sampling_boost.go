@@ -0,0 +1,163 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SamplingBoostKey is set to the boost fraction on the spans sampled or recorded by the SamplingBoost
+var SamplingBoostKey = attribute.Key("trace.sampling.boost")
+
+// MaxSamplingBoostDuration max duration of a SamplingBoost
+const MaxSamplingBoostDuration = 24 * time.Hour
+
+// SamplingBoost temporarily raises the sampling of Sampler until ExpireAt.
+type SamplingBoost struct {
+	// Fraction the traces below it are sampled besides the ones sampled by the Sampler
+	Fraction float64 `json:"fraction"`
+	// Service and Method the callee of the boosted rpcs, empty matches all
+	Service string `json:"service,omitempty"`
+	Method  string `json:"method,omitempty"`
+	// DeferredSampleError and DeferredSampleSlowDuration are the deferred thresholds of the spans not sampled
+	// by Fraction, which are recorded and kept by the DeferredSampler if they are met.
+	DeferredSampleError        bool          `json:"deferred_sample_error,omitempty"`
+	DeferredSampleSlowDuration time.Duration `json:"deferred_sample_slow_duration,omitempty"`
+	ExpireAt                   time.Time     `json:"expire_at"`
+
+	traceIDUpperBound uint64
+}
+
+var (
+	samplingBoost   atomic.Value // *SamplingBoost
+	samplingBoostMu sync.Mutex
+	boostTimer      *time.Timer
+)
+
+// SetSamplingBoost sets the boost, which replaces the active one and is cleared after ExpireAt.
+func SetSamplingBoost(b SamplingBoost) error {
+	if b.Fraction < 0 || b.Fraction > 1 {
+		return errors.New("fraction must be in [0, 1]")
+	}
+	d := time.Until(b.ExpireAt)
+	if d <= 0 || d > MaxSamplingBoostDuration {
+		return errors.New("expire_at must be in the next " + MaxSamplingBoostDuration.String())
+	}
+	b.traceIDUpperBound = getTraceIDUpperBound(b.Fraction)
+
+	samplingBoostMu.Lock()
+	defer samplingBoostMu.Unlock()
+	if boostTimer != nil {
+		boostTimer.Stop()
+	}
+	samplingBoost.Store(&b)
+	boostTimer = time.AfterFunc(d, func() { clearSamplingBoost(&b) })
+	log.Printf("opentelemetry: sampling boost %g of service %q method %q until %s", b.Fraction, b.Service,
+		b.Method, b.ExpireAt.Format(time.RFC3339))
+	return nil
+}
+
+// ClearSamplingBoost clears the active boost.
+func ClearSamplingBoost() {
+	if b := activeSamplingBoost(); b != nil {
+		clearSamplingBoost(b)
+	}
+}
+
+func clearSamplingBoost(b *SamplingBoost) {
+	samplingBoostMu.Lock()
+	defer samplingBoostMu.Unlock()
+	// the boost may have been replaced
+	if cur, _ := samplingBoost.Load().(*SamplingBoost); cur != b {
+		return
+	}
+	samplingBoost.Store((*SamplingBoost)(nil))
+	log.Printf("opentelemetry: sampling boost of service %q method %q cleared", b.Service, b.Method)
+}
+
+// ActiveSamplingBoost returns the active boost.
+func ActiveSamplingBoost() (SamplingBoost, bool) {
+	if b := activeSamplingBoost(); b != nil {
+		return *b, true
+	}
+	return SamplingBoost{}, false
+}
+
+func activeSamplingBoost() *SamplingBoost {
+	b, _ := samplingBoost.Load().(*SamplingBoost)
+	if b == nil || !time.Now().Before(b.ExpireAt) {
+		return nil
+	}
+	return b
+}
+
+func (b *SamplingBoost) matches(ctx context.Context) bool {
+	if b.Service == "" && b.Method == "" {
+		return true
+	}
+	if DefaultGetCalleeMethodInfo == nil {
+		return false
+	}
+	info := DefaultGetCalleeMethodInfo(ctx)
+	return (b.Service == "" || b.Service == info.CalleeService) && (b.Method == "" || b.Method == info.CalleeMethod)
+}
+
+func (b *SamplingBoost) hasDeferred() bool {
+	return b.DeferredSampleError || b.DeferredSampleSlowDuration > 0
+}
+
+// boostSample returns the decision of the active boost, ok is false if the boost does not decide.
+func boostSample(p sdktrace.SamplingParameters, x uint64) (result sdktrace.SamplingResult, ok bool) {
+	b := activeSamplingBoost()
+	if b == nil || !b.matches(p.ParentContext) {
+		return result, false
+	}
+	attrs := []attribute.KeyValue{SamplingBoostKey.Float64(b.Fraction)}
+	if x < b.traceIDUpperBound {
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordAndSample, Attributes: attrs}, true
+	}
+	if b.hasDeferred() {
+		return sdktrace.SamplingResult{Decision: sdktrace.RecordOnly, Attributes: attrs}, true
+	}
+	return result, false
+}
+
+// boostDeferredSample reports whether the span recorded by the active boost meets its deferred thresholds.
+func boostDeferredSample(s sdktrace.ReadOnlySpan, isError bool) bool {
+	b := activeSamplingBoost()
+	if b == nil || !b.hasDeferred() || !hasBoostAttribute(s) {
+		return false
+	}
+	if b.DeferredSampleError && isError {
+		return true
+	}
+	return b.DeferredSampleSlowDuration > 0 && s.EndTime().Sub(s.StartTime()) >= b.DeferredSampleSlowDuration
+}
+
+func hasBoostAttribute(s sdktrace.ReadOnlySpan) bool {
+	for _, kv := range s.Attributes() {
+		if kv.Key == SamplingBoostKey {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+//
+//
+// Tencent is pleased to support the open source community by making tRPC available.
+//
+// Copyright (C) 2023 Tencent.
+// All rights reserved.
+//
+// If you have downloaded a copy of the tRPC source code from Tencent,
+// please note that tRPC source code is licensed under the  Apache 2.0 License,
+// A copy of the Apache 2.0 License is included in this file.
+//
+//
+
+package trace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type methodInfoKey struct{}
+
+func TestSamplingBoost(t *testing.T) {
+	defer ClearSamplingBoost()
+	old := DefaultGetCalleeMethodInfo
+	defer func() { DefaultGetCalleeMethodInfo = old }()
+	DefaultGetCalleeMethodInfo = func(ctx context.Context) MethodInfo {
+		m, _ := ctx.Value(methodInfoKey{}).(MethodInfo)
+		return m
+	}
+	ws := NewSampler("tenant", SamplerConfig{Fraction: 0})
+	hit := context.WithValue(context.Background(), methodInfoKey{}, MethodInfo{"svc", "/hot"})
+	miss := context.WithValue(context.Background(), methodInfoKey{}, MethodInfo{"svc", "/other"})
+	p := sdktrace.SamplingParameters{ParentContext: hit, TraceID: trace.TraceID{0x7f}}
+
+	if got := ws.ShouldSample(p).Decision; got != sdktrace.Drop {
+		t.Fatalf("ShouldSample() = %v before boost", got)
+	}
+	if err := SetSamplingBoost(SamplingBoost{Fraction: 1, ExpireAt: time.Now().Add(48 * time.Hour)}); err == nil {
+		t.Fatal("SetSamplingBoost() should reject the duration over the max")
+	}
+	if err := SetSamplingBoost(SamplingBoost{Fraction: 1, Service: "svc", Method: "/hot",
+		ExpireAt: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatalf("SetSamplingBoost() error = %v", err)
+	}
+	got := ws.ShouldSample(p)
+	if got.Decision != sdktrace.RecordAndSample || len(got.Attributes) != 1 || got.Attributes[0].Key != SamplingBoostKey {
+		t.Errorf("ShouldSample() = %+v with boost", got)
+	}
+	p.ParentContext = miss
+	if got := ws.ShouldSample(p).Decision; got != sdktrace.Drop {
+		t.Errorf("ShouldSample() = %v for the other method", got)
+	}
+	if s := ws.(*Sampler).Status(); s.Boost == nil || s.Boost.Method != "/hot" {
+		t.Errorf("Status().Boost = %+v", s.Boost)
+	}
+
+	ClearSamplingBoost()
+	p.ParentContext = hit
+	if got := ws.ShouldSample(p).Decision; got != sdktrace.Drop {
+		t.Errorf("ShouldSample() = %v after clear", got)
+	}
+}
+
+func TestSamplingBoostExpire(t *testing.T) {
+	if err := SetSamplingBoost(SamplingBoost{Fraction: 1, ExpireAt: time.Now().Add(20 * time.Millisecond)}); err != nil {
+		t.Fatalf("SetSamplingBoost() error = %v", err)
+	}
+	if _, ok := ActiveSamplingBoost(); !ok {
+		t.Fatal("boost should be active")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := ActiveSamplingBoost(); ok {
+		t.Error("boost should expire")
+	}
+	if b, _ := samplingBoost.Load().(*SamplingBoost); b != nil {
+		t.Error("expired boost should be cleared")
+	}
+}
+
+func TestSamplingBoostDeferred(t *testing.T) {
+	defer ClearSamplingBoost()
+	if err := SetSamplingBoost(SamplingBoost{Fraction: 0, DeferredSampleError: true,
+		ExpireAt: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatalf("SetSamplingBoost() error = %v", err)
+	}
+	ws := NewSampler("tenant", SamplerConfig{Fraction: 0})
+	got := ws.ShouldSample(sdktrace.SamplingParameters{ParentContext: context.Background()})
+	if got.Decision != sdktrace.RecordOnly || len(got.Attributes) != 1 {
+		t.Fatalf("ShouldSample() = %+v with deferred boost", got)
+	}
+
+	sampler := NewDeferredSampler(DeferredSampleConfig{})
+	start := time.Now()
+	span := tracetest.SpanStub{StartTime: start, EndTime: start.Add(time.Millisecond), Attributes: got.Attributes,
+		Status: sdktrace.Status{Code: codes.Error}}
+	if !sampler(span.Snapshot()) {
+		t.Error("error span recorded by the boost should be kept")
+	}
+	span.Status = sdktrace.Status{Code: codes.Ok}
+	if sampler(span.Snapshot()) {
+		t.Error("ok span should be dropped")
+	}
+	span.Attributes = nil
+	span.Status = sdktrace.Status{Code: codes.Error}
+	if sampler(span.Snapshot()) {
+		t.Error("span not recorded by the boost should be dropped")
+	}
+}